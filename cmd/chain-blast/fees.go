@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi/bind"
+)
+
+const (
+	txTypeAuto    = "auto"
+	txTypeLegacy  = "legacy"
+	txTypeDynamic = "dynamic"
+)
+
+// setGasPricing sets the gas pricing of the transactions sent with auth, as per the
+// --tx-type flag:
+//
+//   - auto leaves it to the contract bindings, which send dynamic fee transactions on
+//     chains with a base fee, and legacy transactions otherwise, with the fees suggested
+//     by the node.
+//   - legacy sends legacy transactions at the gas price suggested by the node.
+//   - dynamic sends EIP-1559 dynamic fee transactions, with the --priority-tip and a fee
+//     cap of twice the current base fee plus the tip, so the transactions remain valid
+//     while the base fee rises. Chains without a base fee fall back to legacy.
+func setGasPricing(ctx context.Context, provider *ethrpc.Provider, auth *bind.TransactOpts, txType string, priorityTipGwei float64) error {
+	switch txType {
+	case txTypeAuto:
+		return nil
+
+	case txTypeLegacy:
+		return setLegacyGasPrice(ctx, provider, auth)
+
+	case txTypeDynamic:
+		head, err := provider.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if head.BaseFee == nil {
+			fmt.Println("=> chain does not support EIP-1559 dynamic fee txns, falling back to legacy txns")
+			return setLegacyGasPrice(ctx, provider, auth)
+		}
+
+		tip := gweiToWei(priorityTipGwei)
+		feeCap := big.NewInt(0).Mul(head.BaseFee, big.NewInt(2))
+		feeCap.Add(feeCap, tip)
+
+		auth.GasPrice = nil
+		auth.GasTipCap = tip
+		auth.GasFeeCap = feeCap
+
+		fmt.Printf("=> dynamic fee txns, base fee: %s wei, tip: %s wei, fee cap: %s wei\n", head.BaseFee, tip, feeCap)
+		return nil
+
+	default:
+		return fmt.Errorf("invalid --tx-type %q, must be one of %s, %s or %s", txType, txTypeAuto, txTypeLegacy, txTypeDynamic)
+	}
+}
+
+func setLegacyGasPrice(ctx context.Context, provider *ethrpc.Provider, auth *bind.TransactOpts) error {
+	gasPrice, err := provider.SuggestGasPrice(ctx)
+	if err != nil {
+		return err
+	}
+
+	auth.GasPrice = gasPrice
+	auth.GasTipCap = nil
+	auth.GasFeeCap = nil
+
+	fmt.Printf("=> legacy txns, gas price: %s wei\n", gasPrice)
+	return nil
+}
+
+func gweiToWei(gwei float64) *big.Int {
+	wei, _ := big.NewFloat(0).Mul(big.NewFloat(gwei), big.NewFloat(1e9)).Int(nil)
+	return wei
+}
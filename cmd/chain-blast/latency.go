@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsequence/ethkit/ethmonitor"
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+)
+
+// latencyTracker measures the confirmation latency of the sent txns, from the time they
+// were submitted until the time of the block their Transfer event was emitted in. Block
+// times have a resolution of a second, so latencies are rounded to the second as well.
+type latencyTracker struct {
+	submitted map[common.Hash]time.Time
+	mined     map[common.Hash]time.Time
+	latencies []time.Duration
+	mu        sync.Mutex
+}
+
+func newLatencyTracker() *latencyTracker {
+	return &latencyTracker{
+		submitted: map[common.Hash]time.Time{},
+		mined:     map[common.Hash]time.Time{},
+	}
+}
+
+// submit records the submit time of a txn. The txn may be observed on chain before the
+// node returns from sending it, in which case it's confirmed right away.
+func (t *latencyTracker) submit(txnHash common.Hash, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if minedAt, ok := t.mined[txnHash]; ok {
+		delete(t.mined, txnHash)
+		t.record(minedAt.Sub(at))
+		return
+	}
+	t.submitted[txnHash] = at
+}
+
+// confirm records a txn mined in a block at the given block time.
+func (t *latencyTracker) confirm(txnHash common.Hash, blockTime time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	submittedAt, ok := t.submitted[txnHash]
+	if !ok {
+		if _, ok := t.mined[txnHash]; !ok {
+			t.mined[txnHash] = blockTime
+		}
+		return
+	}
+	delete(t.submitted, txnHash)
+	t.record(blockTime.Sub(submittedAt))
+}
+
+func (t *latencyTracker) record(latency time.Duration) {
+	// the block time is the start of the block, which may be before the txn was sent
+	if latency < 0 {
+		latency = 0
+	}
+	t.latencies = append(t.latencies, latency)
+}
+
+// pending returns the number of submitted txns which are not confirmed yet.
+func (t *latencyTracker) pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.submitted)
+}
+
+// sorted returns the confirmation latencies of the confirmed txns, in ascending order.
+func (t *latencyTracker) sorted() []time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	latencies := make([]time.Duration, len(t.latencies))
+	copy(latencies, t.latencies)
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+	return latencies
+}
+
+// printLatencies prints the average and percentiles of the sorted latencies, along with
+// a histogram of their distribution.
+func printLatencies(latencies []time.Duration) {
+	if len(latencies) == 0 {
+		fmt.Println("=> no confirmed txns")
+		return
+	}
+
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+	fmt.Printf("=> confirmation latency avg: %s, min: %s, max: %s\n",
+		total/time.Duration(len(latencies)), latencies[0], latencies[len(latencies)-1])
+	fmt.Printf("=> confirmation latency p50: %s, p90: %s, p99: %s\n",
+		percentile(latencies, 50), percentile(latencies, 90), percentile(latencies, 99))
+
+	// histogram of up to 10 buckets, of a width of a whole number of seconds
+	const maxBuckets = 10
+	width := latencies[len(latencies)-1]/maxBuckets + time.Second
+	width = width.Truncate(time.Second)
+
+	counts := make([]int, int(latencies[len(latencies)-1]/width)+1)
+	maxCount := 0
+	for _, latency := range latencies {
+		i := int(latency / width)
+		counts[i]++
+		if counts[i] > maxCount {
+			maxCount = counts[i]
+		}
+	}
+
+	fmt.Println("")
+	for i, count := range counts {
+		bar := strings.Repeat("#", (count*50+maxCount-1)/maxCount)
+		fmt.Printf("%6s - %-6s | %-50s %d\n", time.Duration(i)*width, time.Duration(i+1)*width, bar, count)
+	}
+}
+
+// percentile returns the nearest-rank percentile p of the sorted latencies.
+func percentile(latencies []time.Duration, p int) time.Duration {
+	rank := (p*len(latencies) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return latencies[rank-1]
+}
+
+// watchTransfers runs a monitor which confirms the txns of the Transfer events emitted by
+// the contract, until the context is done.
+func watchTransfers(ctx context.Context, provider *ethrpc.Provider, erc20 *ERC20Mock, contract common.Address, tracker *latencyTracker) error {
+	monitorOptions := ethmonitor.DefaultOptions
+	monitorOptions.PollingInterval = 250 * time.Millisecond
+	monitorOptions.WithLogs = true
+	monitorOptions.LogAddresses = []common.Address{contract}
+	monitorOptions.LogTopics = []common.Hash{ethmonitor.ERC20TransferTopic}
+	monitorOptions.StartBlockNumber = nil // track the head
+
+	monitor, err := ethmonitor.NewMonitor(provider, monitorOptions)
+	if err != nil {
+		return err
+	}
+	sub := monitor.Subscribe()
+
+	go func() {
+		err := monitor.Run(ctx)
+		if err != nil {
+			fatal(err, "monitor failed")
+		}
+	}()
+
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.Done():
+				return
+			case blocks := <-sub.Blocks():
+				for _, block := range blocks {
+					if block.Event != ethmonitor.Added {
+						continue
+					}
+					blockTime := time.Unix(int64(block.Time()), 0)
+
+					for _, log := range block.Logs {
+						transfer, err := erc20.ParseTransfer(log)
+						if err != nil {
+							continue
+						}
+						tracker.confirm(transfer.Raw.TxHash, blockTime)
+					}
+				}
+			}
+		}
+	}()
+
+	return nil
+}
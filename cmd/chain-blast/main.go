@@ -3,15 +3,20 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"math/big"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/0xsequence/ethkit/ethrpc"
 	"github.com/0xsequence/ethkit/ethwallet"
 	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi/bind"
 	"github.com/0xsequence/ethkit/go-ethereum/common"
 	"github.com/0xsequence/ethkit/go-ethereum/core/types"
 	"github.com/0xsequence/ethkit/util"
@@ -29,6 +34,16 @@ var (
 	ERC20_TEST_CONTRACT = "0xCCCD8b34e94F52eDFAdA6e6Ae4AE1C1ab43F9D67"
 )
 
+var (
+	flagTxType      = flag.String("tx-type", txTypeAuto, "transaction type: auto, legacy or dynamic (EIP-1559)")
+	flagPriorityTip = flag.Float64("priority-tip", 1.5, "priority tip in gwei of dynamic fee transactions")
+	flagNumTxns     = flag.Int("num-txns", 10, "number of transfer txns to send")
+	flagConcurrency = flag.Int("concurrency", 1, "number of goroutines sending txns, sharing the wallet's nonces")
+	flagTPS         = flag.Int("tps", 0, "target txns per second, unlimited when 0")
+	flagDuration    = flag.Duration("duration", 0, "send txns for this long instead of --num-txns, ie. 1m")
+	flagWaitConfirm = flag.Duration("confirm-timeout", time.Minute, "max time to wait for the sent txns to be confirmed")
+)
+
 func init() {
 	testConfig, err := util.ReadTestConfig("../../ethkit-test.json")
 	if err != nil {
@@ -43,6 +58,8 @@ func init() {
 }
 
 func main() {
+	flag.Parse()
+
 	fmt.Println("chain-blast start")
 	fmt.Println("")
 
@@ -108,7 +125,7 @@ func main() {
 
 func deployERC20(wallet *ethwallet.Wallet) (common.Address, error) {
 	provider := wallet.GetProvider()
-	auth, err := wallet.Transactor(context.Background())
+	auth, err := transactor(wallet)
 	if err != nil {
 		return common.Address{}, err
 	}
@@ -169,45 +186,121 @@ func transferERC20s(wallet *ethwallet.Wallet) error {
 
 	fmt.Println("")
 
-	// blastNum := 5 // num txns at a time to dispatch
-	numTxns := 10 // will send this many parallel txns
+	numTxns := *flagNumTxns // will send this many parallel txns, unless sending for a duration
+	duration := *flagDuration
+
+	concurrency := *flagConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
 
 	// marks that we send a txn at a time, and wait for it..
 	var waitForEachTxn bool
 	// waitForEachTxn = true
 	waitForEachTxn = false
 
-	auth, err := wallet.Transactor(context.Background())
+	auth, err := transactor(wallet)
 	if err != nil {
 		return err
 	}
 
+	// nonces are handed out locally to send parallel txns, as the node's pending nonce
+	// collides under load
+	nonces := ethrpc.NewNonceTracker(provider, wallet.Address())
+
 	// TODO: lets use ethmempool + subscribeWithFilter, and listen for transactions as they come in
 
-	for i := 0; i < numTxns; i++ {
-		// increment nonce ourselves to send parallel txns
-		auth.Nonce = big.NewInt(0).SetUint64(nonce)
+	// watch for the Transfer events of the sent txns to measure their confirmation latency
+	monitorCtx, stopMonitor := context.WithCancel(context.Background())
+	defer stopMonitor()
 
-		// dispatch the txn
-		txn, err := erc20.Transfer(auth, common.HexToAddress(randomRecipient), big.NewInt(8))
-		if err != nil {
-			fatal(err, "transfer #%d failed", i)
-		}
-		fmt.Printf("Sent txn %d with hash %s\n", i, txn.Hash().Hex())
+	tracker := newLatencyTracker()
+	err = watchTransfers(monitorCtx, provider, erc20, common.HexToAddress(ERC20_TEST_CONTRACT), tracker)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	var limiter *rateLimiter
+	if *flagTPS > 0 {
+		limiter = newRateLimiter(*flagTPS)
+	}
 
-		if waitForEachTxn {
-			startTime := time.Now()
-			err = waitForTxn(provider, txn.Hash())
-			if err != nil {
-				fatal(err, "transfer wait failed for txn %s", txn.Hash().Hex())
+	var numSent, numFailed int64
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				// dispatch the txn
+				submittedAt := time.Now()
+				txn, err := sendTransfer(erc20, auth, nonces)
+				if err != nil {
+					atomic.AddInt64(&numFailed, 1)
+					fmt.Printf("Failed to send txn %d: %v\n", i, err)
+					continue
+				}
+				tracker.submit(txn.Hash(), submittedAt)
+				atomic.AddInt64(&numSent, 1)
+				fmt.Printf("Sent txn %d with hash %s\n", i, txn.Hash().Hex())
+
+				if waitForEachTxn {
+					startTime := time.Now()
+					err = waitForTxn(provider, txn.Hash())
+					if err != nil {
+						fatal(err, "transfer wait failed for txn %s", txn.Hash().Hex())
+					}
+					fmt.Printf("Txn mined in %s\n", time.Now().Sub(startTime))
+					fmt.Println("")
+				}
 			}
-			fmt.Printf("Txn mined in %s\n", time.Now().Sub(startTime))
-			fmt.Println("")
+		}()
+	}
+
+	startTime := time.Now()
+
+send:
+	for i := 0; duration > 0 || i < numTxns; i++ {
+		if limiter != nil && limiter.Wait(ctx) != nil {
+			break
 		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
 
-		// increment nonce for next txn
-		nonce += 1
+	elapsed := time.Since(startTime)
+
+	// wait for the sent txns to be confirmed
+	deadline := time.Now().Add(*flagWaitConfirm)
+	for tracker.pending() > 0 && time.Now().Before(deadline) {
+		time.Sleep(250 * time.Millisecond)
 	}
+	stopMonitor()
+
+	latencies := tracker.sorted()
+
+	fmt.Println("")
+	fmt.Printf("=> total txns: %d sent, %d failed\n", numSent, numFailed)
+	fmt.Printf("=> achieved tps: %.2f over %s\n", float64(numSent)/elapsed.Seconds(), elapsed.Round(time.Millisecond))
+	fmt.Printf("=> confirmed txns: %d of %d\n", len(latencies), numSent)
+	printLatencies(latencies)
+	fmt.Println("")
 
 	// wallet balance is now..:
 	balance, err = erc20.BalanceOf(nil, wallet.Address())
@@ -219,6 +312,53 @@ func transferERC20s(wallet *ethwallet.Wallet) error {
 	return nil
 }
 
+// sendTransfer sends a transfer with the next nonce of the wallet. If the txn fails to be
+// sent, its nonce is released to be used by the next txn, as the txns sent concurrently
+// with the next nonces would otherwise be stuck behind the gap. Resyncing the nonces with
+// the node instead would hand out the nonces of the txns still in flight again. A nonce
+// which was already used by a txn sent elsewhere is not released, see isNonceUsedErr.
+func sendTransfer(erc20 *ERC20Mock, auth *bind.TransactOpts, nonces *ethrpc.NonceTracker) (*types.Transaction, error) {
+	nonce, err := nonces.Next(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	opts := *auth
+	opts.Nonce = big.NewInt(0).SetUint64(nonce)
+
+	txn, err := erc20.Transfer(&opts, common.HexToAddress(randomRecipient), big.NewInt(8))
+	if err != nil {
+		if !isNonceUsedErr(err) {
+			nonces.Release(nonce)
+		}
+		return nil, err
+	}
+	return txn, nil
+}
+
+// isNonceUsedErr reports if the node rejected the txn as its nonce is already used by
+// another txn, which is either mined or pending in the node's mempool.
+func isNonceUsedErr(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") ||
+		strings.Contains(msg, "already known") ||
+		strings.Contains(msg, "replacement transaction underpriced")
+}
+
+// transactor returns the transact options of the wallet, with the gas pricing of the
+// --tx-type flag.
+func transactor(wallet *ethwallet.Wallet) (*bind.TransactOpts, error) {
+	auth, err := wallet.Transactor(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	err = setGasPricing(context.Background(), wallet.GetProvider(), auth, *flagTxType, *flagPriorityTip)
+	if err != nil {
+		return nil, err
+	}
+	return auth, nil
+}
+
 func promptAreYouSure() {
 	fmt.Println("")
 	fmt.Printf("Are you sure you'd like to deploy a new ERC20 contract? [y/n]: ")
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket which refills at tps tokens per second, and holds up to
+// a tenth of a second worth of tokens, so txns are paced evenly rather than sent in bursts.
+type rateLimiter struct {
+	tps    float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+func newRateLimiter(tps int) *rateLimiter {
+	return &rateLimiter{
+		tps:    float64(tps),
+		burst:  math.Max(1, float64(tps)/10),
+		tokens: 1,
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a token is available, or the context is done.
+func (r *rateLimiter) Wait(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		now := time.Now()
+		r.tokens = math.Min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.tps)
+		r.last = now
+
+		if r.tokens >= 1 {
+			r.tokens--
+			r.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - r.tokens) / r.tps * float64(time.Second))
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
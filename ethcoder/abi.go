@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"reflect"
 	"strconv"
 	"strings"
 
@@ -33,6 +34,104 @@ func AbiCoderHex(argTypes []string, argValues []interface{}) (string, error) {
 	return h, nil
 }
 
+// HashABIEncoded returns the keccak256 hash of the abi encoded values, matching
+// `keccak256(abi.encode(...))` in solidity, as commonly used for commitments.
+func HashABIEncoded(argTypes []string, argValues []interface{}) (common.Hash, error) {
+	b, err := AbiCoder(argTypes, argValues)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return Keccak256Hash(b), nil
+}
+
+// EncodedSize returns the length of the abi encoding of the values as by AbiCoder, without
+// encoding them, ie. to size buffers or check calldata against size limits upfront.
+func EncodedSize(argTypes []string, argValues []interface{}) (int, error) {
+	if len(argTypes) != len(argValues) {
+		return 0, errors.New("invalid arguments - types and values do not match")
+	}
+	args, err := buildArgumentsFromTypes(argTypes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build abi: %v", err)
+	}
+
+	size := 0
+	for i, arg := range args {
+		n, err := abiEncodedSize(arg.Type, reflect.ValueOf(argValues[i]))
+		if err != nil {
+			return 0, fmt.Errorf("ethcoder: argument %d: %w", i, err)
+		}
+		if abiIsDynamicType(arg.Type) {
+			// offset in the head, and the value in the tail
+			size += 32
+		}
+		size += n
+	}
+	return size, nil
+}
+
+// abiEncodedSize returns the length of the abi encoding of the value, excluding its offset
+// in the head of the enclosing encoding when the type is dynamic.
+func abiEncodedSize(t abi.Type, v reflect.Value) (int, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return 0, fmt.Errorf("missing value for type %s", t.String())
+	}
+
+	switch t.T {
+	case abi.StringTy, abi.BytesTy:
+		if v.Kind() != reflect.String && v.Kind() != reflect.Slice {
+			return 0, fmt.Errorf("invalid value of kind %s for type %s", v.Kind(), t.String())
+		}
+		// length, followed by the value padded to 32 bytes
+		return 32 + (v.Len()+31)/32*32, nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return 0, fmt.Errorf("invalid value of kind %s for type %s", v.Kind(), t.String())
+		}
+		size := 0
+		if t.T == abi.SliceTy {
+			size += 32 // length
+		} else if v.Len() != t.Size {
+			return 0, fmt.Errorf("invalid array length %d for type %s", v.Len(), t.String())
+		}
+		dynamic := abiIsDynamicType(*t.Elem)
+		for i := 0; i < v.Len(); i++ {
+			n, err := abiEncodedSize(*t.Elem, v.Index(i))
+			if err != nil {
+				return 0, err
+			}
+			if dynamic {
+				size += 32 // offset
+			}
+			size += n
+		}
+		return size, nil
+
+	case abi.TupleTy:
+		return 0, fmt.Errorf("tuple types are not supported")
+
+	default:
+		return 32, nil
+	}
+}
+
+// abiIsDynamicType reports if the abi type is encoded in the tail of the encoding.
+func abiIsDynamicType(t abi.Type) bool {
+	if t.T == abi.TupleTy {
+		for _, elem := range t.TupleElems {
+			if abiIsDynamicType(*elem) {
+				return true
+			}
+		}
+		return false
+	}
+	return t.T == abi.StringTy || t.T == abi.BytesTy || t.T == abi.SliceTy || (t.T == abi.ArrayTy && abiIsDynamicType(*t.Elem))
+}
+
 func AbiDecoder(argTypes []string, input []byte, argValues []interface{}) error {
 	if len(argTypes) != len(argValues) {
 		return errors.New("invalid arguments - types and values do not match")
@@ -72,6 +171,37 @@ func AbiEncodeMethodCalldata(methodExpr string, argValues []interface{}) ([]byte
 	return data, nil
 }
 
+// EncodeWithSignature mirrors Solidity's `abi.encodeWithSignature`, returning the calldata
+// for the method signature, ie. `transfer(address,uint256)`, and its arguments.
+func EncodeWithSignature(sig string, args ...interface{}) ([]byte, error) {
+	mabi, methodName, err := ParseMethodABI(sig, "")
+	if err != nil {
+		return nil, err
+	}
+	method := mabi.Methods[methodName]
+	if len(method.Inputs) != len(args) {
+		return nil, fmt.Errorf("ethcoder: %s expects %d arguments, got %d", method.Sig, len(method.Inputs), len(args))
+	}
+	data, err := mabi.Pack(methodName, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ethcoder: failed to encode %s: %w", method.Sig, err)
+	}
+	return data, nil
+}
+
+// EncodeWithSelector mirrors Solidity's `abi.encodeWithSelector`, returning the calldata
+// for the 4-byte method selector and its arguments of the given types.
+func EncodeWithSelector(selector [4]byte, argTypes []string, args ...interface{}) ([]byte, error) {
+	if len(argTypes) != len(args) {
+		return nil, fmt.Errorf("ethcoder: selector %s expects %d arguments, got %d", hexutil.Encode(selector[:]), len(argTypes), len(args))
+	}
+	packed, err := AbiCoder(argTypes, args)
+	if err != nil {
+		return nil, fmt.Errorf("ethcoder: failed to encode arguments for selector %s: %w", hexutil.Encode(selector[:]), err)
+	}
+	return append(selector[:], packed...), nil
+}
+
 func AbiEncodeMethodCalldataFromStringValues(methodExpr string, argStringValues []string) ([]byte, error) {
 	_, argsList, err := parseMethodExpr(methodExpr)
 	if err != nil {
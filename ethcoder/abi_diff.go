@@ -0,0 +1,243 @@
+package ethcoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi"
+)
+
+// ABIDiff reports the differences between two versions of a contract ABI. Methods
+// are matched by their selector and events by their topic, so overloaded functions
+// are compared individually.
+type ABIDiff struct {
+	AddedMethods   []abi.Method
+	RemovedMethods []abi.Method
+	ChangedMethods []ABIMethodChange
+
+	AddedEvents   []abi.Event
+	RemovedEvents []abi.Event
+	ChangedEvents []ABIEventChange
+
+	// Constructor is set when the constructor inputs or state mutability have changed.
+	Constructor *ABIMethodChange
+}
+
+// ABIMethodChange is a method which exists in both ABIs with the same selector,
+// but a different definition, ie. its outputs or state mutability.
+type ABIMethodChange struct {
+	Old, New abi.Method
+
+	// Breaking is false when the change is signature-compatible for callers,
+	// ie. only argument names have changed.
+	Breaking bool
+}
+
+// ABIEventChange is an event which exists in both ABIs with the same topic,
+// but a different definition, ie. which arguments are indexed.
+type ABIEventChange struct {
+	Old, New abi.Event
+
+	// Breaking is false when the change is compatible for log decoders,
+	// ie. only argument names have changed.
+	Breaking bool
+}
+
+// IsEmpty returns true if both ABIs are equivalent.
+func (d ABIDiff) IsEmpty() bool {
+	return len(d.AddedMethods) == 0 && len(d.RemovedMethods) == 0 && len(d.ChangedMethods) == 0 &&
+		len(d.AddedEvents) == 0 && len(d.RemovedEvents) == 0 && len(d.ChangedEvents) == 0 &&
+		d.Constructor == nil
+}
+
+// IsBreaking returns true if any of the changes are breaking for existing
+// callers or log decoders. Added methods and events are never breaking.
+func (d ABIDiff) IsBreaking() bool {
+	if len(d.RemovedMethods) > 0 || len(d.RemovedEvents) > 0 {
+		return true
+	}
+	if d.Constructor != nil && d.Constructor.Breaking {
+		return true
+	}
+	for _, c := range d.ChangedMethods {
+		if c.Breaking {
+			return true
+		}
+	}
+	for _, c := range d.ChangedEvents {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffABI compares the old and new ABIs and reports the added, removed and changed
+// methods, events and constructor.
+func DiffABI(old, new abi.ABI) ABIDiff {
+	diff := ABIDiff{}
+
+	oldMethods := methodsBySelector(old)
+	newMethods := methodsBySelector(new)
+
+	for _, selector := range sortedKeys(oldMethods) {
+		oldMethod := oldMethods[selector]
+		newMethod, ok := newMethods[selector]
+		if !ok {
+			diff.RemovedMethods = append(diff.RemovedMethods, oldMethod)
+			continue
+		}
+		if change, changed := diffMethod(oldMethod, newMethod); changed {
+			diff.ChangedMethods = append(diff.ChangedMethods, change)
+		}
+	}
+	for _, selector := range sortedKeys(newMethods) {
+		if _, ok := oldMethods[selector]; !ok {
+			diff.AddedMethods = append(diff.AddedMethods, newMethods[selector])
+		}
+	}
+
+	oldEvents := eventsByTopic(old)
+	newEvents := eventsByTopic(new)
+
+	for _, topic := range sortedKeys(oldEvents) {
+		oldEvent := oldEvents[topic]
+		newEvent, ok := newEvents[topic]
+		if !ok {
+			diff.RemovedEvents = append(diff.RemovedEvents, oldEvent)
+			continue
+		}
+		if change, changed := diffEvent(oldEvent, newEvent); changed {
+			diff.ChangedEvents = append(diff.ChangedEvents, change)
+		}
+	}
+	for _, topic := range sortedKeys(newEvents) {
+		if _, ok := oldEvents[topic]; !ok {
+			diff.AddedEvents = append(diff.AddedEvents, newEvents[topic])
+		}
+	}
+
+	// constructors have no selector, so compare their inputs directly
+	if argumentTypes(old.Constructor.Inputs) != argumentTypes(new.Constructor.Inputs) {
+		diff.Constructor = &ABIMethodChange{Old: old.Constructor, New: new.Constructor, Breaking: true}
+	} else if argumentNames(old.Constructor.Inputs) != argumentNames(new.Constructor.Inputs) || old.Constructor.StateMutability != new.Constructor.StateMutability {
+		diff.Constructor = &ABIMethodChange{Old: old.Constructor, New: new.Constructor, Breaking: old.Constructor.IsPayable() && !new.Constructor.IsPayable()}
+	}
+
+	return diff
+}
+
+// NormalizeABIJSON returns a canonical JSON encoding of the ABI, where entries are sorted
+// by type and signature, and object keys are sorted. Two ABIs which only differ in
+// formatting or ordering will have identical normalized output.
+func NormalizeABIJSON(data []byte) ([]byte, error) {
+	var entries []map[string]interface{}
+	err := json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("ethcoder: invalid abi json: %w", err)
+	}
+
+	sortKey := func(entry map[string]interface{}) string {
+		typ, _ := entry["type"].(string)
+		if typ == "" {
+			typ = "function"
+		}
+		name, _ := entry["name"].(string)
+		inputs, _ := json.Marshal(entry["inputs"])
+		return typ + ":" + name + ":" + string(inputs)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return sortKey(entries[i]) < sortKey(entries[j])
+	})
+
+	return json.Marshal(entries)
+}
+
+func diffMethod(old, new abi.Method) (ABIMethodChange, bool) {
+	change := ABIMethodChange{Old: old, New: new}
+
+	outputsChanged := argumentTypes(old.Outputs) != argumentTypes(new.Outputs)
+	mutabilityChanged := old.StateMutability != new.StateMutability || old.Constant != new.Constant || old.Payable != new.Payable
+	namesChanged := argumentNames(old.Inputs) != argumentNames(new.Inputs) || argumentNames(old.Outputs) != argumentNames(new.Outputs)
+
+	if !outputsChanged && !mutabilityChanged && !namesChanged {
+		return change, false
+	}
+
+	// a method which no longer accepts value, or which is no longer a view and
+	// therefore can't be called statically, breaks existing callers
+	change.Breaking = outputsChanged ||
+		(old.IsPayable() && !new.IsPayable()) ||
+		(old.IsConstant() && !new.IsConstant())
+
+	return change, true
+}
+
+func diffEvent(old, new abi.Event) (ABIEventChange, bool) {
+	change := ABIEventChange{Old: old, New: new}
+
+	indexedChanged := argumentIndexed(old.Inputs) != argumentIndexed(new.Inputs)
+	namesChanged := argumentNames(old.Inputs) != argumentNames(new.Inputs)
+
+	if !indexedChanged && !namesChanged {
+		return change, false
+	}
+	change.Breaking = indexedChanged
+	return change, true
+}
+
+func methodsBySelector(contractABI abi.ABI) map[string]abi.Method {
+	methods := map[string]abi.Method{}
+	for _, method := range contractABI.Methods {
+		methods[HexEncode(method.ID)] = method
+	}
+	return methods
+}
+
+func eventsByTopic(contractABI abi.ABI) map[string]abi.Event {
+	events := map[string]abi.Event{}
+	for _, event := range contractABI.Events {
+		key := event.ID.Hex()
+		if event.Anonymous {
+			// anonymous events have no topic, identify them by signature instead
+			key = "anonymous:" + event.Sig
+		}
+		events[key] = event
+	}
+	return events
+}
+
+func argumentTypes(args abi.Arguments) string {
+	types := make([]string, len(args))
+	for i, arg := range args {
+		types[i] = arg.Type.String()
+	}
+	return strings.Join(types, ",")
+}
+
+func argumentNames(args abi.Arguments) string {
+	names := make([]string, len(args))
+	for i, arg := range args {
+		names[i] = arg.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func argumentIndexed(args abi.Arguments) string {
+	indexed := make([]string, len(args))
+	for i, arg := range args {
+		indexed[i] = fmt.Sprintf("%v", arg.Indexed)
+	}
+	return strings.Join(indexed, ",")
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
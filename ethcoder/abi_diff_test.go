@@ -0,0 +1,86 @@
+package ethcoder_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/0xsequence/ethkit/ethcoder"
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const abiDiffOld = `[
+	{"type":"constructor","inputs":[{"name":"owner","type":"address"}],"stateMutability":"nonpayable"},
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable"},
+	{"type":"function","name":"mint","inputs":[{"name":"to","type":"address"}],"outputs":[],"stateMutability":"nonpayable"},
+	{"type":"function","name":"mint","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[],"stateMutability":"nonpayable"},
+	{"type":"function","name":"balanceOf","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"},
+	{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}],"anonymous":false},
+	{"type":"event","name":"Approval","inputs":[{"name":"owner","type":"address","indexed":true},{"name":"spender","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}],"anonymous":false}
+]`
+
+const abiDiffNew = `[
+	{"type":"constructor","inputs":[{"name":"admin","type":"address"}],"stateMutability":"nonpayable"},
+	{"type":"function","name":"transfer","inputs":[{"name":"recipient","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"success","type":"bool"}],"stateMutability":"nonpayable"},
+	{"type":"function","name":"mint","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[],"stateMutability":"nonpayable"},
+	{"type":"function","name":"balanceOf","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint128"}],"stateMutability":"view"},
+	{"type":"function","name":"burn","inputs":[{"name":"amount","type":"uint256"}],"outputs":[],"stateMutability":"nonpayable"},
+	{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":true}],"anonymous":false},
+	{"type":"event","name":"Approval","inputs":[{"name":"owner","type":"address","indexed":true},{"name":"spender","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}],"anonymous":false}
+]`
+
+func TestDiffABI(t *testing.T) {
+	oldABI, err := abi.JSON(strings.NewReader(abiDiffOld))
+	require.NoError(t, err)
+	newABI, err := abi.JSON(strings.NewReader(abiDiffNew))
+	require.NoError(t, err)
+
+	diff := ethcoder.DiffABI(oldABI, newABI)
+	assert.True(t, diff.IsBreaking())
+	assert.False(t, diff.IsEmpty())
+
+	// the overloaded mint(address) was removed, but mint(address,uint256) remains
+	require.Len(t, diff.RemovedMethods, 1)
+	assert.Equal(t, "mint(address)", diff.RemovedMethods[0].Sig)
+
+	require.Len(t, diff.AddedMethods, 1)
+	assert.Equal(t, "burn(uint256)", diff.AddedMethods[0].Sig)
+
+	changed := map[string]bool{}
+	for _, c := range diff.ChangedMethods {
+		changed[c.New.Sig] = c.Breaking
+	}
+	assert.Equal(t, map[string]bool{"transfer(address,uint256)": false, "balanceOf(address)": true}, changed)
+
+	require.Len(t, diff.ChangedEvents, 1)
+	assert.Equal(t, "Transfer", diff.ChangedEvents[0].New.Name)
+	assert.True(t, diff.ChangedEvents[0].Breaking)
+	assert.Empty(t, diff.AddedEvents)
+	assert.Empty(t, diff.RemovedEvents)
+
+	require.NotNil(t, diff.Constructor)
+	assert.False(t, diff.Constructor.Breaking)
+
+	// an abi compared to itself is empty
+	diff = ethcoder.DiffABI(oldABI, oldABI)
+	assert.True(t, diff.IsEmpty())
+	assert.False(t, diff.IsBreaking())
+}
+
+func TestNormalizeABIJSON(t *testing.T) {
+	a := `[{"type":"event","name":"B","inputs":[]},{"name":"a","type":"function","inputs":[],"outputs":[]}]`
+	b := `[
+		{"outputs":[],"inputs":[],"type":"function","name":"a"},
+		{"inputs":[],"name":"B","type":"event"}
+	]`
+
+	na, err := ethcoder.NormalizeABIJSON([]byte(a))
+	require.NoError(t, err)
+	nb, err := ethcoder.NormalizeABIJSON([]byte(b))
+	require.NoError(t, err)
+	assert.Equal(t, string(na), string(nb))
+
+	_, err = ethcoder.NormalizeABIJSON([]byte(`{}`))
+	assert.Error(t, err)
+}
@@ -142,6 +142,29 @@ func TestAbiEncodeMethodCalldata(t *testing.T) {
 	}
 }
 
+func TestEncodeWithSignature(t *testing.T) {
+	ownerAddress := common.HexToAddress("0x6615e4e985bf0d137196897dfa182dbd7127f54f")
+	expected := "0x00fdd58e0000000000000000000000006615e4e985bf0d137196897dfa182dbd7127f54f0000000000000000000000000000000000000000000000000000000000000002"
+
+	calldata, err := EncodeWithSignature("balanceOf(address,uint256)", ownerAddress, big.NewInt(2))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, HexEncode(calldata))
+
+	calldata, err = EncodeWithSelector([4]byte{0x00, 0xfd, 0xd5, 0x8e}, []string{"address", "uint256"}, ownerAddress, big.NewInt(2))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, HexEncode(calldata)) // same as above
+
+	// invalid arg count
+	_, err = EncodeWithSignature("balanceOf(address,uint256)", ownerAddress)
+	assert.Error(t, err)
+	_, err = EncodeWithSelector([4]byte{0x00, 0xfd, 0xd5, 0x8e}, []string{"address", "uint256"}, ownerAddress)
+	assert.Error(t, err)
+
+	// invalid arg type
+	_, err = EncodeWithSignature("balanceOf(address,uint256)", "0x6615e4e985bf0d137196897dfa182dbd7127f54f", big.NewInt(2))
+	assert.Error(t, err)
+}
+
 func TestAbiDecodeExpr(t *testing.T) {
 	ret := "0x000000000000000000000000000000000000000000007998f984c2040a5a9e01"
 
@@ -315,3 +338,81 @@ func TestAbiUnmarshalStringValues(t *testing.T) {
 
 // 	spew.Dump(values)
 // }
+
+func TestHashABIEncoded(t *testing.T) {
+	// keccak256(abi.encode(uint256(0)))
+	{
+		h, err := HashABIEncoded([]string{"uint256"}, []interface{}{big.NewInt(0)})
+		assert.NoError(t, err)
+		assert.Equal(t, "0x290decd9548b62a8d60345a988386fc84ba6bc95484008f6362f93160ef3e563", h.Hex())
+	}
+
+	// keccak256(abi.encode(uint256(1)))
+	{
+		h, err := HashABIEncoded([]string{"uint256"}, []interface{}{big.NewInt(1)})
+		assert.NoError(t, err)
+		assert.Equal(t, "0xb10e2d527612073b26eecdfd717e6a320cf44b4afac2b0732d9fcbe2b7fa0cf6", h.Hex())
+	}
+
+	// keccak256(abi.encode(address(0), uint256(0)))
+	{
+		h, err := HashABIEncoded([]string{"address", "uint256"}, []interface{}{common.Address{}, big.NewInt(0)})
+		assert.NoError(t, err)
+		assert.Equal(t, "0xad3228b676f7d3cd4284a5443f17f1962b36e491b30a40b2405849e597ba5fb5", h.Hex())
+	}
+
+	// keccak256(abi.encode([44], [22])) with dynamic types
+	{
+		h, err := HashABIEncoded([]string{"uint256[]", "uint256[]"}, []interface{}{[]*big.Int{big.NewInt(44)}, []*big.Int{big.NewInt(22)}})
+		assert.NoError(t, err)
+
+		encoded := hexutil.MustDecode("0x000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000800000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000002c00000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000016")
+		assert.Equal(t, Keccak256Hash(encoded), h)
+	}
+
+	// mismatched types and values
+	{
+		_, err := HashABIEncoded([]string{"uint256", "address"}, []interface{}{big.NewInt(1)})
+		assert.Error(t, err)
+	}
+}
+
+func TestEncodedSize(t *testing.T) {
+	cases := []struct {
+		argTypes []string
+		input    []interface{}
+	}{
+		{[]string{}, []interface{}{}},
+		{[]string{"uint256", "address", "bool", "bytes32"}, []interface{}{big.NewInt(1), common.Address{}, true, [32]byte{}}},
+		{[]string{"string"}, []interface{}{""}},
+		{[]string{"string"}, []interface{}{"hello"}},
+		{[]string{"string", "uint256"}, []interface{}{string(make([]byte, 32)), big.NewInt(1)}},
+		{[]string{"bytes"}, []interface{}{make([]byte, 33)}},
+		{[]string{"uint256[]"}, []interface{}{[]*big.Int{}}},
+		{[]string{"uint256[]", "bytes"}, []interface{}{[]*big.Int{big.NewInt(1), big.NewInt(2)}, []byte{1}}},
+		{[]string{"uint256[3]"}, []interface{}{[3]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}}},
+		{[]string{"string[]"}, []interface{}{[]string{"a", string(make([]byte, 40)), ""}}},
+		{[]string{"string[2]", "address"}, []interface{}{[2]string{"a", "b"}, common.Address{}}},
+		{[]string{"uint8[2][3]"}, []interface{}{[3][2]uint8{}}},
+		{[]string{"uint256[2][]"}, []interface{}{[][2]*big.Int{{big.NewInt(1), big.NewInt(2)}}}},
+		{[]string{"bytes[][]"}, []interface{}{[][][]byte{{{1}, make([]byte, 64)}, {}}}},
+	}
+
+	for _, c := range cases {
+		encoded, err := AbiCoder(c.argTypes, c.input)
+		assert.NoError(t, err, c.argTypes)
+
+		size, err := EncodedSize(c.argTypes, c.input)
+		assert.NoError(t, err, c.argTypes)
+		assert.Equal(t, len(encoded), size, c.argTypes)
+	}
+
+	_, err := EncodedSize([]string{"uint256[2]"}, []interface{}{[]*big.Int{big.NewInt(1)}})
+	assert.Error(t, err)
+
+	_, err = EncodedSize([]string{"string"}, []interface{}{nil})
+	assert.Error(t, err)
+
+	_, err = EncodedSize([]string{"string", "uint256"}, []interface{}{"a"})
+	assert.Error(t, err)
+}
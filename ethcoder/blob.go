@@ -0,0 +1,146 @@
+package ethcoder
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/0xsequence/ethkit/go-ethereum/rlp"
+)
+
+const (
+	// BlobTxType is the EIP-2718 type of EIP-4844 blob transactions.
+	BlobTxType = 0x03
+
+	// BlobCommitmentVersionKZG is the version byte of blob versioned hashes derived
+	// from KZG commitments, as defined by EIP-4844.
+	BlobCommitmentVersionKZG = 0x01
+)
+
+var ErrInvalidBlobVersionedHash = errors.New("ethcoder: invalid blob versioned hash")
+
+// BlobVersionedHash returns the versioned hash of the KZG commitment of a blob, which is
+// how blob transactions and the BLOBHASH opcode refer to blobs, ie. kzg_to_versioned_hash
+// of EIP-4844: the sha256 hash of the commitment, with the first byte replaced by the
+// BlobCommitmentVersionKZG version byte.
+func BlobVersionedHash(commitment [48]byte) common.Hash {
+	h := sha256.Sum256(commitment[:])
+	h[0] = BlobCommitmentVersionKZG
+	return h
+}
+
+// ValidateBlobVersionedHash returns ErrInvalidBlobVersionedHash if the hash does not carry
+// a supported version byte.
+func ValidateBlobVersionedHash(hash common.Hash) error {
+	if hash[0] != BlobCommitmentVersionKZG {
+		return fmt.Errorf("%w: unsupported version 0x%02x", ErrInvalidBlobVersionedHash, hash[0])
+	}
+	return nil
+}
+
+// BlobTxSidecar holds the blobs of a blob transaction, along with their KZG commitments
+// and proofs, which are sent along with the transaction to the network, but not included
+// in blocks.
+type BlobTxSidecar struct {
+	Blobs       [][]byte
+	Commitments [][48]byte
+	Proofs      [][48]byte
+}
+
+// BlobHashes returns the versioned hashes of the commitments of the sidecar, in order.
+func (s *BlobTxSidecar) BlobHashes() []common.Hash {
+	hashes := make([]common.Hash, len(s.Commitments))
+	for i, commitment := range s.Commitments {
+		hashes[i] = BlobVersionedHash(commitment)
+	}
+	return hashes
+}
+
+// ValidateBlobHashes checks the sidecar matches the blob versioned hashes of its
+// transaction, ie. as returned by DecodeBlobTransaction.
+func (s *BlobTxSidecar) ValidateBlobHashes(hashes []common.Hash) error {
+	if len(s.Blobs) != len(s.Commitments) || len(s.Blobs) != len(s.Proofs) {
+		return fmt.Errorf("ethcoder: sidecar has %d blobs, %d commitments and %d proofs", len(s.Blobs), len(s.Commitments), len(s.Proofs))
+	}
+	if len(s.Commitments) != len(hashes) {
+		return fmt.Errorf("%w: sidecar has %d commitments for %d blob hashes", ErrInvalidBlobVersionedHash, len(s.Commitments), len(hashes))
+	}
+	for i, hash := range s.BlobHashes() {
+		if hash != hashes[i] {
+			return fmt.Errorf("%w: blob %d has hash %s, expected %s", ErrInvalidBlobVersionedHash, i, hash.Hex(), hashes[i].Hex())
+		}
+	}
+	return nil
+}
+
+// blobTx is the rlp payload of a blob transaction, as defined by EIP-4844.
+type blobTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	Gas        uint64
+	To         common.Address
+	Value      *big.Int
+	Data       []byte
+	AccessList types.AccessList
+	BlobFeeCap *big.Int
+	BlobHashes []common.Hash
+	V, R, S    *big.Int
+}
+
+// blobTxWithSidecar is the network encoding of a blob transaction.
+type blobTxWithSidecar struct {
+	Tx          blobTx
+	Blobs       [][]byte
+	Commitments [][48]byte
+	Proofs      [][48]byte
+}
+
+// DecodeBlobTransaction decodes the blob versioned hashes of a raw EIP-4844 blob
+// transaction, along with its sidecar when the transaction is in its network encoding,
+// ie. as sent with eth_sendRawTransaction. The sidecar is nil for the canonical encoding,
+// ie. as included in blocks.
+//
+// NOTE: blob transactions are not supported by the underlying go-ethereum types, so a
+// *types.Transaction can't hold one, and blob transactions must be decoded from their
+// raw bytes instead.
+func DecodeBlobTransaction(rawTx []byte) ([]common.Hash, *BlobTxSidecar, error) {
+	if len(rawTx) == 0 || rawTx[0] != BlobTxType {
+		return nil, nil, fmt.Errorf("%w: not a blob transaction", ErrUnsupportedTransactionType)
+	}
+	payload := rawTx[1:]
+
+	// the network encoding wraps the transaction in a list along with the sidecar,
+	// while the canonical encoding starts with the chain id
+	content, _, err := rlp.SplitList(payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ethcoder: failed to decode blob transaction: %w", err)
+	}
+	kind, _, _, err := rlp.Split(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ethcoder: failed to decode blob transaction: %w", err)
+	}
+
+	if kind != rlp.List {
+		var tx blobTx
+		if err := rlp.DecodeBytes(payload, &tx); err != nil {
+			return nil, nil, fmt.Errorf("ethcoder: failed to decode blob transaction: %w", err)
+		}
+		return tx.BlobHashes, nil, nil
+	}
+
+	var tx blobTxWithSidecar
+	if err := rlp.DecodeBytes(payload, &tx); err != nil {
+		return nil, nil, fmt.Errorf("ethcoder: failed to decode blob transaction: %w", err)
+	}
+	sidecar := &BlobTxSidecar{
+		Blobs:       tx.Blobs,
+		Commitments: tx.Commitments,
+		Proofs:      tx.Proofs,
+	}
+	return tx.Tx.BlobHashes, sidecar, nil
+}
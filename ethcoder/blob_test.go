@@ -0,0 +1,105 @@
+package ethcoder_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xsequence/ethkit/ethcoder"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/0xsequence/ethkit/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobVersionedHash(t *testing.T) {
+	// the commitment of the zero blob is the compressed point at infinity
+	var zeroBlobCommitment [48]byte
+	zeroBlobCommitment[0] = 0xc0
+
+	var sequentialCommitment [48]byte
+	for i := range sequentialCommitment {
+		sequentialCommitment[i] = byte(i)
+	}
+
+	cases := []struct {
+		commitment [48]byte
+		expected   string
+	}{
+		{zeroBlobCommitment, "0x010657f37554c781402a22917dee2f75def7ab966d7b770905398eba3c444014"},
+		{sequentialCommitment, "0x01bdc2b2b62cb00749785bc84202236dbc3777d74660611b8e58812f0cfde6c3"},
+	}
+	for _, c := range cases {
+		hash := ethcoder.BlobVersionedHash(c.commitment)
+		require.Equal(t, c.expected, hash.Hex())
+		require.NoError(t, ethcoder.ValidateBlobVersionedHash(hash))
+	}
+
+	err := ethcoder.ValidateBlobVersionedHash(common.HexToHash("0x02bdc2b2b62cb00749785bc84202236dbc3777d74660611b8e58812f0cfde6c3"))
+	require.ErrorIs(t, err, ethcoder.ErrInvalidBlobVersionedHash)
+}
+
+func TestDecodeBlobTransaction(t *testing.T) {
+	type blobTx struct {
+		ChainID    *big.Int
+		Nonce      uint64
+		GasTipCap  *big.Int
+		GasFeeCap  *big.Int
+		Gas        uint64
+		To         common.Address
+		Value      *big.Int
+		Data       []byte
+		AccessList types.AccessList
+		BlobFeeCap *big.Int
+		BlobHashes []common.Hash
+		V, R, S    *big.Int
+	}
+
+	var commitment [48]byte
+	commitment[0] = 0xc0
+	sidecar := &ethcoder.BlobTxSidecar{
+		Blobs:       [][]byte{make([]byte, 131072)},
+		Commitments: [][48]byte{commitment},
+		Proofs:      [][48]byte{commitment},
+	}
+
+	tx := blobTx{
+		ChainID:    big.NewInt(1),
+		Nonce:      1,
+		GasTipCap:  big.NewInt(1),
+		GasFeeCap:  big.NewInt(100),
+		Gas:        21000,
+		To:         common.HexToAddress("0x000000000000000000000000000000000000dEaD"),
+		Value:      big.NewInt(0),
+		BlobFeeCap: big.NewInt(1),
+		BlobHashes: sidecar.BlobHashes(),
+		V:          big.NewInt(0),
+		R:          big.NewInt(1),
+		S:          big.NewInt(1),
+	}
+
+	// canonical encoding, as included in blocks
+	payload, err := rlp.EncodeToBytes(tx)
+	require.NoError(t, err)
+
+	hashes, decodedSidecar, err := ethcoder.DecodeBlobTransaction(append([]byte{ethcoder.BlobTxType}, payload...))
+	require.NoError(t, err)
+	require.Nil(t, decodedSidecar)
+	require.Equal(t, []common.Hash{common.HexToHash("0x010657f37554c781402a22917dee2f75def7ab966d7b770905398eba3c444014")}, hashes)
+
+	// network encoding, with the sidecar
+	payload, err = rlp.EncodeToBytes([]interface{}{tx, sidecar.Blobs, sidecar.Commitments, sidecar.Proofs})
+	require.NoError(t, err)
+
+	hashes, decodedSidecar, err = ethcoder.DecodeBlobTransaction(append([]byte{ethcoder.BlobTxType}, payload...))
+	require.NoError(t, err)
+	require.Equal(t, sidecar, decodedSidecar)
+	require.NoError(t, decodedSidecar.ValidateBlobHashes(hashes))
+
+	// a sidecar which doesn't match the blob hashes of its transaction
+	decodedSidecar.Commitments[0][1] = 0x01
+	require.ErrorIs(t, decodedSidecar.ValidateBlobHashes(hashes), ethcoder.ErrInvalidBlobVersionedHash)
+
+	// not a blob transaction
+	_, _, err = ethcoder.DecodeBlobTransaction(append([]byte{types.DynamicFeeTxType}, payload...))
+	require.ErrorIs(t, err, ethcoder.ErrUnsupportedTransactionType)
+}
@@ -0,0 +1,49 @@
+package ethcoder
+
+import (
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+)
+
+var (
+	// minimalProxyPrefix is the EIP-1167 creation code, followed by the start of the
+	// runtime code up to the implementation address.
+	minimalProxyPrefix = common.FromHex("0x3d602d80600a3d3981f3363d3d373d3d3d363d73")
+
+	// minimalProxySuffix is the rest of the EIP-1167 runtime code after the
+	// implementation address.
+	minimalProxySuffix = common.FromHex("0x5af43d82803e903d91602b57fd5bf3")
+)
+
+// Create2Address returns the address of a contract deployed with CREATE2 by the deployer,
+// ie. a factory contract, with the salt and init code, as defined by EIP-1014.
+func Create2Address(deployer common.Address, salt [32]byte, initCode []byte) common.Address {
+	return Create2AddressFromHash(deployer, salt, Keccak256Hash(initCode))
+}
+
+// Create2AddressFromHash is the same as Create2Address, but from the hash of the init code.
+func Create2AddressFromHash(deployer common.Address, salt [32]byte, initCodeHash common.Hash) common.Address {
+	data := make([]byte, 0, 1+20+32+32)
+	data = append(data, 0xff)
+	data = append(data, deployer.Bytes()...)
+	data = append(data, salt[:]...)
+	data = append(data, initCodeHash.Bytes()...)
+	return common.BytesToAddress(Keccak256(data)[12:])
+}
+
+// MinimalProxyInitCode returns the EIP-1167 init code of a minimal proxy which delegates
+// all calls to the implementation, as deployed by clone factories, ie. OpenZeppelin's
+// Clones library.
+func MinimalProxyInitCode(implementation common.Address) []byte {
+	initCode := make([]byte, 0, len(minimalProxyPrefix)+20+len(minimalProxySuffix))
+	initCode = append(initCode, minimalProxyPrefix...)
+	initCode = append(initCode, implementation.Bytes()...)
+	initCode = append(initCode, minimalProxySuffix...)
+	return initCode
+}
+
+// PredictCloneAddress returns the address of an EIP-1167 minimal proxy of the
+// implementation, deployed with CREATE2 by the factory with the salt, ie. OpenZeppelin's
+// Clones.predictDeterministicAddress.
+func PredictCloneAddress(factory common.Address, salt [32]byte, implementation common.Address) common.Address {
+	return Create2Address(factory, salt, MinimalProxyInitCode(implementation))
+}
@@ -0,0 +1,50 @@
+package ethcoder_test
+
+import (
+	"testing"
+
+	"github.com/0xsequence/ethkit/ethcoder"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreate2Address(t *testing.T) {
+	// vectors from EIP-1014
+	cases := []struct {
+		deployer string
+		salt     string
+		initCode string
+		expected string
+	}{
+		{"0x0000000000000000000000000000000000000000", "0x0000000000000000000000000000000000000000000000000000000000000000", "0x00", "0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38"},
+		{"0xdeadbeef00000000000000000000000000000000", "0x0000000000000000000000000000000000000000000000000000000000000000", "0x00", "0xB928f69Bb1D91Cd65274e3c79d8986362984fDA3"},
+		{"0xdeadbeef00000000000000000000000000000000", "0x000000000000000000000000feed000000000000000000000000000000000000", "0x00", "0xD04116cDd17beBE565EB2422F2497E06cC1C9833"},
+		{"0x0000000000000000000000000000000000000000", "0x0000000000000000000000000000000000000000000000000000000000000000", "0xdeadbeef", "0x70f2b2914A2a4b783FaEFb75f459A580616Fcb5e"},
+		{"0x00000000000000000000000000000000deadbeef", "0x00000000000000000000000000000000000000000000000000000000cafebabe", "0xdeadbeef", "0x60f3f640a8508fC6a86d45DF051962668E1e8AC7"},
+		{"0x00000000000000000000000000000000deadbeef", "0x00000000000000000000000000000000000000000000000000000000cafebabe", "0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef", "0x1d8bfDC5D46DC4f61D6b6115972536eBE6A8854C"},
+		{"0x0000000000000000000000000000000000000000", "0x0000000000000000000000000000000000000000000000000000000000000000", "0x", "0xE33C0C7F7df4809055C3ebA6c09CFe4BaF1BD9e0"},
+	}
+
+	for _, c := range cases {
+		salt := common.HexToHash(c.salt)
+		addr := ethcoder.Create2Address(common.HexToAddress(c.deployer), salt, common.FromHex(c.initCode))
+		assert.Equal(t, common.HexToAddress(c.expected), addr, c.expected)
+		assert.Equal(t, crypto.CreateAddress2(common.HexToAddress(c.deployer), salt, crypto.Keccak256(common.FromHex(c.initCode))), addr)
+	}
+}
+
+func TestPredictCloneAddress(t *testing.T) {
+	implementation := common.HexToAddress("0xbebebebebebebebebebebebebebebebebebebebe")
+
+	// init code from EIP-1167, which is the creation code followed by the runtime code
+	initCode := ethcoder.MinimalProxyInitCode(implementation)
+	assert.Equal(t, "0x3d602d80600a3d3981f3363d3d373d3d3d363d73bebebebebebebebebebebebebebebebebebebebe5af43d82803e903d91602b57fd5bf3", ethcoder.HexEncode(initCode))
+
+	factory := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	salt := common.HexToHash("0x01")
+
+	expected := crypto.CreateAddress2(factory, salt, crypto.Keccak256(initCode))
+	assert.Equal(t, expected, ethcoder.PredictCloneAddress(factory, salt, implementation))
+	assert.NotEqual(t, expected, ethcoder.PredictCloneAddress(factory, common.HexToHash("0x02"), implementation))
+}
@@ -0,0 +1,64 @@
+package ethcoder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+)
+
+// formatDataMaxBytes is the number of bytes of data shown by FormatLog, longer data
+// is truncated.
+const formatDataMaxBytes = 32
+
+// FormatTransaction returns a compact one-line summary of the transaction, ie.
+//
+//	txn 0x5c50..  from=0xf39f.. to=0x7099.. value=1000 nonce=3 method=0xa9059cbb
+//
+// Addresses and hashes are shown in full so they can be searched for in logs. The
+// sender is omitted when it can't be recovered, ie. for unsigned transactions, and
+// to is shown as "create" for contract deployments.
+func FormatTransaction(tx *types.Transaction) string {
+	if tx == nil {
+		return "txn <nil>"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "txn %s", tx.Hash().Hex())
+	if from, err := TransactionSender(tx, nil); err == nil {
+		fmt.Fprintf(&sb, " from=%s", from.Hex())
+	}
+	if tx.To() == nil {
+		sb.WriteString(" to=create")
+	} else {
+		fmt.Fprintf(&sb, " to=%s", tx.To().Hex())
+	}
+	fmt.Fprintf(&sb, " value=%s nonce=%d", tx.Value().String(), tx.Nonce())
+	if tx.To() != nil && len(tx.Data()) >= 4 {
+		fmt.Fprintf(&sb, " method=%s", hexutil.Encode(tx.Data()[:4]))
+	}
+	return sb.String()
+}
+
+// FormatLog returns a compact one-line summary of the log, ie.
+//
+//	log 0x7099.. block=12 index=0 topic0=0xddf2.. topics=3 data=0x0000..(32 bytes)
+//
+// Data longer than 32 bytes is truncated, removed logs are marked as such.
+func FormatLog(log types.Log) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "log %s block=%d index=%d", log.Address.Hex(), log.BlockNumber, log.Index)
+	if len(log.Topics) > 0 {
+		fmt.Fprintf(&sb, " topic0=%s topics=%d", log.Topics[0].Hex(), len(log.Topics))
+	}
+	if len(log.Data) > formatDataMaxBytes {
+		fmt.Fprintf(&sb, " data=%s..(%d bytes)", hexutil.Encode(log.Data[:formatDataMaxBytes]), len(log.Data))
+	} else if len(log.Data) > 0 {
+		fmt.Fprintf(&sb, " data=%s", hexutil.Encode(log.Data))
+	}
+	if log.Removed {
+		sb.WriteString(" removed")
+	}
+	return sb.String()
+}
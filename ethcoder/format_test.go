@@ -0,0 +1,41 @@
+package ethcoder_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xsequence/ethkit/ethcoder"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatTransaction(t *testing.T) {
+	key, err := crypto.HexToECDSA("4646464646464646464646464646464646464646464646464646464646464646")
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x3535353535353535353535353535353535353535")
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: 9, To: &to, Value: big.NewInt(1000), Gas: 21000, GasPrice: big.NewInt(1), Data: []byte{0xa9, 0x05, 0x9c, 0xbb, 0x01}})
+	signed, err := types.SignTx(tx, types.NewEIP155Signer(big.NewInt(1)), key)
+	require.NoError(t, err)
+
+	assert.Equal(t, "txn "+signed.Hash().Hex()+" from="+from.Hex()+" to="+to.Hex()+" value=1000 nonce=9 method=0xa9059cbb", ethcoder.FormatTransaction(signed))
+
+	// unsigned contract creation
+	create := types.NewTx(&types.LegacyTx{Nonce: 1, Value: big.NewInt(0), Data: []byte{0x60, 0x80, 0x60, 0x40}})
+	assert.Equal(t, "txn "+create.Hash().Hex()+" to=create value=0 nonce=1", ethcoder.FormatTransaction(create))
+}
+
+func TestFormatLog(t *testing.T) {
+	addr := common.HexToAddress("0x3535353535353535353535353535353535353535")
+	topic := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+
+	log := types.Log{Address: addr, BlockNumber: 12, Index: 3, Topics: []common.Hash{topic, {}}, Data: []byte{1, 2}}
+	assert.Equal(t, "log "+addr.Hex()+" block=12 index=3 topic0="+topic.Hex()+" topics=2 data=0x0102", ethcoder.FormatLog(log))
+
+	log = types.Log{Address: addr, BlockNumber: 12, Data: make([]byte, 64), Removed: true}
+	assert.Equal(t, "log "+addr.Hex()+" block=12 index=0 data=0x0000000000000000000000000000000000000000000000000000000000000000..(64 bytes) removed", ethcoder.FormatLog(log))
+}
@@ -0,0 +1,89 @@
+package ethcoder
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+)
+
+// EIP-1967 -- https://eips.ethereum.org/EIPS/eip-1967
+
+var (
+	eip1967ImplementationSlot = eip1967Slot("eip1967.proxy.implementation")
+	eip1967AdminSlot          = eip1967Slot("eip1967.proxy.admin")
+	eip1967BeaconSlot         = eip1967Slot("eip1967.proxy.beacon")
+)
+
+// EIP1967ImplementationSlot returns the storage slot of the implementation address
+// of an EIP-1967 proxy, ie. bytes32(uint256(keccak256('eip1967.proxy.implementation')) - 1)
+func EIP1967ImplementationSlot() common.Hash {
+	return eip1967ImplementationSlot
+}
+
+// EIP1967AdminSlot returns the storage slot of the admin address of an EIP-1967 proxy.
+func EIP1967AdminSlot() common.Hash {
+	return eip1967AdminSlot
+}
+
+// EIP1967BeaconSlot returns the storage slot of the beacon address of an EIP-1967 beacon proxy.
+func EIP1967BeaconSlot() common.Hash {
+	return eip1967BeaconSlot
+}
+
+// ProxyReader is the set of node methods used to resolve a proxy's implementation,
+// which *ethrpc.Provider satisfies.
+type ProxyReader interface {
+	StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error)
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// ResolveImplementation returns the implementation address of an EIP-1967 proxy contract
+// by reading the standard storage slots. For beacon proxies, the implementation is
+// resolved by calling `implementation()` on the beacon contract.
+func ResolveImplementation(ctx context.Context, provider ProxyReader, proxy common.Address) (common.Address, error) {
+	implementation, err := readAddressSlot(ctx, provider, proxy, eip1967ImplementationSlot)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if implementation != (common.Address{}) {
+		return implementation, nil
+	}
+
+	beacon, err := readAddressSlot(ctx, provider, proxy, eip1967BeaconSlot)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if beacon == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("ethcoder: %s is not an EIP-1967 proxy", proxy.Hex())
+	}
+
+	calldata, err := AbiEncodeMethodCalldata("implementation()", nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+	output, err := provider.CallContract(ctx, ethereum.CallMsg{To: &beacon, Data: calldata}, nil)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("ethcoder: failed to call implementation() on beacon %s: %w", beacon.Hex(), err)
+	}
+	if len(output) != 32 {
+		return common.Address{}, fmt.Errorf("ethcoder: unexpected implementation() response from beacon %s", beacon.Hex())
+	}
+	return common.BytesToAddress(output), nil
+}
+
+func readAddressSlot(ctx context.Context, provider ProxyReader, account common.Address, slot common.Hash) (common.Address, error) {
+	value, err := provider.StorageAt(ctx, account, slot, nil)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("ethcoder: failed to read storage slot %s of %s: %w", slot.Hex(), account.Hex(), err)
+	}
+	return common.BytesToAddress(value), nil
+}
+
+func eip1967Slot(label string) common.Hash {
+	slot := big.NewInt(0).SetBytes(Keccak256([]byte(label)))
+	slot.Sub(slot, big.NewInt(1))
+	return common.BigToHash(slot)
+}
@@ -0,0 +1,60 @@
+package ethcoder_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/0xsequence/ethkit/ethcoder"
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEIP1967Slots(t *testing.T) {
+	assert.Equal(t, "0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc", ethcoder.EIP1967ImplementationSlot().Hex())
+	assert.Equal(t, "0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103", ethcoder.EIP1967AdminSlot().Hex())
+	assert.Equal(t, "0xa3f0ad74e5423aebfd80d3ef4346578335a9a72aeaee59ff6cb3582b35133d50", ethcoder.EIP1967BeaconSlot().Hex())
+}
+
+type mockProxyReader struct {
+	storage map[common.Address]map[common.Hash]common.Hash
+	calls   map[common.Address][]byte
+}
+
+func (m *mockProxyReader) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return m.storage[account][key].Bytes(), nil
+}
+
+func (m *mockProxyReader) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return m.calls[*msg.To], nil
+}
+
+func TestResolveImplementation(t *testing.T) {
+	proxy := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	beaconProxy := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	beacon := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	implementation := common.HexToAddress("0x4444444444444444444444444444444444444444")
+
+	reader := &mockProxyReader{
+		storage: map[common.Address]map[common.Hash]common.Hash{
+			proxy:       {ethcoder.EIP1967ImplementationSlot(): common.BytesToHash(implementation.Bytes())},
+			beaconProxy: {ethcoder.EIP1967BeaconSlot(): common.BytesToHash(beacon.Bytes())},
+		},
+		calls: map[common.Address][]byte{
+			beacon: common.BytesToHash(implementation.Bytes()).Bytes(),
+		},
+	}
+
+	addr, err := ethcoder.ResolveImplementation(context.Background(), reader, proxy)
+	require.NoError(t, err)
+	assert.Equal(t, implementation, addr)
+
+	addr, err = ethcoder.ResolveImplementation(context.Background(), reader, beaconProxy)
+	require.NoError(t, err)
+	assert.Equal(t, implementation, addr)
+
+	_, err = ethcoder.ResolveImplementation(context.Background(), reader, implementation)
+	assert.Error(t, err)
+}
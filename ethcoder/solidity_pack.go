@@ -209,6 +209,194 @@ func solidityArgumentPack(typ string, val interface{}, isArray bool) ([]byte, er
 	return nil, fmt.Errorf("unknown type '%s'", typ)
 }
 
+// DecodePacked decodes the tightly packed encoding produced by SolidityPack (or solidity's
+// abi.encodePacked) back into values of the given types. As packed values carry no length
+// or offset information, decoding is only possible when all but at most one of the types
+// are fixed-width, in which case the dynamic type takes the remaining bytes. The dynamic
+// type may be a string, bytes or an array T[] of a fixed-width T.
+//
+// Values are returned as common.Address, bool, string, []byte, *big.Int for (u)intX,
+// [N]byte for bytesN, and slices of those for arrays.
+func DecodePacked(argTypes []string, data []byte) ([]interface{}, error) {
+	sizes := make([]int, len(argTypes))
+	fixedSize, dynamicIdx := 0, -1
+	for i, typ := range argTypes {
+		size, err := solidityArgumentPackedSize(typ, false)
+		if err != nil {
+			return nil, err
+		}
+		sizes[i] = size
+		if size >= 0 {
+			fixedSize += size
+			continue
+		}
+		if dynamicIdx >= 0 {
+			return nil, fmt.Errorf("ambiguous packed schema - types '%s' and '%s' are both dynamic, at most one dynamic type can be decoded", argTypes[dynamicIdx], typ)
+		}
+		dynamicIdx = i
+	}
+
+	if dynamicIdx < 0 && len(data) != fixedSize {
+		return nil, fmt.Errorf("invalid data length - expecting %d bytes, got %d", fixedSize, len(data))
+	}
+	if dynamicIdx >= 0 {
+		if len(data) < fixedSize {
+			return nil, fmt.Errorf("invalid data length - expecting at least %d bytes, got %d", fixedSize, len(data))
+		}
+		sizes[dynamicIdx] = len(data) - fixedSize
+	}
+
+	values := make([]interface{}, len(argTypes))
+	offset := 0
+	for i, typ := range argTypes {
+		v, err := solidityArgumentUnpack(typ, data[offset:offset+sizes[i]], false)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+		offset += sizes[i]
+	}
+	return values, nil
+}
+
+// solidityArgumentPackedSize returns the packed size of the type in bytes, or -1 if the
+// type is dynamic.
+func solidityArgumentPackedSize(typ string, isArray bool) (int, error) {
+	switch typ {
+	case "address":
+		if isArray {
+			return 32, nil
+		}
+		return 20, nil
+
+	case "bool":
+		if isArray {
+			return 32, nil
+		}
+		return 1, nil
+
+	case "string", "bytes":
+		return -1, nil
+	}
+
+	// numbers
+	if match := regexArgNumber.FindStringSubmatch(typ); len(match) > 0 {
+		size, err := strconv.ParseInt(match[2], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		if (size%8 != 0) || size == 0 || size > 256 {
+			return 0, fmt.Errorf("invalid number type '%s'", typ)
+		}
+		if isArray {
+			return 32, nil
+		}
+		return int(size / 8), nil
+	}
+
+	// bytes
+	if match := regexArgBytes.FindStringSubmatch(typ); len(match) > 0 {
+		size, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		if size == 0 || size > 32 {
+			return 0, fmt.Errorf("invalid number type '%s'", typ)
+		}
+		if isArray {
+			return 0, fmt.Errorf("unsupported, file ticket.")
+		}
+		return int(size), nil
+	}
+
+	// arrays
+	if match := regexArgArray.FindStringSubmatch(typ); len(match) > 0 {
+		elemSize, err := solidityArgumentPackedSize(match[1], true)
+		if err != nil {
+			return 0, err
+		}
+		if elemSize < 0 {
+			return 0, fmt.Errorf("ambiguous packed type '%s' - arrays of dynamic types cannot be decoded", typ)
+		}
+		if match[2] == "" {
+			return -1, nil
+		}
+		count, err := strconv.ParseInt(match[2], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return int(count) * elemSize, nil
+	}
+
+	return 0, fmt.Errorf("unknown type '%s'", typ)
+}
+
+func solidityArgumentUnpack(typ string, data []byte, isArray bool) (interface{}, error) {
+	switch typ {
+	case "address":
+		return common.BytesToAddress(data), nil
+
+	case "bool":
+		switch data[len(data)-1] {
+		case 0:
+			return false, nil
+		case 1:
+			return true, nil
+		default:
+			return nil, fmt.Errorf("invalid bool value 0x%x", data)
+		}
+
+	case "string":
+		return string(data), nil
+
+	case "bytes":
+		return common.CopyBytes(data), nil
+	}
+
+	// numbers
+	if match := regexArgNumber.FindStringSubmatch(typ); len(match) > 0 {
+		num := new(big.Int).SetBytes(data)
+		if match[1] == "int" && len(data) > 0 && data[0]&0x80 != 0 {
+			// two's complement
+			num.Sub(num, new(big.Int).Lsh(big.NewInt(1), uint(len(data)*8)))
+		}
+		return num, nil
+	}
+
+	// bytes
+	if regexArgBytes.MatchString(typ) {
+		v := reflect.New(reflect.ArrayOf(len(data), reflect.TypeOf(byte(0)))).Elem()
+		reflect.Copy(v, reflect.ValueOf(data))
+		return v.Interface(), nil
+	}
+
+	// arrays
+	if match := regexArgArray.FindStringSubmatch(typ); len(match) > 0 {
+		baseTyp := match[1]
+		if len(data)%32 != 0 {
+			return nil, fmt.Errorf("invalid data length for type '%s' - %d is not a multiple of 32", typ, len(data))
+		}
+		size := len(data) / 32
+
+		// decode a zero value to learn the element type, so empty arrays are typed too
+		zero, err := solidityArgumentUnpack(baseTyp, make([]byte, 32), true)
+		if err != nil {
+			return nil, err
+		}
+		arr := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(zero)), size, size)
+		for i := 0; i < size; i++ {
+			v, err := solidityArgumentUnpack(baseTyp, data[i*32:(i+1)*32], true)
+			if err != nil {
+				return nil, err
+			}
+			arr.Index(i).Set(reflect.ValueOf(v))
+		}
+		return arr.Interface(), nil
+	}
+
+	return nil, fmt.Errorf("unknown type '%s'", typ)
+}
+
 func PadZeros(array []byte, totalLength int) ([]byte, error) {
 	if len(array) > totalLength {
 		return nil, fmt.Errorf("array is larger than total expected length")
@@ -121,3 +121,83 @@ func TestSolidityPack(t *testing.T) {
 		assert.Equal(t, "0x00000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000001", h)
 	}
 }
+
+func TestDecodePacked(t *testing.T) {
+	addr := common.HexToAddress("0x39d28D4c4191a584acabe021F5B905887a6B5247")
+
+	// fixed-width types with a trailing dynamic string
+	{
+		argTypes := []string{"address", "uint32", "bool", "bytes4", "uint256[]", "string"}
+		argValues := []interface{}{addr, uint32(4242), true, [4]byte{1, 2, 3, 4}, []*big.Int{big.NewInt(1), big.NewInt(2)}, "peϣer"}
+
+		packed, err := SolidityPack(argTypes, argValues)
+		assert.NoError(t, err)
+
+		// uint256[] is dynamic too, so the schema is ambiguous
+		_, err = DecodePacked(argTypes, packed)
+		assert.ErrorContains(t, err, "ambiguous")
+
+		argTypes[4] = "uint256[2]"
+		values, err := DecodePacked(argTypes, packed)
+		assert.NoError(t, err)
+		assert.Len(t, values, 6)
+		assert.Equal(t, addr, values[0])
+		assert.Equal(t, big.NewInt(4242), values[1])
+		assert.Equal(t, true, values[2])
+		assert.Equal(t, [4]byte{1, 2, 3, 4}, values[3])
+		assert.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(2)}, values[4])
+		assert.Equal(t, "peϣer", values[5])
+	}
+
+	// dynamic type in the middle
+	{
+		packed, err := SolidityPack([]string{"uint8", "bytes", "address"}, []interface{}{uint8(7), []byte{0, 1, 2, 3}, addr})
+		assert.NoError(t, err)
+
+		values, err := DecodePacked([]string{"uint8", "bytes", "address"}, packed)
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(7), values[0])
+		assert.Equal(t, []byte{0, 1, 2, 3}, values[1])
+		assert.Equal(t, addr, values[2])
+	}
+
+	// dynamic array
+	{
+		packed, err := SolidityPack([]string{"address[]"}, []interface{}{[]common.Address{addr, addr}})
+		assert.NoError(t, err)
+
+		values, err := DecodePacked([]string{"address[]"}, packed)
+		assert.NoError(t, err)
+		assert.Equal(t, []common.Address{addr, addr}, values[0])
+
+		values, err = DecodePacked([]string{"bool[]"}, []byte{})
+		assert.NoError(t, err)
+		assert.Equal(t, []bool{}, values[0])
+	}
+
+	// signed numbers
+	{
+		values, err := DecodePacked([]string{"int8", "int16"}, []byte{0xff, 0x10, 0x92})
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(-1), values[0])
+		assert.Equal(t, big.NewInt(4242), values[1])
+	}
+
+	// errors
+	{
+		_, err := DecodePacked([]string{"string", "bytes"}, []byte{1, 2, 3})
+		assert.ErrorContains(t, err, "ambiguous")
+
+		_, err = DecodePacked([]string{"string[]"}, []byte{1, 2, 3})
+		assert.ErrorContains(t, err, "ambiguous")
+
+		_, err = DecodePacked([]string{"uint32"}, []byte{1, 2, 3})
+		assert.ErrorContains(t, err, "invalid data length")
+
+		_, err = DecodePacked([]string{"address", "string"}, []byte{1, 2, 3})
+		assert.ErrorContains(t, err, "invalid data length")
+
+		_, err = DecodePacked([]string{"bool"}, []byte{2})
+		assert.Error(t, err)
+	}
+}
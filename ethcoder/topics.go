@@ -0,0 +1,31 @@
+package ethcoder
+
+import (
+	"math/big"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/math"
+)
+
+// TopicToAddress returns the address of an indexed `address` event argument, which
+// is stored in the low 20 bytes of the left-padded topic.
+func TopicToAddress(topic common.Hash) common.Address {
+	return common.BytesToAddress(topic[12:])
+}
+
+// TopicToBig returns the value of an indexed `uint` event argument.
+func TopicToBig(topic common.Hash) *big.Int {
+	return big.NewInt(0).SetBytes(topic[:])
+}
+
+// AddressToTopic returns the topic of an indexed `address` event argument, ie. for
+// filtering logs, by left-padding the address to 32 bytes.
+func AddressToTopic(addr common.Address) common.Hash {
+	return common.BytesToHash(addr.Bytes())
+}
+
+// BigToTopic returns the topic of an indexed `uint` or `int` event argument, by left-padding
+// the value to 32 bytes. Negative values are encoded in two's complement.
+func BigToTopic(n *big.Int) common.Hash {
+	return common.BytesToHash(math.U256Bytes(big.NewInt(0).Set(n)))
+}
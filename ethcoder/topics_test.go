@@ -0,0 +1,25 @@
+package ethcoder_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xsequence/ethkit/ethcoder"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopics(t *testing.T) {
+	addr := common.HexToAddress("0x6615e4e985bf0d137196897dfa182dbd7127f54f")
+	topic := ethcoder.AddressToTopic(addr)
+	assert.Equal(t, "0x0000000000000000000000006615e4e985bf0d137196897dfa182dbd7127f54f", topic.Hex())
+	assert.Equal(t, addr, ethcoder.TopicToAddress(topic))
+
+	topic = ethcoder.BigToTopic(big.NewInt(1000))
+	assert.Equal(t, "0x00000000000000000000000000000000000000000000000000000000000003e8", topic.Hex())
+	assert.Equal(t, big.NewInt(1000), ethcoder.TopicToBig(topic))
+
+	// negative values are two's complement
+	topic = ethcoder.BigToTopic(big.NewInt(-1))
+	assert.Equal(t, "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", topic.Hex())
+}
@@ -0,0 +1,63 @@
+package ethcoder
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+)
+
+// TransactionSigningHash returns the digest which the sender signs for the transaction,
+// following the EIP-2718 typed transaction envelope rules for legacy (EIP-155),
+// access list (EIP-2930) and dynamic fee (EIP-1559) transactions.
+//
+// Passing a nil or zero chainID will return the pre-EIP-155 (homestead) signing hash
+// for legacy transactions, and the chain id of the transaction itself for typed ones.
+//
+// NOTE: blob transactions (EIP-4844) are not supported by the underlying go-ethereum
+// types, and an empty hash is returned for any unsupported transaction type.
+func TransactionSigningHash(tx *types.Transaction, chainID *big.Int) common.Hash {
+	return transactionSigner(tx, chainID).Hash(tx)
+}
+
+// TransactionHash returns the hash which identifies the transaction on chain, ie.
+// keccak256(rlp(tx)) for legacy transactions and keccak256(type || rlp(tx)) for
+// EIP-2718 typed transactions.
+func TransactionHash(tx *types.Transaction) common.Hash {
+	return tx.Hash()
+}
+
+var ErrUnsupportedTransactionType = errors.New("ethcoder: unsupported transaction type")
+
+// TransactionSender recovers the address which signed the transaction, selecting the
+// signer based on the transaction type and chainID. Passing a nil or zero chainID will
+// use the chain id of the transaction itself, and the homestead signer for unprotected
+// legacy transactions.
+func TransactionSender(tx *types.Transaction, chainID *big.Int) (common.Address, error) {
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType:
+	default:
+		return common.Address{}, fmt.Errorf("%w: %d", ErrUnsupportedTransactionType, tx.Type())
+	}
+
+	if (chainID == nil || chainID.Sign() == 0) && tx.Protected() {
+		chainID = tx.ChainId()
+	}
+	sender, err := types.Sender(transactionSigner(tx, chainID), tx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("ethcoder: failed to recover transaction sender: %w", err)
+	}
+	return sender, nil
+}
+
+func transactionSigner(tx *types.Transaction, chainID *big.Int) types.Signer {
+	if chainID == nil || chainID.Sign() == 0 {
+		if tx.Type() == types.LegacyTxType {
+			return types.HomesteadSigner{}
+		}
+		chainID = tx.ChainId()
+	}
+	return types.NewLondonSigner(chainID)
+}
@@ -0,0 +1,104 @@
+package ethcoder_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xsequence/ethkit/ethcoder"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionHashes(t *testing.T) {
+	// vectors are derived from the EIP-155 example transaction, signed with
+	// private key 0x4646..46 as each of the supported envelope types.
+	cases := []struct {
+		name        string
+		raw         string
+		txType      uint8
+		signingHash string
+		txHash      string
+	}{
+		{
+			name:        "legacy (eip-155)",
+			raw:         "0xf86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83",
+			txType:      types.LegacyTxType,
+			signingHash: "0xdaf5a779ae972f972197303d7b574746c7ef83eadac0f2791ad23db92e4c8e53",
+			txHash:      "0x33469b22e9f636356c4160a87eb19df52b7412e8eac32a4a55ffe88ea8350788",
+		},
+		{
+			name:        "access list (eip-2930)",
+			raw:         "0x01f8a701098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a764000080f838f7943535353535353535353535353535353535353535e1a0000000000000000000000000000000000000000000000000000000000000000180a0381f5272732aaecaa36e57234061be5c675ad65be3a7a8900421c6fe9060965fa01dc3c104e6b5f480132d393594bf20f1375e716a60a449b3bf855cd51f61b870",
+			txType:      types.AccessListTxType,
+			signingHash: "0xa4441968a0519a1bb79f9a4c352397415c411f98ff1e3bead9dea1f8813aaa37",
+			txHash:      "0xe4eae69c2439a5b9b8f32eb7f0a31e965c0dca62947e2df71990fc6c32937249",
+		},
+		{
+			name:        "dynamic fee (eip-1559)",
+			raw:         "0x02f8ac0109843b9aca008504a817c800825208943535353535353535353535353535353535353535880de0b6b3a764000080f838f7943535353535353535353535353535353535353535e1a0000000000000000000000000000000000000000000000000000000000000000180a0864e594585fd82fb8051e8d392017a52a21b839b6cdee779fa75f4668b2adf70a04cb347d800f1eabd8432ffcdf50a67ded4ff0f3e6776174c6fa04875d8ecb152",
+			txType:      types.DynamicFeeTxType,
+			signingHash: "0x16aa1164b16e9d548c2002d21cc2cc941eb434fa176ac278bdf3acd155d178a3",
+			txHash:      "0xa69edb3bff229ef3e635120543393cf03f1a7f036cd749ac7229b7e19741479f",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tx := &types.Transaction{}
+			err := tx.UnmarshalBinary(ethcoder.MustHexDecode(c.raw))
+			require.NoError(t, err)
+			require.Equal(t, c.txType, tx.Type())
+
+			assert.Equal(t, c.signingHash, ethcoder.TransactionSigningHash(tx, big.NewInt(1)).Hex())
+			assert.Equal(t, c.txHash, ethcoder.TransactionHash(tx).Hex())
+
+			// typed transactions carry their own chain id
+			if tx.Type() != types.LegacyTxType {
+				assert.Equal(t, c.signingHash, ethcoder.TransactionSigningHash(tx, nil).Hex())
+			}
+
+			sender, err := ethcoder.TransactionSender(tx, big.NewInt(1))
+			require.NoError(t, err)
+			assert.Equal(t, "0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F", sender.Hex())
+
+			sender, err = ethcoder.TransactionSender(tx, nil)
+			require.NoError(t, err)
+			assert.Equal(t, "0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F", sender.Hex())
+
+			_, err = ethcoder.TransactionSender(tx, big.NewInt(5))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTransactionSigningHashHomestead(t *testing.T) {
+	to := common.HexToAddress("0x3535353535353535353535353535353535353535")
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    9,
+		GasPrice: big.NewInt(20000000000),
+		Gas:      21000,
+		To:       &to,
+		Value:    big.NewInt(1000000000000000000),
+	})
+
+	// pre eip-155 signing hash, without replay protection
+	assert.Equal(t, "0xf9e36c28c8cb35adba138005c02ab7aa7fbcd891f3139cb2eeed052a51cd2713", ethcoder.TransactionSigningHash(tx, nil).Hex())
+	assert.Equal(t, "0xdaf5a779ae972f972197303d7b574746c7ef83eadac0f2791ad23db92e4c8e53", ethcoder.TransactionSigningHash(tx, big.NewInt(1)).Hex())
+}
+
+func TestTransactionSenderHomestead(t *testing.T) {
+	key, err := crypto.HexToECDSA("4646464646464646464646464646464646464646464646464646464646464646")
+	require.NoError(t, err)
+
+	to := common.HexToAddress("0x3535353535353535353535353535353535353535")
+	tx, err := types.SignTx(types.NewTransaction(9, to, big.NewInt(1), 21000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	require.NoError(t, err)
+	require.False(t, tx.Protected())
+
+	sender, err := ethcoder.TransactionSender(tx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), sender)
+}
@@ -1,6 +1,7 @@
 package ethcoder
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"sort"
@@ -219,3 +220,73 @@ func (t *TypedData) EncodeDigest() ([]byte, error) {
 
 	return hashBytes, nil
 }
+
+var (
+	ErrTypedDataDomainMismatch    = errors.New("ethcoder: typed data domain does not match the expected domain")
+	ErrTypedDataSignatureMismatch = errors.New("ethcoder: typed data signature does not match the expected signer")
+)
+
+// VerifyTypedData verifies that sig is the EIP-712 signature of the typed data by the
+// expectedSigner, ie. as signed with eth_signTypedData. The typed data domain is first
+// checked against the expectedDomain to prevent the replay of a signature made for
+// another chain or contract. The expectedDomain must set the chainId and the
+// verifyingContract, while its other fields are only compared when set. A domain
+// mismatch, or an expectedDomain without a chainId or verifyingContract, returns
+// ErrTypedDataDomainMismatch, and a signature made by another signer returns
+// ErrTypedDataSignatureMismatch.
+func VerifyTypedData(typedData *TypedData, expectedDomain TypedDataDomain, sig []byte, expectedSigner common.Address) (bool, error) {
+	if err := typedData.Domain.match(expectedDomain); err != nil {
+		return false, err
+	}
+
+	if len(sig) != 65 {
+		return false, fmt.Errorf("ethcoder: signature is not of proper length (=65)")
+	}
+
+	digest, err := typedData.EncodeDigest()
+	if err != nil {
+		return false, err
+	}
+
+	recoverySig := make([]byte, 65)
+	copy(recoverySig, sig)
+	if recoverySig[64] > 1 {
+		recoverySig[64] -= 27 // recovery ID
+	}
+
+	pubkey, err := crypto.SigToPub(digest, recoverySig)
+	if err != nil {
+		return false, err
+	}
+	signer := crypto.PubkeyToAddress(*pubkey)
+	if signer != expectedSigner {
+		return false, fmt.Errorf("%w: recovered %s, expected %s", ErrTypedDataSignatureMismatch, signer.Hex(), expectedSigner.Hex())
+	}
+
+	return true, nil
+}
+
+// match returns ErrTypedDataDomainMismatch if a field set in the expected domain differs
+// from the domain. The expected domain must set the chainId and verifyingContract, as
+// otherwise a signature made for any chain or contract would match.
+func (t TypedDataDomain) match(expected TypedDataDomain) error {
+	if expected.ChainID == nil || expected.VerifyingContract == nil {
+		return fmt.Errorf("%w: expected domain must set the chainId and verifyingContract", ErrTypedDataDomainMismatch)
+	}
+	if expected.Name != "" && t.Name != expected.Name {
+		return fmt.Errorf("%w: name %q, expected %q", ErrTypedDataDomainMismatch, t.Name, expected.Name)
+	}
+	if expected.Version != "" && t.Version != expected.Version {
+		return fmt.Errorf("%w: version %q, expected %q", ErrTypedDataDomainMismatch, t.Version, expected.Version)
+	}
+	if t.ChainID == nil || t.ChainID.Cmp(expected.ChainID) != 0 {
+		return fmt.Errorf("%w: chainId %v, expected %v", ErrTypedDataDomainMismatch, t.ChainID, expected.ChainID)
+	}
+	if t.VerifyingContract == nil || *t.VerifyingContract != *expected.VerifyingContract {
+		return fmt.Errorf("%w: verifyingContract %v, expected %s", ErrTypedDataDomainMismatch, t.VerifyingContract, expected.VerifyingContract.Hex())
+	}
+	if expected.Salt != nil && (t.Salt == nil || *t.Salt != *expected.Salt) {
+		return fmt.Errorf("%w: salt does not match", ErrTypedDataDomainMismatch)
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/0xsequence/ethkit/ethcoder"
 	"github.com/0xsequence/ethkit/ethwallet"
 	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -142,3 +143,81 @@ func TestTypedDataCase2(t *testing.T) {
 	// fmt.Println("===> digest", HexEncode(digest))
 
 }
+
+func TestVerifyTypedData(t *testing.T) {
+	verifyingContract := common.HexToAddress("0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC")
+
+	typedData := &ethcoder.TypedData{
+		Types: ethcoder.TypedDataTypes{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Person": {
+				{Name: "name", Type: "string"},
+				{Name: "wallet", Type: "address"},
+			},
+		},
+		PrimaryType: "Person",
+		Domain: ethcoder.TypedDataDomain{
+			Name:              "Ether Mail",
+			Version:           "1",
+			ChainID:           big.NewInt(1),
+			VerifyingContract: &verifyingContract,
+		},
+		Message: map[string]interface{}{
+			"name":   "Bob",
+			"wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB",
+		},
+	}
+
+	wallet, err := ethwallet.NewWalletFromMnemonic("dose weasel clever culture letter volume endorse used harvest ripple circle install")
+	assert.NoError(t, err)
+
+	digest, err := typedData.EncodeDigest()
+	assert.NoError(t, err)
+	sig, err := crypto.Sign(digest, wallet.PrivateKey())
+	assert.NoError(t, err)
+	sig[64] += 27
+
+	expectedDomain := ethcoder.TypedDataDomain{ChainID: big.NewInt(1), VerifyingContract: &verifyingContract}
+
+	valid, err := ethcoder.VerifyTypedData(typedData, expectedDomain, sig, wallet.Address())
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	// signed by someone else
+	valid, err = ethcoder.VerifyTypedData(typedData, expectedDomain, sig, common.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"))
+	assert.ErrorIs(t, err, ethcoder.ErrTypedDataSignatureMismatch)
+	assert.False(t, valid)
+
+	// signed for another chain
+	valid, err = ethcoder.VerifyTypedData(typedData, ethcoder.TypedDataDomain{ChainID: big.NewInt(137), VerifyingContract: &verifyingContract}, sig, wallet.Address())
+	assert.ErrorIs(t, err, ethcoder.ErrTypedDataDomainMismatch)
+	assert.False(t, valid)
+
+	// signed for another contract
+	otherContract := common.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")
+	valid, err = ethcoder.VerifyTypedData(typedData, ethcoder.TypedDataDomain{ChainID: big.NewInt(1), VerifyingContract: &otherContract}, sig, wallet.Address())
+	assert.ErrorIs(t, err, ethcoder.ErrTypedDataDomainMismatch)
+	assert.False(t, valid)
+
+	// the expected domain must set the chainId and verifyingContract
+	for _, domain := range []ethcoder.TypedDataDomain{
+		{},
+		{ChainID: big.NewInt(1)},
+		{VerifyingContract: &verifyingContract},
+	} {
+		valid, err = ethcoder.VerifyTypedData(typedData, domain, sig, wallet.Address())
+		assert.ErrorIs(t, err, ethcoder.ErrTypedDataDomainMismatch)
+		assert.False(t, valid)
+	}
+
+	// the message was tampered with
+	typedData.Message["name"] = "Alice"
+	valid, err = ethcoder.VerifyTypedData(typedData, expectedDomain, sig, wallet.Address())
+	assert.ErrorIs(t, err, ethcoder.ErrTypedDataSignatureMismatch)
+	assert.False(t, valid)
+}
@@ -0,0 +1,85 @@
+package ethmonitor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/goware/superr"
+)
+
+var (
+	ErrAnomaly               = errors.New("ethmonitor: block anomaly")
+	ErrNonMonotonicTimestamp = errors.New("ethmonitor: block timestamp is before its parent")
+	ErrInvalidBlock          = errors.New("ethmonitor: block rejected by validator")
+	ErrFutureTimestamp       = errors.New("ethmonitor: block timestamp is in the future")
+)
+
+// Anomaly is a block which failed one of the monitor's consistency checks, ie. when
+// Options.ValidateTimestamps is enabled. Err wraps ErrAnomaly, and the reason for the
+// anomaly, ie. ErrNonMonotonicTimestamp, ErrInvalidBlock or ErrFutureTimestamp.
+type Anomaly struct {
+	Block *types.Block
+	Err   error
+}
+
+// reportAnomaly logs the anomaly, and passes it to the Options.OnAnomaly hook if set.
+func (m *Monitor) reportAnomaly(block *types.Block, reason error) {
+	anomaly := Anomaly{Block: block, Err: superr.New(ErrAnomaly, reason)}
+	m.log.Warnf("ethmonitor: anomaly on block #%d hash:%s: %v", block.NumberU64(), block.Hash().Hex(), reason)
+	if m.options.OnAnomaly != nil {
+		m.options.OnAnomaly(anomaly)
+	}
+}
+
+// validateTimestamp reports an anomaly if the block's timestamp is earlier than its
+// parent's, beyond the configured tolerance.
+func (m *Monitor) validateTimestamp(parent *Block, block *types.Block) {
+	if !m.options.ValidateTimestamps || parent == nil {
+		return
+	}
+	tolerance := uint64(m.options.TimestampTolerance.Seconds())
+	if block.Time()+tolerance < parent.Time() {
+		m.reportAnomaly(block, ErrNonMonotonicTimestamp)
+	}
+}
+
+// validateFutureTimestamp rejects the block if its timestamp is later than the local clock
+// by more than the FutureTimestampTolerance, reporting it as an anomaly.
+func (m *Monitor) validateFutureTimestamp(block *types.Block) error {
+	if m.options.FutureTimestampTolerance <= 0 {
+		return nil
+	}
+	now := time.Now()
+	if block.Time() <= uint64(now.Add(m.options.FutureTimestampTolerance).Unix()) {
+		return nil
+	}
+	ahead := time.Unix(int64(block.Time()), 0).Sub(now).Round(time.Second)
+	if block.Time() > math.MaxInt64 {
+		ahead = time.Duration(math.MaxInt64)
+	}
+	reason := superr.New(ErrFutureTimestamp, fmt.Errorf("timestamp %d is %s ahead of the local clock", block.Time(), ahead))
+	m.reportAnomaly(block, reason)
+	return reason
+}
+
+// validateBlock rejects future-dated blocks and runs the Options.BlockValidator on the
+// block, and reports an anomaly if the block is rejected, in which case the block must not
+// be pushed to the chain.
+func (m *Monitor) validateBlock(block *types.Block) error {
+	if err := m.validateFutureTimestamp(block); err != nil {
+		return err
+	}
+	if m.options.BlockValidator == nil {
+		return nil
+	}
+	err := m.options.BlockValidator(block)
+	if err == nil {
+		return nil
+	}
+	reason := superr.New(ErrInvalidBlock, err)
+	m.reportAnomaly(block, reason)
+	return reason
+}
@@ -0,0 +1,203 @@
+package ethmonitor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func mockBlockWithTime(parent *types.Block, blockNum int, timestamp uint64) *types.Block {
+	parentHash := common.Hash{}
+	if parent != nil {
+		parentHash = parent.Hash()
+	}
+	return types.NewBlockWithHeader(&types.Header{
+		ParentHash: parentHash,
+		Number:     big.NewInt(int64(blockNum)),
+		Time:       timestamp,
+	})
+}
+
+func TestValidateTimestamps(t *testing.T) {
+	anomalies := []Anomaly{}
+
+	opts := DefaultOptions
+	opts.ValidateTimestamps = true
+	opts.TimestampTolerance = 0
+	opts.OnAnomaly = func(anomaly Anomaly) {
+		anomalies = append(anomalies, anomaly)
+	}
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	b1 := mockBlockWithTime(nil, 1, 100)
+	b2 := mockBlockWithTime(b1, 2, 102)
+	b3 := mockBlockWithTime(b2, 3, 90) // backwards
+	b4 := mockBlockWithTime(b3, 4, 104)
+
+	var events Blocks
+	for _, b := range []*types.Block{b1, b2, b3, b4} {
+		events, err = monitor.buildCanonicalChain(context.Background(), b, events)
+		require.NoError(t, err)
+	}
+
+	// the block is still accepted, but flagged
+	require.Len(t, events, 4)
+	require.Len(t, anomalies, 1)
+	require.Equal(t, b3.Hash(), anomalies[0].Block.Hash())
+	require.ErrorIs(t, anomalies[0].Err, ErrAnomaly)
+	require.ErrorIs(t, anomalies[0].Err, ErrNonMonotonicTimestamp)
+
+	// the negative interval is ignored by the average block time
+	require.Greater(t, monitor.GetAverageBlockTime(), float64(0))
+	require.Less(t, monitor.GetAverageBlockTime(), float64(100))
+}
+
+func TestValidateTimestampsTolerance(t *testing.T) {
+	anomalies := 0
+
+	opts := DefaultOptions
+	opts.ValidateTimestamps = true
+	opts.TimestampTolerance = 15 * time.Second
+	opts.OnAnomaly = func(anomaly Anomaly) {
+		anomalies++
+	}
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	b1 := mockBlockWithTime(nil, 1, 100)
+	b2 := mockBlockWithTime(b1, 2, 90)
+	b3 := mockBlockWithTime(b2, 3, 70)
+
+	var events Blocks
+	for _, b := range []*types.Block{b1, b2, b3} {
+		events, err = monitor.buildCanonicalChain(context.Background(), b, events)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, anomalies)
+}
+
+func TestBlockValidator(t *testing.T) {
+	anomalies := []Anomaly{}
+
+	opts := DefaultOptions
+	opts.BlockValidator = func(block *types.Block) error {
+		if block.Time() == 0 {
+			return errors.New("missing timestamp")
+		}
+		return nil
+	}
+	opts.OnAnomaly = func(anomaly Anomaly) {
+		anomalies = append(anomalies, anomaly)
+	}
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	b1 := mockBlockWithTime(nil, 1, 100)
+	events, err := monitor.buildCanonicalChain(context.Background(), b1, Blocks{})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Empty(t, anomalies)
+
+	// an invalid block is reported and not pushed
+	b2 := mockBlockWithTime(b1, 2, 0)
+	_, err = monitor.buildCanonicalChain(context.Background(), b2, Blocks{})
+	require.ErrorIs(t, err, ErrInvalidBlock)
+	require.Equal(t, b1.Hash(), monitor.chain.Head().Hash())
+
+	require.Len(t, anomalies, 1)
+	require.Equal(t, b2.Hash(), anomalies[0].Block.Hash())
+	require.ErrorIs(t, anomalies[0].Err, ErrAnomaly)
+	require.ErrorIs(t, anomalies[0].Err, ErrInvalidBlock)
+
+	// invalid external blocks are rejected too
+	err = monitor.IngestExternalBlocks(Blocks{{Event: Added, Block: b2, OK: true}})
+	require.ErrorIs(t, err, ErrInvalidBlock)
+	require.Equal(t, b1.Hash(), monitor.chain.Head().Hash())
+}
+
+func TestFutureTimestampAverageBlockTime(t *testing.T) {
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+
+	now := uint64(time.Now().Unix())
+	yearAhead := uint64(time.Now().AddDate(1, 0, 0).Unix())
+
+	b1 := mockBlockWithTime(nil, 1, now-48)
+	b2 := mockBlockWithTime(b1, 2, now-36)
+	b3 := mockBlockWithTime(b2, 3, yearAhead)
+	b4 := mockBlockWithTime(b3, 4, now-12)
+	b5 := mockBlockWithTime(b4, 5, now)
+
+	// future-dated blocks are accepted when the check is disabled, but don't skew the
+	// average block time
+	for _, b := range []*types.Block{b1, b2, b3, b4, b5} {
+		require.NoError(t, monitor.chain.push(&Block{Block: b, Event: Added}))
+	}
+	require.Equal(t, float64(12), monitor.GetAverageBlockTime())
+}
+
+func TestFutureTimestamp(t *testing.T) {
+	now := uint64(time.Now().Unix())
+	yearAhead := uint64(time.Now().AddDate(1, 0, 0).Unix())
+
+	newChain := func(futureBlock int) []*types.Block {
+		bc := []*types.Block{}
+		for i := 1; i <= 5; i++ {
+			header := &types.Header{Number: big.NewInt(int64(i)), Difficulty: big.NewInt(0), Time: now - uint64(60-12*i)}
+			if i == futureBlock {
+				header.Time = yearAhead
+			}
+			if i > 1 {
+				header.ParentHash = bc[i-2].Hash()
+			}
+			bc = append(bc, types.NewBlockWithHeader(header))
+		}
+		return bc
+	}
+
+	var chain atomic.Value
+	chain.Store(newChain(3))
+
+	var anomalies int32
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.FutureTimestampTolerance = time.Minute
+	opts.OnAnomaly = func(anomaly Anomaly) {
+		if errors.Is(anomaly.Err, ErrFutureTimestamp) && anomaly.Block.NumberU64() == 3 {
+			atomic.AddInt32(&anomalies, 1)
+		}
+	}
+
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	// the future-dated block is rejected, and fetched again on the next poll
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&anomalies) >= 2
+	}, 5*time.Second, 5*time.Millisecond)
+	require.Equal(t, uint64(2), monitor.LatestBlock().NumberU64())
+
+	// the monitor recovers once the node serves a valid block
+	chain.Store(newChain(0))
+	require.Eventually(t, func() bool {
+		head := monitor.LatestBlock()
+		return head != nil && head.NumberU64() == 5
+	}, 5*time.Second, 5*time.Millisecond)
+	require.Equal(t, float64(12), monitor.GetAverageBlockTime())
+}
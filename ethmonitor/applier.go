@@ -0,0 +1,92 @@
+package ethmonitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrReset is returned by the Applier on a Reset event, as the blocks before a
+// Monitor.Reset are not reverted, so the store must be reset along with the monitor.
+var ErrReset = errors.New("ethmonitor: monitor was reset, the store must be reset along with it")
+
+// Store is the storage of an indexer which is driven by an Applier.
+type Store interface {
+	// ApplyBlock applies the block, which was added to the canonical chain.
+	ApplyBlock(ctx context.Context, block *Block) error
+
+	// RevertBlock reverts the block, which was previously applied and has been
+	// removed from the canonical chain by a reorg.
+	RevertBlock(ctx context.Context, block *Block) error
+
+	// Commit atomically commits the blocks applied and reverted since the last commit.
+	Commit(ctx context.Context) error
+}
+
+// Applier drives a Store with the events of a Subscription, applying added blocks and
+// reverting removed blocks in the order they're published, so the store follows the
+// canonical chain across reorgs. The monitor publishes the blocks removed by a reorg
+// from the old head down, followed by the added blocks from the common ancestor up, so
+// reverting and applying in event order always leaves the store on the canonical chain,
+// even when a reorg happens in the middle of a batch.
+//
+// Each published batch is committed as a whole, so the store is never left on a chain
+// which the monitor never published. On a Reset event, the Applier stops with ErrReset,
+// as the blocks before a Monitor.Reset are not reverted, so the store must be reset
+// along with the monitor before applying the events which follow.
+type Applier struct {
+	store Store
+}
+
+func NewApplier(store Store) *Applier {
+	return &Applier{store: store}
+}
+
+// Run applies the events of the subscription to the store until the context is done or
+// the subscription is unsubscribed. If the store fails to apply, revert or commit a
+// batch, Run returns the error without committing the batch, in which case the store
+// must discard its uncommitted changes. Run returns ErrReset on a Reset event.
+func (a *Applier) Run(ctx context.Context, sub Subscription) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-sub.Done():
+			return nil
+
+		case blocks, ok := <-sub.Blocks():
+			if !ok {
+				return nil
+			}
+			err := a.Apply(ctx, blocks)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Apply applies a batch of published events to the store, and commits it. A batch with a
+// Reset event is not committed, and ErrReset is returned.
+func (a *Applier) Apply(ctx context.Context, blocks Blocks) error {
+	for _, block := range blocks {
+		switch block.Event {
+		case Reset:
+			return ErrReset
+		case Added:
+			if err := a.store.ApplyBlock(ctx, block); err != nil {
+				return fmt.Errorf("ethmonitor: failed to apply block #%d hash:%s: %w", block.NumberU64(), block.Hash().Hex(), err)
+			}
+		case Removed:
+			if err := a.store.RevertBlock(ctx, block); err != nil {
+				return fmt.Errorf("ethmonitor: failed to revert block #%d hash:%s: %w", block.NumberU64(), block.Hash().Hex(), err)
+			}
+		}
+	}
+
+	if err := a.store.Commit(ctx); err != nil {
+		return fmt.Errorf("ethmonitor: failed to commit blocks: %w", err)
+	}
+	return nil
+}
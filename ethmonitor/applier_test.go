@@ -0,0 +1,157 @@
+package ethmonitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockStore struct {
+	ops       []string
+	committed []string
+	failAt    uint64
+	commits   chan struct{}
+}
+
+func (s *mockStore) ApplyBlock(ctx context.Context, block *Block) error {
+	if block.NumberU64() == s.failAt {
+		return errors.New("store failure")
+	}
+	s.ops = append(s.ops, fmt.Sprintf("apply %d:%s", block.NumberU64(), block.Hash().Hex()[:6]))
+	return nil
+}
+
+func (s *mockStore) RevertBlock(ctx context.Context, block *Block) error {
+	s.ops = append(s.ops, fmt.Sprintf("revert %d:%s", block.NumberU64(), block.Hash().Hex()[:6]))
+	return nil
+}
+
+func (s *mockStore) Commit(ctx context.Context) error {
+	s.committed = append(s.committed, s.ops...)
+	s.ops = nil
+	if s.commits != nil {
+		s.commits <- struct{}{}
+	}
+	return nil
+}
+
+func TestApplier(t *testing.T) {
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+
+	bc := mockBlockchain(4)
+	fork := mockChain(bc, 2, 5)
+
+	label := func(op string, b *Block) string {
+		return fmt.Sprintf("%s %d:%s", op, b.NumberU64(), b.Hash().Hex()[:6])
+	}
+
+	canonical := Blocks{}
+	for _, b := range bc {
+		canonical = append(canonical, &Block{Block: b, Event: Added, OK: true})
+	}
+	forked := Blocks{}
+	for _, b := range fork[2:] {
+		forked = append(forked, &Block{Block: b, Event: Added, OK: true})
+	}
+	removed := func(b *Block) *Block {
+		return &Block{Block: b.Block, Event: Removed, OK: true}
+	}
+
+	store := &mockStore{commits: make(chan struct{}, 2)}
+	applier := NewApplier(store)
+
+	sub := monitor.Subscribe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		done <- applier.Run(ctx, sub)
+	}()
+
+	// a batch of new blocks, followed by a batch with a reorg in the middle of it, ie.
+	// block 4 is added, then blocks 4 and 3 are replaced by the fork
+	monitor.broadcast(canonical[:3])
+	monitor.broadcast(Blocks{canonical[3], removed(canonical[3]), removed(canonical[2]), forked[0], forked[1], forked[2]})
+
+	expected := []string{
+		label("apply", canonical[0]),
+		label("apply", canonical[1]),
+		label("apply", canonical[2]),
+		label("apply", canonical[3]),
+		label("revert", canonical[3]),
+		label("revert", canonical[2]),
+		label("apply", forked[0]),
+		label("apply", forked[1]),
+		label("apply", forked[2]),
+	}
+
+	// every batch is committed as a whole
+	for i := 0; i < 2; i++ {
+		select {
+		case <-store.commits:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for commit")
+		}
+	}
+
+	sub.Unsubscribe()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("applier did not stop")
+	}
+
+	require.Equal(t, expected, store.committed)
+	require.Empty(t, store.ops)
+}
+
+func TestApplierFailure(t *testing.T) {
+	bc := mockBlockchain(3)
+	blocks := Blocks{}
+	for _, b := range bc {
+		blocks = append(blocks, &Block{Block: b, Event: Added, OK: true})
+	}
+
+	store := &mockStore{failAt: 3}
+	applier := NewApplier(store)
+
+	require.NoError(t, applier.Apply(context.Background(), blocks[:1]))
+	require.Len(t, store.committed, 1)
+
+	// the failed batch is not committed
+	err := applier.Apply(context.Background(), blocks[1:])
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to apply block #3")
+	require.Len(t, store.committed, 1)
+}
+
+func TestApplierReset(t *testing.T) {
+	bc := mockBlockchain(2)
+
+	store := &mockStore{}
+	applier := NewApplier(store)
+
+	require.NoError(t, applier.Apply(context.Background(), Blocks{{Block: bc[0], Event: Added, OK: true}}))
+	require.Len(t, store.committed, 1)
+
+	// the store must be reset along with the monitor
+	err := applier.Apply(context.Background(), Blocks{{Block: bc[0], Event: Reset, OK: true}})
+	require.ErrorIs(t, err, ErrReset)
+	require.Len(t, store.committed, 1)
+
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	monitor.broadcast(Blocks{{Block: bc[1], Event: Reset, OK: true}})
+	require.ErrorIs(t, applier.Run(context.Background(), sub), ErrReset)
+}
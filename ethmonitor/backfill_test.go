@@ -0,0 +1,136 @@
+package ethmonitor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyLogsProvider fails to fetch the logs of a block a number of times, and then
+// returns them unsorted and with duplicates.
+type flakyLogsProvider struct {
+	fakeProvider
+	failBlock common.Hash
+	failures  int32
+}
+
+func (p *flakyLogsProvider) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if *q.BlockHash != p.failBlock {
+		return []types.Log{}, nil
+	}
+	if atomic.AddInt32(&p.failures, -1) >= 0 {
+		return nil, errors.New("getLogs failed")
+	}
+	logs := []types.Log{}
+	for _, i := range []uint{2, 0, 1, 0} {
+		logs = append(logs, types.Log{BlockHash: p.failBlock, TxHash: common.BigToHash(big.NewInt(int64(i))), Index: i})
+	}
+	return logs, nil
+}
+
+func TestLogsBackfillPublishedOnce(t *testing.T) {
+	bc := mockChain(nil, 0, 5)
+	provider := &flakyLogsProvider{failBlock: bc[2].Hash(), failures: 3}
+	provider.bc.Store(bc)
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.WithLogs = true
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	events := Blocks{}
+	for len(events) == 0 || events.LatestBlock().NumberU64() < 5 {
+		select {
+		case blocks := <-sub.Blocks():
+			events = append(events, blocks...)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+
+	// every block is published once and in order, including the backfilled block
+	require.Len(t, events, 5)
+	for i, b := range events {
+		require.Equal(t, Added, b.Event)
+		require.Equal(t, uint64(i+1), b.NumberU64())
+		require.True(t, b.OK)
+	}
+	require.LessOrEqual(t, atomic.LoadInt32(&provider.failures), int32(-1))
+
+	logs := events[2].Logs
+	require.Len(t, logs, 3)
+	for i, log := range logs {
+		require.Equal(t, uint(i), log.Index)
+	}
+}
+
+// slowLogsProvider tracks the max number of concurrent getLogs calls.
+type slowLogsProvider struct {
+	fakeProvider
+	calls    int32
+	inflight int32
+	max      int32
+}
+
+func (p *slowLogsProvider) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	atomic.AddInt32(&p.calls, 1)
+	n := atomic.AddInt32(&p.inflight, 1)
+	defer atomic.AddInt32(&p.inflight, -1)
+	for {
+		max := atomic.LoadInt32(&p.max)
+		if n <= max || atomic.CompareAndSwapInt32(&p.max, max, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return []types.Log{{BlockHash: *q.BlockHash}}, nil
+}
+
+func TestBackfillChainLogsConcurrently(t *testing.T) {
+	bc := mockChain(nil, 0, 10)
+	provider := &slowLogsProvider{}
+	provider.bc.Store(bc)
+
+	opts := DefaultOptions
+	opts.WithLogs = true
+	opts.MaxConcurrentFetches = 3
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+
+	// every other block is missing its logs
+	for i, b := range bc {
+		require.NoError(t, monitor.chain.push(&Block{Block: b, Event: Added, OK: i%2 == 0}))
+	}
+
+	monitor.backfillChainLogs(context.Background())
+
+	for _, b := range monitor.chain.Blocks() {
+		require.True(t, b.OK)
+		if b.NumberU64()%2 == 0 {
+			require.Len(t, b.Logs, 1)
+			require.Equal(t, b.Hash(), b.Logs[0].BlockHash)
+		}
+	}
+	require.Equal(t, int32(5), atomic.LoadInt32(&provider.calls))
+	require.LessOrEqual(t, atomic.LoadInt32(&provider.max), int32(3))
+	require.Greater(t, atomic.LoadInt32(&provider.max), int32(1))
+}
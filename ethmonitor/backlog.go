@@ -0,0 +1,50 @@
+package ethmonitor
+
+// SubscribeWithBacklog subscribes to the monitor, the same as Subscribe, and returns the
+// last n blocks of the canonical chain published so far as an initial batch of Added
+// events, so late subscribers can hydrate their state without a separate query. The
+// snapshot is taken under the same lock the monitor publishes with, so the subscription
+// continues exactly where the backlog ends, without any gap or duplicate event.
+//
+// The backlog is bounded by the retained blocks, see Options.BlockRetentionLimit.
+func (m *Monitor) SubscribeWithBacklog(n int) (Subscription, Blocks) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub := m.newSubscriber(nil, false)
+
+	if n > len(m.published) {
+		n = len(m.published)
+	}
+	if n <= 0 {
+		return sub, Blocks{}
+	}
+	backlog := make(Blocks, n)
+	copy(backlog, m.published[len(m.published)-n:])
+	return sub, backlog
+}
+
+// recordPublished applies the published events to the canonical chain as seen by the
+// subscribers, which is the backlog of SubscribeWithBacklog. It must be called with
+// m.mu held.
+func (m *Monitor) recordPublished(events Blocks) {
+	for _, block := range events {
+		switch block.Event {
+		case Added:
+			m.published = append(m.published, block)
+
+		case Removed:
+			if n := len(m.published); n > 0 && m.published[n-1].Hash() == block.Hash() {
+				m.published[n-1] = nil
+				m.published = m.published[:n-1]
+			}
+
+		case Reset:
+			m.published = nil
+		}
+	}
+
+	if over := len(m.published) - m.chain.retentionLimit; over > 0 {
+		m.published = append(m.published[:0:0], m.published[over:]...)
+	}
+}
@@ -0,0 +1,120 @@
+package ethmonitor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeWithBacklog(t *testing.T) {
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+
+	bc := mockChain(nil, 0, 4)
+	fork := mockChain(bc, 2, 6)
+
+	canonical := Blocks{}
+	for _, b := range bc {
+		canonical = append(canonical, &Block{Block: b, Event: Added, OK: true})
+	}
+	forked := Blocks{}
+	for _, b := range fork {
+		forked = append(forked, &Block{Block: b, Event: Added, OK: true})
+	}
+	removed := func(b *Block) *Block {
+		return &Block{Block: b.Block, Event: Removed, OK: true}
+	}
+
+	hashes := func(blocks Blocks) []common.Hash {
+		h := []common.Hash{}
+		for _, b := range blocks {
+			h = append(h, b.Hash())
+		}
+		return h
+	}
+
+	// nothing published yet
+	sub, backlog := monitor.SubscribeWithBacklog(3)
+	require.Empty(t, backlog)
+	sub.Unsubscribe()
+
+	// blocks 3 and 4 are reorged away, so the backlog only holds canonical blocks
+	monitor.broadcast(canonical)
+	monitor.broadcast(Blocks{removed(canonical[3]), removed(canonical[2]), forked[2], forked[3], forked[4]})
+
+	sub, backlog = monitor.SubscribeWithBacklog(3)
+	defer sub.Unsubscribe()
+	require.Equal(t, hashes(forked[2:5]), hashes(backlog))
+	for _, b := range backlog {
+		require.Equal(t, Added, b.Event)
+	}
+
+	_, backlog = monitor.SubscribeWithBacklog(10)
+	require.Equal(t, hashes(forked[:5]), hashes(backlog))
+
+	_, backlog = monitor.SubscribeWithBacklog(0)
+	require.Empty(t, backlog)
+
+	// the subscription continues where the backlog ends
+	monitor.broadcast(forked[5:])
+	select {
+	case blocks := <-sub.Blocks():
+		require.Equal(t, hashes(forked[5:]), hashes(blocks))
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for events")
+	}
+
+	// a reset clears the backlog
+	monitor.broadcast(Blocks{{Block: fork[5], Event: Reset, OK: true}})
+	_, backlog = monitor.SubscribeWithBacklog(3)
+	require.Empty(t, backlog)
+}
+
+func TestSubscribeWithBacklogWhileRunning(t *testing.T) {
+	bc := mockChain(nil, 0, 50)
+	provider := &fakeProvider{}
+	provider.bc.Store(bc[:1])
+
+	opts := DefaultOptions
+	opts.PollingInterval = time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	// grow the chain while subscribing mid-run
+	go func() {
+		for i := 2; i <= len(bc); i++ {
+			provider.bc.Store(bc[:i])
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	sub, backlog := monitor.SubscribeWithBacklog(len(bc))
+	defer sub.Unsubscribe()
+
+	// the backlog and the live events make up the chain without gaps or duplicates
+	blocks := backlog
+	for len(blocks) == 0 || blocks.LatestBlock().NumberU64() < uint64(len(bc)) {
+		select {
+		case events := <-sub.Blocks():
+			blocks = append(blocks, events...)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+	require.Len(t, blocks, len(bc))
+	for i, b := range blocks {
+		require.Equal(t, Added, b.Event)
+		require.Equal(t, bc[i].Hash(), b.Hash())
+	}
+}
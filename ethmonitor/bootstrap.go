@@ -34,7 +34,7 @@ func (c *Chain) bootstrapBlocks(blocks Blocks) error {
 	}
 
 	if len(blocks) == 0 {
-		c.blocks = make(Blocks, 0, c.retentionLimit)
+		c.blocks = make(Blocks, 0, c.retentionLimit+c.retentionSlack+1)
 		return nil
 	}
 
@@ -43,7 +43,7 @@ func (c *Chain) bootstrapBlocks(blocks Blocks) error {
 		return nil
 	}
 
-	c.blocks = make(Blocks, 0, c.retentionLimit)
+	c.blocks = make(Blocks, 0, c.retentionLimit+c.retentionSlack+1)
 
 	if len(blocks) > c.retentionLimit {
 		blocks = blocks[c.retentionLimit-1:]
@@ -55,7 +55,7 @@ func (c *Chain) bootstrapBlocks(blocks Blocks) error {
 			if err != nil {
 				return fmt.Errorf("ethmonitor: bootstrap failed to build canonical chain: %w", err)
 			}
-		} else {
+		} else if b.Event == Removed {
 			c.pop()
 		}
 	}
@@ -63,6 +63,8 @@ func (c *Chain) bootstrapBlocks(blocks Blocks) error {
 	return nil
 }
 
+// Snapshot serializes the retained canonical chain, including the logs of the blocks,
+// which can be restored with Monitor.LoadSnapshot or Chain.BootstrapFromBlocksJSON.
 func (c *Chain) Snapshot() ([]byte, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -75,19 +77,94 @@ func (c *Chain) Snapshot() ([]byte, error) {
 	return data, nil
 }
 
+// LoadSnapshot restores the canonical chain from a Chain.Snapshot, ie. one taken before
+// a restart, so the monitor resumes from the head of the snapshot on Run instead of
+// re-scanning from StartBlockNumber. The monitor must not be running, and its chain must
+// not have been built yet, ie. it has not been run, bootstrapped or ingested blocks.
+//
+// The snapshot is rejected with a descriptive error if it is corrupt, ie. if it holds
+// blocks other than Added blocks, blocks which don't link to their parent by hash and
+// number, or logs of other blocks. The oldest blocks beyond the BlockRetentionLimit are
+// dropped.
+func (m *Monitor) LoadSnapshot(data []byte) error {
+	if m.IsRunning() {
+		return fmt.Errorf("ethmonitor: cannot load snapshot while running")
+	}
+
+	// check for missing blocks and headers upfront, as the block methods would panic
+	var headers []*struct {
+		Block *struct {
+			Header json.RawMessage `json:"header"`
+		} `json:"block"`
+	}
+	err := json.Unmarshal(data, &headers)
+	if err != nil {
+		return fmt.Errorf("ethmonitor: invalid snapshot: %w", err)
+	}
+	for i, h := range headers {
+		if h == nil || h.Block == nil || len(h.Block.Header) == 0 || string(h.Block.Header) == "null" {
+			return fmt.Errorf("ethmonitor: invalid snapshot: block at index %d is missing its header", i)
+		}
+	}
+
+	var blocks Blocks
+	err = json.Unmarshal(data, &blocks)
+	if err != nil {
+		return fmt.Errorf("ethmonitor: invalid snapshot: %w", err)
+	}
+
+	// validate with a scratch chain, so the monitor's chain is left untouched on error
+	chain := newChain(m.chain.retentionLimit, m.chain.retentionSlack, false)
+	chain.blockLinkFunc = m.chain.blockLinkFunc
+	chain.sampling = m.chain.sampling
+
+	for i, block := range blocks {
+		if block.Event != Added {
+			return fmt.Errorf("ethmonitor: invalid snapshot: block #%d %s at index %d is not an added block", block.NumberU64(), block.Hash().Hex(), i)
+		}
+		for _, log := range block.Logs {
+			if log.BlockHash != block.Hash() {
+				return fmt.Errorf("ethmonitor: invalid snapshot: block #%d %s holds a log of block %s", block.NumberU64(), block.Hash().Hex(), log.BlockHash.Hex())
+			}
+		}
+		err := chain.push(block)
+		if err != nil {
+			head := chain.Head()
+			return fmt.Errorf("ethmonitor: invalid snapshot: block #%d %s does not follow block #%d %s: %w", block.NumberU64(), block.Hash().Hex(), head.NumberU64(), head.Hash().Hex(), err)
+		}
+	}
+
+	m.chain.mu.Lock()
+	if len(m.chain.blocks) > 0 {
+		m.chain.mu.Unlock()
+		return fmt.Errorf("ethmonitor: cannot load snapshot, chain has already been built")
+	}
+	m.chain.blocks = make(Blocks, len(chain.blocks), m.chain.retentionLimit+m.chain.retentionSlack+1)
+	copy(m.chain.blocks, chain.blocks)
+	m.chain.averageBlockTime = chain.averageBlockTime
+	m.chain.mu.Unlock()
+
+	if m.options.WithLogs {
+		m.updateLogIndex(chain.blocks)
+	}
+	return nil
+}
+
 type blockSnapshot struct {
-	Block *types.Block `json:"block"`
-	Event Event        `json:"event"`
-	Logs  []types.Log  `json:"logs"`
-	OK    bool         `json:"ok"`
+	Block    *types.Block     `json:"block"`
+	Event    Event            `json:"event"`
+	Logs     []types.Log      `json:"logs"`
+	Receipts []*types.Receipt `json:"receipts,omitempty"`
+	OK       bool             `json:"ok"`
 }
 
 func (b *Block) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&blockSnapshot{
-		Block: b.Block,
-		Event: b.Event,
-		Logs:  b.Logs,
-		OK:    b.OK,
+		Block:    b.Block,
+		Event:    b.Event,
+		Logs:     b.Logs,
+		Receipts: b.receipts,
+		OK:       b.OK,
 	})
 }
 
@@ -100,6 +177,7 @@ func (b *Block) UnmarshalJSON(data []byte) error {
 	b.Block = s.Block
 	b.Event = s.Event
 	b.Logs = s.Logs
+	b.receipts = s.Receipts
 	b.OK = s.OK
 	return nil
 }
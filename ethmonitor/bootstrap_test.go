@@ -0,0 +1,111 @@
+package ethmonitor
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func mockSnapshotChain(t *testing.T, size int) *Chain {
+	chain := newChain(100, 0, false)
+
+	parentHash := common.Hash{}
+	for i := 1; i <= size; i++ {
+		block := types.NewBlockWithHeader(&types.Header{
+			ParentHash: parentHash,
+			Number:     big.NewInt(int64(i)),
+			Difficulty: big.NewInt(0),
+			Time:       uint64(i * 12),
+		})
+		logs := []types.Log{{
+			Address:     common.HexToAddress("0xaa"),
+			Topics:      []common.Hash{common.HexToHash("0x01")},
+			BlockNumber: block.NumberU64(),
+			BlockHash:   block.Hash(),
+		}}
+		require.NoError(t, chain.push(&Block{Block: block, Event: Added, Logs: logs, OK: true}))
+		parentHash = block.Hash()
+	}
+	return chain
+}
+
+func TestLoadSnapshot(t *testing.T) {
+	snapshot, err := mockSnapshotChain(t, 20).Snapshot()
+	require.NoError(t, err)
+
+	opts := DefaultOptions
+	opts.BlockRetentionLimit = 15
+	opts.WithLogs = true
+	opts.IndexLogsByAddress = true
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+	require.NoError(t, monitor.LoadSnapshot(snapshot))
+
+	blocks := monitor.Chain().Blocks()
+	require.Len(t, blocks, 15)
+	require.Equal(t, uint64(6), blocks.Tail().NumberU64())
+	require.Equal(t, uint64(20), blocks.Head().NumberU64())
+	require.Len(t, blocks.Head().Logs, 1)
+	require.Equal(t, float64(12), monitor.Chain().GetAverageBlockTime())
+	require.Len(t, monitor.GetLogsByAddress(common.HexToAddress("0xaa")), 15)
+
+	// the chain has already been built
+	require.Error(t, monitor.LoadSnapshot(snapshot))
+
+	// loads in bootstrap mode too
+	opts.Bootstrap = true
+	monitor, err = NewMonitor(nil, opts)
+	require.NoError(t, err)
+	require.NoError(t, monitor.LoadSnapshot(snapshot))
+	require.Equal(t, uint64(20), monitor.Chain().Head().NumberU64())
+}
+
+func TestLoadSnapshotCorrupt(t *testing.T) {
+	chain := mockSnapshotChain(t, 5)
+	snapshot, err := chain.Snapshot()
+	require.NoError(t, err)
+
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+
+	// not json
+	err = monitor.LoadSnapshot(snapshot[:len(snapshot)/2])
+	require.ErrorContains(t, err, "invalid snapshot")
+
+	// missing header
+	err = monitor.LoadSnapshot([]byte(`[{"block":{"header":null},"event":0}]`))
+	require.ErrorContains(t, err, "missing its header")
+
+	// tampered block header, which changes the hash of block #3
+	tampered := strings.Replace(string(snapshot), `"timestamp":"0x24"`, `"timestamp":"0x25"`, 1)
+	err = monitor.LoadSnapshot([]byte(tampered))
+	require.ErrorContains(t, err, "block #3")
+	require.ErrorContains(t, err, "holds a log of block")
+
+	// gap in the chain
+	blocks := chain.Blocks()
+	blocks = append(blocks[:2], blocks[3:]...)
+	gap, err := (&Chain{blocks: blocks}).Snapshot()
+	require.NoError(t, err)
+	err = monitor.LoadSnapshot(gap)
+	require.ErrorIs(t, err, ErrUnexpectedParentHash)
+	require.ErrorContains(t, err, "does not follow block #2")
+
+	// removed blocks
+	blocks = chain.Blocks().Copy()
+	blocks[4].Event = Removed
+	removed, err := (&Chain{blocks: blocks}).Snapshot()
+	require.NoError(t, err)
+	err = monitor.LoadSnapshot(removed)
+	require.ErrorContains(t, err, "not an added block")
+
+	// the monitor's chain is untouched
+	require.Empty(t, monitor.Chain().Blocks())
+	require.NoError(t, monitor.LoadSnapshot(snapshot))
+	require.Equal(t, uint64(5), monitor.Chain().Head().NumberU64())
+}
@@ -0,0 +1,95 @@
+package ethmonitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/goware/superr"
+)
+
+// catchUpRangeSize is the max number of blocks fetched per tick while catching up.
+const catchUpRangeSize = 100
+
+// fetchCatchUpBlocks fetches the range of blocks following nextBlockNumber concurrently
+// when the monitor is more than Options.CatchUpThreshold blocks behind the head, and
+// returns nil if catch-up is disabled or the monitor is near the head, in which case the
+// next block is fetched on its own. The head block number is only refreshed once every
+// CatchUpThreshold blocks, so catching up adds little rpc cost once the monitor is synced.
+func (m *Monitor) fetchCatchUpBlocks(ctx context.Context) ([]*types.Block, error) {
+	threshold := uint64(m.options.CatchUpThreshold)
+	if threshold == 0 || m.nextBlockNumber == nil {
+		return nil, nil
+	}
+	next := m.nextBlockNumber.Uint64()
+
+	if m.catchUpHead == 0 || next >= m.catchUpCheckedAt+threshold {
+		head, err := m.provider.(blockNumberProvider).BlockNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		m.catchUpHead, m.catchUpCheckedAt = head, next
+		m.observeRemoteHead(head)
+	}
+	if m.catchUpHead <= next+threshold {
+		return nil, nil
+	}
+
+	to := next + catchUpRangeSize - 1
+	if to > m.catchUpHead {
+		to = m.catchUpHead
+	}
+
+	m.log.Debugf("ethmonitor: catching up %d blocks behind head, fetching blocks #%d-%d", m.catchUpHead-next, next, to)
+
+	blocks, err := m.fetchBlockRange(ctx, next, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// assert the range is a chain, as the blocks may be served by different nodes
+	for i := 1; i < len(blocks); i++ {
+		if !m.chain.blockLinkFunc(blocks[i-1], blocks[i]) {
+			return nil, superr.New(ErrUnexpectedParentHash, fmt.Errorf("block #%d does not follow block #%d", blocks[i].NumberU64(), blocks[i-1].NumberU64()))
+		}
+	}
+
+	return blocks, nil
+}
+
+// fetchBlockRange fetches the blocks in the inclusive range [from, to] with at most
+// Options.MaxConcurrentFetches requests in flight.
+func (m *Monitor) fetchBlockRange(ctx context.Context, from, to uint64) ([]*types.Block, error) {
+	concurrency := m.options.MaxConcurrentFetches
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	blocks := make([]*types.Block, to-from+1)
+	errs := make([]error, len(blocks))
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i := range blocks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			num := big.NewInt(0).SetUint64(from + uint64(i))
+			blocks[i], errs[i] = m.fetchBlockByNumber(ctx, num)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch block #%d: %w", from+uint64(i), err)
+		}
+	}
+	return blocks, nil
+}
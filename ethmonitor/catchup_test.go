@@ -0,0 +1,96 @@
+package ethmonitor
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatchUp(t *testing.T) {
+	var chain atomic.Value
+	chain.Store(mockChain(nil, 0, 250))
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.CatchUpThreshold = 10
+	opts.MaxConcurrentFetches = 4
+	opts.BlockRetentionLimit = 400
+
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	// the first range is fetched and published at once
+	select {
+	case blocks := <-sub.Blocks():
+		require.Len(t, blocks, catchUpRangeSize)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for events")
+	}
+
+	var next uint64 = catchUpRangeSize + 1
+	for next <= 250 {
+		select {
+		case blocks := <-sub.Blocks():
+			for _, b := range blocks {
+				require.Equal(t, Added, b.Event)
+				require.Equal(t, next, b.NumberU64())
+				next++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+
+	// near the head, blocks are fetched one at a time
+	chain.Store(mockChain(nil, 0, 252))
+	for next <= 252 {
+		select {
+		case blocks := <-sub.Blocks():
+			require.Len(t, blocks, 1)
+			require.Equal(t, next, blocks[0].NumberU64())
+			next++
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+}
+
+func TestCatchUpUnlinkedRange(t *testing.T) {
+	bc := mockChain(nil, 0, 50)
+
+	var chain atomic.Value
+	chain.Store(bc)
+
+	opts := DefaultOptions
+	opts.CatchUpThreshold = 10
+	opts.MaxConcurrentFetches = 4
+
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+	monitor.nextBlockNumber = big.NewInt(1)
+
+	blocks, err := monitor.fetchCatchUpBlocks(context.Background())
+	require.NoError(t, err)
+	require.Len(t, blocks, 50)
+
+	// a range served from two forks is rejected
+	forked := append([]*types.Block{}, bc[:20]...)
+	forked = append(forked, mockChain(bc, 5, 50)[20:]...)
+	chain.Store(forked)
+
+	_, err = monitor.fetchCatchUpBlocks(context.Background())
+	require.ErrorIs(t, err, ErrUnexpectedParentHash)
+}
@@ -2,7 +2,9 @@ package ethmonitor
 
 import (
 	"fmt"
+	"math/big"
 	"sync"
+	"time"
 
 	"github.com/0xsequence/ethkit/go-ethereum/common"
 	"github.com/0xsequence/ethkit/go-ethereum/core/types"
@@ -15,31 +17,53 @@ type Chain struct {
 	// retentionLimit of total number of blocks in cache
 	retentionLimit int
 
+	// retentionSlack is the number of blocks the cache may grow beyond the
+	// retentionLimit before it is trimmed back down to the retentionLimit.
+	retentionSlack int
+
 	// bootstrapMode flag that chain is bootstrapped with blocks
 	// before starting the monitor.
 	bootstrapMode bool
 
+	// blockLinkFunc determines if a block is the child of the head block
+	blockLinkFunc BlockLinkFunc
+
+	// sampling is the distance between the block numbers of a sparse chain, see
+	// Options.SparseSampling, in which case blocks are not linked by parent hash.
+	sampling uint64
+
+	// reorgAncestor is the common ancestor of the last reorg, see LastReorgAncestor
+	reorgAncestor *Block
+
+	// evicted is the number of blocks trimmed from the chain by the retention limit
+	evicted uint64
+
 	mu               sync.Mutex
 	averageBlockTime float64 // in seconds
 }
 
-func newChain(retentionLimit int, bootstrapMode bool) *Chain {
+func newChain(retentionLimit int, retentionSlack int, bootstrapMode bool) *Chain {
 	// a minimum retention limit
 	retentionMin := 10
 	if retentionLimit < retentionMin {
 		retentionLimit = retentionMin
 	}
+	if retentionSlack < 0 {
+		retentionSlack = 0
+	}
 
 	// blocks of nil means the chain has not been initialized
 	var blocks Blocks = nil
 	if !bootstrapMode {
-		blocks = make(Blocks, 0, retentionLimit)
+		blocks = make(Blocks, 0, retentionLimit+retentionSlack+1)
 	}
 
 	return &Chain{
 		blocks:         blocks,
 		retentionLimit: retentionLimit,
+		retentionSlack: retentionSlack,
 		bootstrapMode:  bootstrapMode,
+		blockLinkFunc:  LinkByParentHash,
 	}
 }
 
@@ -61,34 +85,69 @@ func (c *Chain) push(nextBlock *Block) error {
 	if n > 0 {
 		headBlock := c.blocks[n-1]
 
-		// Assert pointing at prev block
-		if nextBlock.ParentHash() != headBlock.Hash() {
-			return ErrUnexpectedParentHash
-		}
+		if c.sampling > 1 {
+			// Assert block numbers are in sequence of the sparse chain
+			if nextBlock.NumberU64() != headBlock.NumberU64()+c.sampling {
+				return ErrUnexpectedBlockNumber
+			}
+		} else {
+			// Assert pointing at prev block
+			if !c.blockLinkFunc(headBlock.Block, nextBlock.Block) {
+				return ErrUnexpectedParentHash
+			}
 
-		// Assert block numbers are in sequence
-		if nextBlock.NumberU64() != headBlock.NumberU64()+1 {
-			return ErrUnexpectedBlockNumber
+			// Assert block numbers are in sequence
+			if nextBlock.NumberU64() != headBlock.NumberU64()+1 {
+				return ErrUnexpectedBlockNumber
+			}
 		}
 
-		// Update average block time
-		if c.averageBlockTime == 0 {
-			c.averageBlockTime = float64(nextBlock.Time() - headBlock.Time())
-		} else {
-			c.averageBlockTime = (c.averageBlockTime + float64(nextBlock.Time()-headBlock.Time())) / 2
-		}
+		c.averageBlockTime = nextAverageBlockTime(c.averageBlockTime, headBlock, nextBlock)
 	}
 
 	// Add to head of stack
 	c.blocks = append(c.blocks, nextBlock)
-	if len(c.blocks) > c.retentionLimit {
+	if c.retentionSlack == 0 && len(c.blocks) > c.retentionLimit {
 		c.blocks[0] = nil
 		c.blocks = c.blocks[1:]
+		c.evicted++
+	} else if len(c.blocks) > c.retentionLimit+c.retentionSlack {
+		c.trim()
 	}
 
 	return nil
 }
 
+// trim the chain back down to the retentionLimit. Blocks are moved to the
+// front of the existing backing array, so trimming does not allocate.
+func (c *Chain) trim() {
+	n := len(c.blocks)
+	if n <= c.retentionLimit {
+		return
+	}
+	copy(c.blocks, c.blocks[n-c.retentionLimit:])
+	for i := c.retentionLimit; i < n; i++ {
+		c.blocks[i] = nil
+	}
+	c.blocks = c.blocks[:c.retentionLimit]
+	c.evicted += uint64(n - c.retentionLimit)
+}
+
+// Len returns the number of retained blocks.
+func (c *Chain) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.blocks)
+}
+
+// EvictedCount returns the number of blocks evicted from the chain by the retention limit
+// since the monitor was created.
+func (c *Chain) EvictedCount() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.evicted
+}
+
 // Pop from the top of the stack
 func (c *Chain) pop() *Block {
 	c.mu.Lock()
@@ -98,9 +157,41 @@ func (c *Chain) pop() *Block {
 	block := c.blocks[n]
 	c.blocks[n] = nil
 	c.blocks = c.blocks[:n]
+	if block == c.reorgAncestor {
+		c.reorgAncestor = nil
+	}
 	return block
 }
 
+// LastReorgAncestor returns the last block common to both the old and the new canonical
+// chain of the last reorg, ie. the block to roll back to before applying the added blocks
+// of the reorg. It returns false if there was no reorg since the monitor started, or if the
+// ancestor has since been removed by another reorg whose ancestor is not retained.
+func (c *Chain) LastReorgAncestor() (*Block, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reorgAncestor, c.reorgAncestor != nil
+}
+
+// updateReorgAncestor tracks the common ancestor of the reorg described by the events, if
+// any, once they're applied to the chain.
+func (c *Chain) updateReorgAncestor(events Blocks) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.reorgAncestor != nil {
+		if _, ok := events.FindBlock(c.reorgAncestor.Hash(), Removed); ok {
+			c.reorgAncestor = nil
+		}
+	}
+
+	reorg, ok := reorgInfo(events)
+	if !ok {
+		return
+	}
+	c.reorgAncestor, _ = c.blocks.FindBlock(reorg.CommonAncestorHash, Added)
+}
+
 func (c *Chain) Head() *Block {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -144,6 +235,13 @@ func (c *Chain) GetBlockByNumber(blockNum uint64, event Event) *Block {
 // transactions can still be reorged, but you can check the blockNumber and compare it against
 // the head to determine if its final.
 func (c *Chain) GetTransaction(txnHash common.Hash) *types.Transaction {
+	txn, _, _ := c.GetTransactionWithBlock(txnHash)
+	return txn
+}
+
+// GetTransactionWithBlock is the same as GetTransaction, but also returns the block which
+// contains the transaction, and false if the transaction is not in the retained chain.
+func (c *Chain) GetTransactionWithBlock(txnHash common.Hash) (*types.Transaction, *Block, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -151,7 +249,26 @@ func (c *Chain) GetTransaction(txnHash common.Hash) *types.Transaction {
 	for i := len(c.blocks) - 1; i >= 0; i-- {
 		for _, txn := range c.blocks[i].Transactions() {
 			if txn.Hash() == txnHash {
-				return txn
+				return txn, c.blocks[i], true
+			}
+		}
+	}
+
+	return nil, nil, false
+}
+
+// GetReceipt searches our canonical chain of blocks for the receipt of the transaction, which
+// is only available for blocks fetched with Options.WithReceipts. Receipts of reorged blocks
+// are dropped along with the blocks, and receipts are kept for as long as their block is
+// retained.
+func (c *Chain) GetReceipt(txnHash common.Hash) *types.Receipt {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := len(c.blocks) - 1; i >= 0; i-- {
+		for _, receipt := range c.blocks[i].receipts {
+			if receipt.TxHash == txnHash {
+				return receipt
 			}
 		}
 	}
@@ -168,23 +285,76 @@ func (c *Chain) PrintAllBlocks() {
 	}
 }
 
+// maxBlockTimeDrift is how far ahead of the local clock a block's timestamp may be for
+// the block to count towards the average block time.
+const maxBlockTimeDrift = 15 * time.Minute
+
+// nextAverageBlockTime returns the average block time updated with the interval between
+// the parent and the block, or between two blocks of a sparse chain. Negative intervals
+// from out-of-order timestamps, which would otherwise underflow, and blocks timestamped in
+// the future, ie. by a misconfigured node, are ignored so they don't skew the average.
+func nextAverageBlockTime(averageBlockTime float64, parent, block *Block) float64 {
+	if block.Time() < parent.Time() || block.NumberU64() <= parent.NumberU64() {
+		return averageBlockTime
+	}
+	if block.Time() > uint64(time.Now().Add(maxBlockTimeDrift).Unix()) {
+		return averageBlockTime
+	}
+	blockTime := float64(block.Time()-parent.Time()) / float64(block.NumberU64()-parent.NumberU64())
+	if averageBlockTime == 0 {
+		return blockTime
+	}
+	return (averageBlockTime + blockTime) / 2
+}
+
 func (c *Chain) GetAverageBlockTime() float64 {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	return c.averageBlockTime
 }
 
+// GetAverageBlockTimeWindow returns the mean block time in seconds over the most recent n
+// blocks of the retained chain, which follows changes of the block rate more closely than
+// GetAverageBlockTime. When n covers all retained blocks, GetAverageBlockTime is returned.
+// Returns 0 when n is less than 2.
+func (c *Chain) GetAverageBlockTimeWindow(n int) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if n >= len(c.blocks) {
+		return c.averageBlockTime
+	}
+	if n < 2 {
+		return 0
+	}
+
+	first, last := c.blocks[len(c.blocks)-n], c.blocks[len(c.blocks)-1]
+	if last.Time() < first.Time() || last.NumberU64() <= first.NumberU64() {
+		return 0
+	}
+	return float64(last.Time()-first.Time()) / float64(last.NumberU64()-first.NumberU64())
+}
+
 type Event uint32
 
 const (
 	Added Event = iota
 	Removed
+
+	// Finalized is published for a block which was previously published as Added, once
+	// it reaches finality, see Options.PublishFinalized. Finalized events carry no logs.
+	Finalized
+
+	// Reset is published on its own for the previous head of the chain when the monitor
+	// is reset, see Monitor.Reset. The events which follow don't link to the blocks
+	// published before it.
+	Reset
 )
 
 type Block struct {
 	*types.Block
 
-	// Event type where Block is Added or Removed (ie. reorged)
+	// Event type where Block is Added, Removed (ie. reorged), Finalized or Reset
 	Event Event
 
 	// Logs in the block, grouped by transactions:
@@ -192,8 +362,42 @@ type Block struct {
 	// Logs [][]types.Log `json:"logs"`
 	Logs []types.Log
 
-	// OK flag which represents the block is ready for broadcasting
+	// TokenTransfers are the ERC20 transfers decoded from the block logs,
+	// only available when Options.DecodeTokenTransfers is enabled.
+	TokenTransfers []TokenTransfer
+
+	// OK flag which represents the block is ready for broadcasting, ie. its logs and
+	// receipts were fetched as per the options. Blocks which are not OK are held back in
+	// the publish queue until their data is backfilled, so published blocks are always OK.
 	OK bool
+
+	// receipts of the block transactions, see Receipts
+	receipts []*types.Receipt
+
+	// txnHashes of a block built by FromDTO without its transactions, see TxnHashes
+	txnHashes []common.Hash
+}
+
+// Receipts returns the receipts of the block transactions in order, only available
+// when Options.WithReceipts is enabled. Receipts are not fetched for removed blocks,
+// so they are only available if the block was added while the monitor was running.
+func (b *Block) Receipts() []*types.Receipt {
+	return b.receipts
+}
+
+// TxnHashes returns the hashes of the block transactions in order. A block built by
+// FromDTO from a dto without the full transactions has no Transactions, but it keeps
+// the transaction hashes of the dto, which are returned instead.
+func (b *Block) TxnHashes() []common.Hash {
+	txns := b.Transactions()
+	if len(txns) == 0 && len(b.txnHashes) > 0 {
+		return b.txnHashes
+	}
+	hashes := make([]common.Hash, 0, len(txns))
+	for _, txn := range txns {
+		hashes = append(hashes, txn.Hash())
+	}
+	return hashes
 }
 
 type Blocks []*Block
@@ -239,6 +443,61 @@ func (b Blocks) Reorg() bool {
 	return false
 }
 
+// Added returns the added blocks of the batch, in order.
+func (b Blocks) Added() Blocks {
+	return b.filterEvent(Added)
+}
+
+// Removed returns the removed blocks of the batch, in order, ie. from the old head down
+// to the common ancestor of a reorg.
+func (b Blocks) Removed() Blocks {
+	return b.filterEvent(Removed)
+}
+
+// Finalized returns the finalized blocks of the batch, in order, see Options.PublishFinalized.
+func (b Blocks) Finalized() Blocks {
+	return b.filterEvent(Finalized)
+}
+
+// EachLog calls fn with each log of the added and removed blocks of the batch, along with
+// its block, in block order and in log order within each block, so a log indexer can
+// apply the logs of added blocks and revert those of removed blocks in one pass.
+func (b Blocks) EachLog(fn func(block *Block, log types.Log)) {
+	for _, block := range b {
+		if block.Event != Added && block.Event != Removed {
+			continue
+		}
+		for _, log := range block.Logs {
+			fn(block, log)
+		}
+	}
+}
+
+// Logs returns a reference to each log of the added and removed blocks of the batch, in
+// the same order as EachLog.
+func (b Blocks) Logs() []*LogRef {
+	refs := []*LogRef{}
+	for _, block := range b {
+		if block.Event != Added && block.Event != Removed {
+			continue
+		}
+		for i := range block.Logs {
+			refs = append(refs, &LogRef{Block: block, Index: i})
+		}
+	}
+	return refs
+}
+
+func (b Blocks) filterEvent(event Event) Blocks {
+	filtered := Blocks{}
+	for _, block := range b {
+		if block.Event == event {
+			filtered = append(filtered, block)
+		}
+	}
+	return filtered
+}
+
 func (blocks Blocks) FindBlock(hash common.Hash, optEvent ...Event) (*Block, bool) {
 	for i := len(blocks) - 1; i >= 0; i-- {
 		if blocks[i].Hash() == hash {
@@ -263,19 +522,55 @@ func (blocks Blocks) EventExists(block *types.Block, event Event) bool {
 	return false
 }
 
+// FindBlockByHash returns the last block of the batch with the hash, or nil if none.
+func (blocks Blocks) FindBlockByHash(hash common.Hash) *Block {
+	block, _ := blocks.FindBlock(hash)
+	return block
+}
+
+// FindBlockByNumber returns the last block of the batch with the number, or nil if none.
+// After a reorg, the batch may have a removed and an added block with the same number,
+// in which case the added block comes last.
+func (blocks Blocks) FindBlockByNumber(num *big.Int) *Block {
+	if num == nil {
+		return nil
+	}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if blocks[i].Number().Cmp(num) == 0 {
+			return blocks[i]
+		}
+	}
+	return nil
+}
+
+// FindTransaction returns the transaction with the hash and its block, searching the
+// last blocks of the batch first, or nil if none of the blocks include it.
+func (blocks Blocks) FindTransaction(txnHash common.Hash) (*types.Transaction, *Block) {
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if txn := blocks[i].Transaction(txnHash); txn != nil {
+			return txn, blocks[i]
+		}
+	}
+	return nil, nil
+}
+
 func (blocks Blocks) Copy() Blocks {
 	nb := make(Blocks, len(blocks))
 
 	for i, b := range blocks {
 		var logs []types.Log
 		if b.Logs != nil {
+			logs = make([]types.Log, len(b.Logs))
 			copy(logs, b.Logs)
 		}
 		nb[i] = &Block{
-			Block: b.Block,
-			Event: b.Event,
-			Logs:  logs,
-			OK:    b.OK,
+			Block:          b.Block,
+			Event:          b.Event,
+			Logs:           logs,
+			TokenTransfers: b.TokenTransfers,
+			OK:             b.OK,
+			receipts:       b.receipts,
+			txnHashes:      b.txnHashes,
 		}
 	}
 
@@ -0,0 +1,381 @@
+package ethmonitor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainRetentionSlack(t *testing.T) {
+	chain := newChain(10, 5, false)
+
+	bc := mockBlockchain(31)
+	for i, b := range bc {
+		require.NoError(t, chain.push(&Block{Block: b, Event: Added}))
+
+		// the chain grows up to limit+slack, and is then trimmed back to the limit
+		n := len(chain.blocks)
+		require.LessOrEqual(t, n, 15)
+		require.Equal(t, uint64(i+1), chain.Head().NumberU64())
+		require.Equal(t, chain.Head().NumberU64()-uint64(n-1), chain.Tail().NumberU64())
+	}
+
+	// trimmed back to 10 blocks at blocks 16, 22 and 28, then grew to 13 blocks
+	require.Len(t, chain.blocks, 13)
+	require.Equal(t, uint64(19), chain.Tail().NumberU64())
+	require.Equal(t, 16, cap(chain.blocks)) // no reallocation
+}
+
+func TestChainRetentionNoSlack(t *testing.T) {
+	chain := newChain(10, 0, false)
+
+	for _, b := range mockBlockchain(25) {
+		require.NoError(t, chain.push(&Block{Block: b, Event: Added}))
+	}
+	require.Len(t, chain.blocks, 10)
+	require.Equal(t, uint64(16), chain.Tail().NumberU64())
+}
+
+func TestChainAverageBlockTimeWindow(t *testing.T) {
+	chain := newChain(10, 0, false)
+
+	// block times of 2s, then slowing down to 14s and 20s
+	var parent *types.Block
+	for i, ts := range []uint64{100, 102, 104, 106, 120, 140} {
+		block := mockBlockWithTime(parent, i+1, ts)
+		require.NoError(t, chain.push(&Block{Block: block, Event: Added}))
+		parent = block
+	}
+
+	require.Equal(t, 20.0, chain.GetAverageBlockTimeWindow(2))
+	require.Equal(t, 17.0, chain.GetAverageBlockTimeWindow(3))
+	require.Equal(t, 12.0, chain.GetAverageBlockTimeWindow(4))
+	require.Equal(t, 0.0, chain.GetAverageBlockTimeWindow(1))
+
+	// the whole chain falls back to the overall average
+	require.Equal(t, chain.GetAverageBlockTime(), chain.GetAverageBlockTimeWindow(6))
+	require.Equal(t, chain.GetAverageBlockTime(), chain.GetAverageBlockTimeWindow(100))
+	require.NotEqual(t, 0.0, chain.GetAverageBlockTime())
+}
+
+func TestChainBlockLinkFunc(t *testing.T) {
+	// link blocks by number only, as if the chain hashed blocks differently
+	opts := DefaultOptions
+	opts.BlockLinkFunc = func(parent, child *types.Block) bool {
+		return child.NumberU64() == parent.NumberU64()+1
+	}
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	var events Blocks
+	for i := 1; i <= 3; i++ {
+		events, err = monitor.buildCanonicalChain(context.Background(), mockBlock("0x1234", i), events)
+		require.NoError(t, err)
+	}
+	require.Len(t, events, 3)
+	require.Equal(t, uint64(3), monitor.chain.Head().NumberU64())
+
+	// the default links by parent hash
+	chain := newChain(10, 0, false)
+	require.NoError(t, chain.push(&Block{Block: mockBlock("0x1234", 1), Event: Added}))
+	require.ErrorIs(t, chain.push(&Block{Block: mockBlock("0x1234", 2), Event: Added}), ErrUnexpectedParentHash)
+}
+
+func TestGetBlockByNumber(t *testing.T) {
+	opts := DefaultOptions
+	opts.BlockRetentionLimit = 10
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	bc := mockBlockchain(15)
+	for _, b := range bc {
+		require.NoError(t, monitor.chain.push(&Block{Block: b, Event: Added}))
+	}
+
+	require.Equal(t, bc[14].Hash(), monitor.GetBlockByNumber(big.NewInt(15)).Hash())
+	require.Equal(t, bc[5].Hash(), monitor.GetBlockByNumber(big.NewInt(6)).Hash())
+	require.Nil(t, monitor.GetBlockByNumber(big.NewInt(5)))
+	require.Nil(t, monitor.GetBlockByNumber(big.NewInt(16)))
+	require.Nil(t, monitor.GetBlockByNumber(big.NewInt(-1)))
+	require.Nil(t, monitor.GetBlockByNumber(nil))
+
+	// reorged blocks are replaced by the canonical block at the same height
+	monitor.chain.pop()
+	fork := types.NewBlockWithHeader(&types.Header{ParentHash: bc[13].Hash(), Number: big.NewInt(15), Time: 1})
+	require.NoError(t, monitor.chain.push(&Block{Block: fork, Event: Added}))
+	require.Equal(t, fork.Hash(), monitor.GetBlockByNumber(big.NewInt(15)).Hash())
+}
+
+func TestMonitorEffectiveOptions(t *testing.T) {
+	opts := DefaultOptions
+	opts.BlockRetentionLimit = 100
+	opts.TrailNumBlocksBehindHead = 10
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	require.Equal(t, 110, monitor.Options().BlockRetentionLimit)
+	require.Equal(t, 10, monitor.Options().TrailNumBlocksBehindHead)
+	require.Equal(t, 220, monitor.PublishQueueCapacity())
+	require.Equal(t, 220, monitor.Options().PublishQueueSize)
+}
+
+func TestMonitorOptionsValidation(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(opts *Options)
+		err    string
+	}{
+		{"zero PollingInterval", func(opts *Options) { opts.PollingInterval = 0 }, "PollingInterval (0s) must be greater than 0"},
+		{"negative PollingInterval", func(opts *Options) { opts.PollingInterval = -time.Second }, "PollingInterval (-1s) must be greater than 0"},
+		{"zero Timeout", func(opts *Options) { opts.Timeout = 0 }, "Timeout (0s) must be greater than 0"},
+		{"negative ReorgRecoveryPause", func(opts *Options) { opts.ReorgRecoveryPause = -time.Second }, "ReorgRecoveryPause (-1s) must not be negative"},
+		{"zero BlockRetentionLimit", func(opts *Options) { opts.BlockRetentionLimit = 0 }, "BlockRetentionLimit (0) must be greater than 0"},
+		{"negative BlockRetentionLimit", func(opts *Options) { opts.BlockRetentionLimit = -1 }, "BlockRetentionLimit (-1) must be greater than 0"},
+		{"negative TrailNumBlocksBehindHead", func(opts *Options) { opts.TrailNumBlocksBehindHead = -1 }, "TrailNumBlocksBehindHead (-1) must not be negative"},
+		{"TrailNumBlocksBehindHead beyond retention", func(opts *Options) {
+			opts.BlockRetentionLimit = 20
+			opts.TrailNumBlocksBehindHead = 20
+		}, "TrailNumBlocksBehindHead (20) must be < BlockRetentionLimit (20)"},
+		{"PublishQueueSize within TrailNumBlocksBehindHead", func(opts *Options) {
+			opts.TrailNumBlocksBehindHead = 10
+			opts.PublishQueueSize = 10
+		}, "PublishQueueSize (10) must be > TrailNumBlocksBehindHead (10), as the trailed blocks are held in the publish queue"},
+		{"UseBlockReceipts without WithLogs", func(opts *Options) { opts.UseBlockReceipts = true }, "UseBlockReceipts requires WithLogs"},
+		{"DecodeTokenTransfers without WithLogs", func(opts *Options) { opts.DecodeTokenTransfers = true }, "DecodeTokenTransfers requires WithLogs"},
+		{"IndexLogsByAddress without WithLogs", func(opts *Options) { opts.IndexLogsByAddress = true }, "IndexLogsByAddress requires WithLogs"},
+		{"WithReceipts with HeadersOnly", func(opts *Options) {
+			opts.WithReceipts = true
+			opts.HeadersOnly = true
+		}, "only one of WithReceipts and HeadersOnly may be set"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := DefaultOptions
+			c.modify(&opts)
+
+			_, err := NewMonitor(nil, opts)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), c.err)
+		})
+	}
+}
+
+func TestMonitorPublishQueueSize(t *testing.T) {
+	opts := DefaultOptions
+	opts.PublishQueueSize = 3
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+	require.Equal(t, 3, monitor.PublishQueueCapacity())
+
+	bc := mockBlockchain(4)
+	require.NoError(t, monitor.publishQueue.enqueue(Blocks{{Block: bc[0], Event: Added}, {Block: bc[1], Event: Added}}))
+	require.Equal(t, 2, monitor.PublishQueueLen())
+
+	// the queue is full beyond the configured size
+	err = monitor.publishQueue.enqueue(Blocks{{Block: bc[2], Event: Added}, {Block: bc[3], Event: Added}})
+	require.ErrorIs(t, err, ErrQueueFull)
+
+	opts.PublishQueueSize = -1
+	_, err = NewMonitor(nil, opts)
+	require.Error(t, err)
+}
+
+func TestMonitorGetTransactionWithBlock(t *testing.T) {
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+
+	txn := types.NewTransaction(1, common.HexToAddress("0x01"), big.NewInt(1), 21000, big.NewInt(1), nil)
+
+	bc := mockBlockchain(3)
+	bc[1] = types.NewBlockWithHeader(bc[1].Header()).WithBody([]*types.Transaction{txn}, nil)
+	for _, b := range bc {
+		require.NoError(t, monitor.chain.push(&Block{Block: b, Event: Added}))
+	}
+
+	found, block, ok := monitor.GetTransactionWithBlock(txn.Hash())
+	require.True(t, ok)
+	require.Equal(t, txn.Hash(), found.Hash())
+	require.Equal(t, uint64(2), block.NumberU64())
+	require.Equal(t, bc[1].Hash(), block.Hash())
+	require.Equal(t, txn.Hash(), monitor.GetTransaction(txn.Hash()).Hash())
+
+	found, block, ok = monitor.GetTransactionWithBlock(common.HexToHash("0x01"))
+	require.False(t, ok)
+	require.Nil(t, found)
+	require.Nil(t, block)
+	require.Nil(t, monitor.GetTransaction(common.HexToHash("0x01")))
+}
+
+func TestChainLastReorgAncestor(t *testing.T) {
+	chain := newChain(10, 0, false)
+
+	bc := mockBlockchain(5)
+	for _, b := range bc {
+		require.NoError(t, chain.push(&Block{Event: Added, Block: b, OK: true}))
+	}
+	_, ok := chain.LastReorgAncestor()
+	require.False(t, ok)
+
+	// rebuilds the chain from the block at forkAt, as buildCanonicalChain does
+	reorg := func(forkAt int, size int) {
+		events := Blocks{}
+		for chain.Head().NumberU64() > uint64(forkAt) {
+			popped := *chain.pop()
+			popped.Event = Removed
+			events = append(events, &popped)
+		}
+		parent := chain.Head().Hash()
+		for i := forkAt + 1; i <= size; i++ {
+			header := &types.Header{ParentHash: parent, Number: big.NewInt(int64(i)), Time: uint64(forkAt)}
+			b := &Block{Event: Added, Block: types.NewBlockWithHeader(header), OK: true}
+			require.NoError(t, chain.push(b))
+			events = append(events, b)
+			parent = b.Hash()
+		}
+		chain.updateReorgAncestor(events)
+	}
+
+	reorg(3, 6)
+	ancestor, ok := chain.LastReorgAncestor()
+	require.True(t, ok)
+	require.Equal(t, bc[2].Hash(), ancestor.Hash())
+
+	// new blocks don't change the last reorg ancestor
+	chain.updateReorgAncestor(Blocks{chain.Head()})
+	ancestor, ok = chain.LastReorgAncestor()
+	require.True(t, ok)
+	require.Equal(t, bc[2].Hash(), ancestor.Hash())
+
+	// a deeper reorg removing the ancestor
+	reorg(1, 7)
+	ancestor, ok = chain.LastReorgAncestor()
+	require.True(t, ok)
+	require.Equal(t, bc[0].Hash(), ancestor.Hash())
+
+	// the ancestor is cleared when it's removed from the chain
+	for len(chain.Blocks()) > 0 {
+		chain.pop()
+	}
+	_, ok = chain.LastReorgAncestor()
+	require.False(t, ok)
+}
+
+func TestBlocksFind(t *testing.T) {
+	bc := mockBlockchain(3)
+	txn := types.NewTransaction(1, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	fork3 := types.NewBlockWithHeader(&types.Header{ParentHash: bc[1].Hash(), Number: big.NewInt(3), Time: 1}).WithBody([]*types.Transaction{txn}, nil)
+
+	blocks := Blocks{
+		{Event: Added, Block: bc[0]},
+		{Event: Added, Block: bc[1]},
+		{Event: Removed, Block: bc[2]},
+		{Event: Added, Block: fork3},
+	}
+
+	require.Equal(t, bc[1].Hash(), blocks.FindBlockByHash(bc[1].Hash()).Hash())
+	require.Equal(t, Removed, blocks.FindBlockByHash(bc[2].Hash()).Event)
+	require.Nil(t, blocks.FindBlockByHash(common.HexToHash("0x01")))
+
+	// the added block of a reorg comes last
+	require.Equal(t, fork3.Hash(), blocks.FindBlockByNumber(big.NewInt(3)).Hash())
+	require.Equal(t, bc[0].Hash(), blocks.FindBlockByNumber(big.NewInt(1)).Hash())
+	require.Nil(t, blocks.FindBlockByNumber(big.NewInt(4)))
+	require.Nil(t, blocks.FindBlockByNumber(nil))
+
+	found, block := blocks.FindTransaction(txn.Hash())
+	require.Equal(t, txn.Hash(), found.Hash())
+	require.Equal(t, fork3.Hash(), block.Hash())
+
+	found, block = blocks.FindTransaction(common.HexToHash("0x01"))
+	require.Nil(t, found)
+	require.Nil(t, block)
+}
+
+func TestBlocksFilterEvent(t *testing.T) {
+	bc := mockBlockchain(4)
+	fork3 := types.NewBlockWithHeader(&types.Header{ParentHash: bc[1].Hash(), Number: big.NewInt(3), Time: 1})
+
+	blocks := Blocks{
+		{Event: Removed, Block: bc[3]},
+		{Event: Removed, Block: bc[2]},
+		{Event: Added, Block: fork3},
+		{Event: Finalized, Block: bc[0]},
+	}
+
+	require.Equal(t, Blocks{blocks[2]}, blocks.Added())
+	require.Equal(t, Blocks{blocks[0], blocks[1]}, blocks.Removed())
+	require.Equal(t, Blocks{blocks[3]}, blocks.Finalized())
+	require.Empty(t, blocks[2:3].Removed())
+}
+
+func TestBlocksEachLog(t *testing.T) {
+	bc := mockBlockchain(3)
+	fork3 := types.NewBlockWithHeader(&types.Header{ParentHash: bc[1].Hash(), Number: big.NewInt(3), Time: 1})
+
+	logs := func(b *types.Block, n int) []types.Log {
+		logs := []types.Log{}
+		for i := 0; i < n; i++ {
+			logs = append(logs, types.Log{BlockHash: b.Hash(), Index: uint(i)})
+		}
+		return logs
+	}
+	blocks := Blocks{
+		{Event: Removed, Block: bc[2], Logs: logs(bc[2], 2)},
+		{Event: Added, Block: fork3, Logs: logs(fork3, 3)},
+		{Event: Finalized, Block: bc[0]},
+	}
+
+	type entry struct {
+		event Event
+		hash  common.Hash
+		index uint
+	}
+	expected := []entry{
+		{Removed, bc[2].Hash(), 0}, {Removed, bc[2].Hash(), 1},
+		{Added, fork3.Hash(), 0}, {Added, fork3.Hash(), 1}, {Added, fork3.Hash(), 2},
+	}
+
+	entries := []entry{}
+	blocks.EachLog(func(block *Block, log types.Log) {
+		require.Equal(t, block.Hash(), log.BlockHash)
+		entries = append(entries, entry{block.Event, block.Hash(), log.Index})
+	})
+	require.Equal(t, expected, entries)
+
+	entries = []entry{}
+	for _, ref := range blocks.Logs() {
+		entries = append(entries, entry{ref.Block.Event, ref.Block.Hash(), ref.Log().Index})
+	}
+	require.Equal(t, expected, entries)
+}
+
+func TestMonitorRetainedBlockRange(t *testing.T) {
+	opts := DefaultOptions
+	opts.BlockRetentionLimit = 10
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	oldest, newest := monitor.RetainedBlockRange()
+	require.Nil(t, oldest)
+	require.Nil(t, newest)
+
+	for _, b := range mockBlockchain(14) {
+		require.NoError(t, monitor.chain.push(&Block{Event: Added, Block: b, OK: true}))
+	}
+
+	oldest, newest = monitor.RetainedBlockRange()
+	require.Equal(t, uint64(5), oldest.Uint64())
+	require.Equal(t, uint64(14), newest.Uint64())
+	require.Equal(t, 10, monitor.Chain().Len())
+	require.Equal(t, uint64(4), monitor.EvictedBlockCount())
+}
@@ -0,0 +1,61 @@
+package ethmonitor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeChainIDProvider struct {
+	fakeProvider
+	chainID *big.Int
+}
+
+func (p *fakeChainIDProvider) ChainID(ctx context.Context) (*big.Int, error) {
+	return p.chainID, nil
+}
+
+func TestExpectedChainID(t *testing.T) {
+	provider := &fakeChainIDProvider{chainID: big.NewInt(137)}
+	provider.bc.Store(mockChain(nil, 0, 3))
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.ExpectedChainID = big.NewInt(1)
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+
+	err = monitor.Run(context.Background())
+	require.True(t, errors.Is(err, ErrChainIDMismatch))
+	require.Contains(t, err.Error(), "provider chain id is 137, expected 1")
+	require.Nil(t, monitor.LatestBlock())
+
+	// the monitor runs on the expected network
+	opts.ExpectedChainID = big.NewInt(137)
+	monitor, err = NewMonitor(provider, opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	select {
+	case blocks := <-sub.Blocks():
+		require.Equal(t, uint64(1), blocks[0].NumberU64())
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for events")
+	}
+
+	// providers without ChainID can't check the chain id
+	_, err = NewMonitor(&fakeProvider{}, opts)
+	require.ErrorContains(t, err, "ExpectedChainID requires ChainID")
+}
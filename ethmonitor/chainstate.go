@@ -0,0 +1,104 @@
+package ethmonitor
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrChainStateUnavailable = errors.New("ethmonitor: chain state is unavailable")
+
+// reorgRecord is a reorg kept in the monitor's reorg history, see ChainStateAt.
+type reorgRecord struct {
+	ancestorNum uint64
+	newHeadNum  uint64
+	removed     Blocks // in ascending order
+}
+
+// recordReorg adds the reorg described by the events, if any, to the reorg history.
+func (m *Monitor) recordReorg(events Blocks) {
+	if m.options.ReorgHistoryLimit <= 0 {
+		return
+	}
+
+	record := reorgRecord{}
+	for _, block := range events {
+		switch block.Event {
+		case Removed:
+			// removed blocks are in descending order
+			record.removed = append(Blocks{block}, record.removed...)
+		case Added:
+			record.newHeadNum = block.NumberU64()
+		}
+	}
+	if len(record.removed) == 0 {
+		return
+	}
+	record.ancestorNum = record.removed[0].NumberU64() - 1
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reorgHistory = append(m.reorgHistory, record)
+	if len(m.reorgHistory) > m.options.ReorgHistoryLimit {
+		// states before the head of the trimmed reorg can't be reconstructed anymore
+		m.reorgHistoryFloor = m.reorgHistory[0].newHeadNum
+		m.reorgHistory[0] = reorgRecord{}
+		m.reorgHistory = m.reorgHistory[1:]
+	}
+}
+
+// ChainStateAt returns the canonical chain as it was when the block number was the head
+// of the chain, up to and including the head. If the block number was the head more than
+// once, ie. on both sides of a reorg, the most recent state is returned.
+//
+// The state is reconstructed from the retained canonical chain by undoing the reorgs in
+// the reorg history, so ReorgHistoryLimit must be set. The state is only available for
+// block numbers within BlockRetentionLimit of the current head, and after any reorg which
+// was trimmed from the history, otherwise ErrChainStateUnavailable is returned. The blocks
+// below the block number may be fewer than BlockRetentionLimit, as blocks which were
+// trimmed from the chain are not kept.
+func (m *Monitor) ChainStateAt(headNumber uint64) (Blocks, error) {
+	if m.options.ReorgHistoryLimit <= 0 {
+		return nil, fmt.Errorf("%w: ReorgHistoryLimit is not set", ErrChainStateUnavailable)
+	}
+
+	m.mu.RLock()
+	history := make([]reorgRecord, len(m.reorgHistory))
+	copy(history, m.reorgHistory)
+	floor := m.reorgHistoryFloor
+	m.mu.RUnlock()
+
+	state := m.chain.Blocks()
+
+	for i := len(history) - 1; i >= 0; i-- {
+		reorg := history[i]
+
+		// the block number was the head after this reorg
+		if headNumber >= reorg.newHeadNum {
+			return chainStateAt(state, headNumber)
+		}
+
+		// undo the reorg, restoring the removed blocks on top of the common ancestor
+		n := 0
+		for n < len(state) && state[n].NumberU64() <= reorg.ancestorNum {
+			n++
+		}
+		state = append(state[:n:n], reorg.removed...)
+	}
+
+	if headNumber < floor {
+		return nil, fmt.Errorf("%w: block %d is before a reorg trimmed from the history", ErrChainStateUnavailable, headNumber)
+	}
+	return chainStateAt(state, headNumber)
+}
+
+func chainStateAt(state Blocks, headNumber uint64) (Blocks, error) {
+	if len(state) == 0 || headNumber < state.Tail().NumberU64() || headNumber > state.Head().NumberU64() {
+		return nil, fmt.Errorf("%w: block %d is not retained", ErrChainStateUnavailable, headNumber)
+	}
+	n := len(state) - int(state.Head().NumberU64()-headNumber)
+	blocks := state[:n].Copy()
+	for _, b := range blocks {
+		b.Event = Added // restored blocks were Removed
+	}
+	return blocks, nil
+}
@@ -0,0 +1,96 @@
+package ethmonitor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainStateAt(t *testing.T) {
+	newBlock := func(parent *types.Block, fork uint64) *types.Block {
+		header := &types.Header{Number: big.NewInt(1), Time: fork}
+		if parent != nil {
+			header.ParentHash = parent.Hash()
+			header.Number = big.NewInt(0).Add(parent.Number(), big.NewInt(1))
+		}
+		return types.NewBlockWithHeader(header)
+	}
+	extend := func(parent *types.Block, n int, fork uint64) []*types.Block {
+		blocks := []*types.Block{}
+		for i := 0; i < n; i++ {
+			parent = newBlock(parent, fork)
+			blocks = append(blocks, parent)
+		}
+		return blocks
+	}
+	added := func(blocks ...*types.Block) Blocks {
+		events := Blocks{}
+		for _, b := range blocks {
+			events = append(events, &Block{Event: Added, Block: b, OK: true})
+		}
+		return events
+	}
+	removed := func(blocks ...*types.Block) Blocks {
+		events := Blocks{}
+		for i := len(blocks) - 1; i >= 0; i-- {
+			events = append(events, &Block{Event: Removed, Block: blocks[i], OK: true})
+		}
+		return events
+	}
+
+	// fork a: 1..10, fork b: 8..12 from block 7, fork c: 11..13 from block b10
+	a := append([]*types.Block{newBlock(nil, 0)}, extend(newBlock(nil, 0), 9, 0)...)
+	b := extend(a[6], 5, 1)
+	c := extend(b[2], 3, 2)
+
+	for _, limit := range []int{10, 1} {
+		opts := DefaultOptions
+		opts.ReorgHistoryLimit = limit
+
+		monitor, err := NewMonitor(nil, opts)
+		require.NoError(t, err)
+
+		require.NoError(t, monitor.IngestExternalBlocks(added(a...)))
+		require.NoError(t, monitor.IngestExternalBlocks(append(removed(a[7:]...), added(b[:2]...)...)))
+		require.NoError(t, monitor.IngestExternalBlocks(added(b[2:]...)))
+		require.NoError(t, monitor.IngestExternalBlocks(append(removed(b[3:]...), added(c...)...)))
+
+		expectHead := func(headNumber uint64, head *types.Block) {
+			blocks, err := monitor.ChainStateAt(headNumber)
+			require.NoError(t, err)
+			require.Equal(t, head.Hash(), blocks.Head().Hash())
+			require.Equal(t, uint64(1), blocks.Tail().NumberU64())
+			require.Len(t, blocks, int(headNumber))
+			for _, block := range blocks {
+				require.Equal(t, Added, block.Event)
+			}
+		}
+
+		expectHead(13, c[2])
+		expectHead(12, b[4])
+		expectHead(10, b[2])
+
+		if limit == 1 {
+			// the first reorg is no longer in the history
+			_, err = monitor.ChainStateAt(8)
+			require.ErrorIs(t, err, ErrChainStateUnavailable)
+			continue
+		}
+
+		expectHead(9, b[1])
+
+		expectHead(8, a[7])
+		expectHead(2, a[1])
+
+		_, err = monitor.ChainStateAt(14)
+		require.ErrorIs(t, err, ErrChainStateUnavailable)
+	}
+
+	// history is disabled by default
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+	_, err = monitor.ChainStateAt(1)
+	require.ErrorIs(t, err, ErrChainStateUnavailable)
+}
@@ -0,0 +1,70 @@
+package ethmonitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/goware/superr"
+)
+
+var ErrChainDivergence = errors.New("ethmonitor: chain diverged from the cross-check provider")
+
+// DivergencePolicy is how the monitor reacts when its chain diverges from the
+// CrossCheckProvider.
+type DivergencePolicy int
+
+const (
+	// DivergenceHalt will stop the monitor with ErrFatal wrapping ErrChainDivergence,
+	// without publishing the events of the diverged block. This is the default behaviour.
+	DivergenceHalt DivergencePolicy = iota
+
+	// DivergencePause will pause the monitor, see Monitor.Pause, and hold back the events
+	// which were not published yet. Once resumed, the held events are published along
+	// with the next new block, as long as the providers agree again.
+	DivergencePause
+)
+
+// crossCheck compares the hash of the buried block CrossCheckDepth blocks behind the head
+// with the block at the same height on the CrossCheckProvider, and returns an error wrapping
+// ErrChainDivergence if they differ. Each height is checked once, and a failure to reach the
+// cross-check provider is not considered a divergence, the check is retried on the next head.
+func (m *Monitor) crossCheck(ctx context.Context) error {
+	if m.options.CrossCheckProvider == nil {
+		return nil
+	}
+
+	head := m.chain.Head()
+	depth := uint64(m.options.CrossCheckDepth)
+	if head == nil || head.NumberU64() < depth {
+		return nil
+	}
+	blockNum := head.NumberU64() - depth
+	if blockNum <= m.crossCheckedBlockNum {
+		return nil
+	}
+	block := m.chain.GetBlockByNumber(blockNum, Added)
+	if block == nil {
+		return nil
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, m.options.Timeout)
+	defer cancel()
+
+	other, err := m.options.CrossCheckProvider.BlockByNumber(tctx, big.NewInt(0).SetUint64(blockNum))
+	if err != nil {
+		m.log.Warnf("ethmonitor: failed to fetch block #%d from the cross-check provider: %v", blockNum, err)
+		return nil
+	}
+
+	if other.Hash() != block.Hash() {
+		err := fmt.Errorf("block #%d hash:%s parentHash:%s, cross-check provider has hash:%s parentHash:%s (head #%d hash:%s)",
+			blockNum, block.Hash().Hex(), block.ParentHash().Hex(), other.Hash().Hex(), other.ParentHash().Hex(), head.NumberU64(), head.Hash().Hex())
+		m.log.Errorf("ethmonitor: chain diverged from the cross-check provider at %v", err)
+		return superr.New(ErrChainDivergence, err)
+	}
+
+	m.crossCheckedBlockNum = blockNum
+	return nil
+}
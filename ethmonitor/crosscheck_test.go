@@ -0,0 +1,116 @@
+package ethmonitor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrossCheckHalt(t *testing.T) {
+	primary := mockChain(nil, 0, 10)
+
+	var primaryChain, crossCheckChain atomic.Value
+	primaryChain.Store(primary)
+	crossCheckChain.Store(mockChain(primary, 3, 10))
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.CrossCheckProvider = mockNode(t, &crossCheckChain)
+	opts.CrossCheckDepth = 2
+
+	monitor, err := NewMonitor(mockNode(t, &primaryChain), opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	err = monitor.Run(context.Background())
+	require.True(t, errors.Is(err, ErrFatal))
+	require.True(t, errors.Is(err, ErrChainDivergence))
+	require.Contains(t, err.Error(), "block #4")
+
+	// block #6 buried block #4, so it was never published
+	require.Equal(t, uint64(6), monitor.LatestBlock().NumberU64())
+
+	var next uint64 = 1
+	for next <= 5 {
+		select {
+		case blocks := <-sub.Blocks():
+			for _, b := range blocks {
+				require.Equal(t, next, b.NumberU64())
+				next++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+	select {
+	case blocks := <-sub.Blocks():
+		t.Fatalf("unexpected events for block #%d", blocks.LatestBlock().NumberU64())
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestCrossCheckPause(t *testing.T) {
+	primary := mockChain(nil, 0, 10)
+
+	var primaryChain, crossCheckChain atomic.Value
+	primaryChain.Store(primary)
+	crossCheckChain.Store(mockChain(primary, 3, 10))
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.CrossCheckProvider = mockNode(t, &crossCheckChain)
+	opts.CrossCheckDepth = 2
+	opts.DivergencePolicy = DivergencePause
+
+	monitor, err := NewMonitor(mockNode(t, &primaryChain), opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	require.Eventually(t, monitor.IsPaused, 5*time.Second, 5*time.Millisecond)
+	require.True(t, monitor.IsRunning())
+	require.Equal(t, uint64(6), monitor.LatestBlock().NumberU64())
+
+	// once the providers agree again, the held back events are published
+	crossCheckChain.Store(primary)
+	monitor.Resume()
+
+	var next uint64 = 1
+	for next <= 10 {
+		select {
+		case blocks := <-sub.Blocks():
+			for _, b := range blocks {
+				require.Equal(t, next, b.NumberU64())
+				next++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+	require.False(t, monitor.IsPaused())
+}
+
+func TestCrossCheckDepthRequired(t *testing.T) {
+	var chain atomic.Value
+	chain.Store(mockChain(nil, 0, 1))
+
+	opts := DefaultOptions
+	opts.CrossCheckProvider = mockNode(t, &chain)
+
+	_, err := NewMonitor(nil, opts)
+	require.Error(t, err)
+}
@@ -0,0 +1,112 @@
+package ethmonitor
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+)
+
+// BlockDTO is a flat and serializable representation of a Block event, which is
+// convenient for shipping monitor output across a process boundary, ie. over a
+// message bus. Transactions are always included by hash, and optionally in full
+// as their binary (EIP-2718) encoding.
+type BlockDTO struct {
+	Event      Event          `json:"event"`
+	Number     uint64         `json:"number"`
+	Hash       common.Hash    `json:"hash"`
+	ParentHash common.Hash    `json:"parentHash"`
+	Timestamp  uint64         `json:"timestamp"`
+	Coinbase   common.Address `json:"coinbase"`
+	GasLimit   uint64         `json:"gasLimit"`
+	GasUsed    uint64         `json:"gasUsed"`
+	BaseFee    *big.Int       `json:"baseFee,omitempty"`
+	LogsBloom  types.Bloom    `json:"logsBloom"`
+
+	TxnHashes    []common.Hash   `json:"txnHashes"`
+	Transactions []hexutil.Bytes `json:"transactions,omitempty"`
+
+	Logs     []types.Log      `json:"logs"`
+	Receipts []*types.Receipt `json:"receipts,omitempty"`
+	OK       bool             `json:"ok"`
+}
+
+// ToDTO returns the flat representation of the block. Passing `optFullTxns true`
+// will also include the full encoded transactions, otherwise only the transaction
+// hashes are included.
+func (b *Block) ToDTO(optFullTxns ...bool) (*BlockDTO, error) {
+	fullTxns := len(optFullTxns) > 0 && optFullTxns[0]
+
+	dto := &BlockDTO{
+		Event:      b.Event,
+		Number:     b.NumberU64(),
+		Hash:       b.Hash(),
+		ParentHash: b.ParentHash(),
+		Timestamp:  b.Time(),
+		Coinbase:   b.Coinbase(),
+		GasLimit:   b.GasLimit(),
+		GasUsed:    b.GasUsed(),
+		BaseFee:    b.BaseFee(),
+		LogsBloom:  b.Bloom(),
+		TxnHashes:  b.TxnHashes(),
+		Logs:       b.Logs,
+		Receipts:   b.receipts,
+		OK:         b.OK,
+	}
+
+	if fullTxns {
+		for _, txn := range b.Transactions() {
+			data, err := txn.MarshalBinary()
+			if err != nil {
+				return nil, fmt.Errorf("ethmonitor: failed to encode txn %s: %w", txn.Hash().Hex(), err)
+			}
+			dto.Transactions = append(dto.Transactions, data)
+		}
+	}
+
+	return dto, nil
+}
+
+// FromDTO builds a Block from its flat representation. The block hash is taken as-is
+// from the dto, as the header is only partially represented. Transactions are only
+// available on the returned block if the dto was created with full transactions,
+// otherwise the transaction hashes of the dto are kept, see Block.TxnHashes.
+func FromDTO(dto *BlockDTO) (*Block, error) {
+	header := &types.Header{
+		ParentHash: dto.ParentHash,
+		Coinbase:   dto.Coinbase,
+		Bloom:      dto.LogsBloom,
+		Number:     big.NewInt(0).SetUint64(dto.Number),
+		GasLimit:   dto.GasLimit,
+		GasUsed:    dto.GasUsed,
+		Time:       dto.Timestamp,
+		BaseFee:    dto.BaseFee,
+	}
+
+	txns := make([]*types.Transaction, 0, len(dto.Transactions))
+	for i, data := range dto.Transactions {
+		txn := &types.Transaction{}
+		err := txn.UnmarshalBinary(data)
+		if err != nil {
+			return nil, fmt.Errorf("ethmonitor: failed to decode txn at index %d: %w", i, err)
+		}
+		txns = append(txns, txn)
+	}
+
+	block := types.NewBlockWithHeader(header).WithBody(txns, nil)
+	block.SetHash(dto.Hash)
+
+	b := &Block{
+		Block:    block,
+		Event:    dto.Event,
+		Logs:     dto.Logs,
+		OK:       dto.OK,
+		receipts: dto.Receipts,
+	}
+	if len(txns) == 0 {
+		b.txnHashes = dto.TxnHashes
+	}
+	return b, nil
+}
@@ -0,0 +1,79 @@
+package ethmonitor
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockDTO(t *testing.T) {
+	to := common.HexToAddress("0x3535353535353535353535353535353535353535")
+	txn := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     1,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(2),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(3),
+	})
+
+	header := &types.Header{
+		ParentHash: common.HexToHash("0x01"),
+		Number:     big.NewInt(10),
+		Time:       1650000000,
+		GasLimit:   30000000,
+		GasUsed:    21000,
+		BaseFee:    big.NewInt(7),
+	}
+	block := &Block{
+		Block: types.NewBlockWithHeader(header).WithBody([]*types.Transaction{txn}, nil),
+		Event: Added,
+		Logs:  []types.Log{{Address: to, Topics: []common.Hash{common.HexToHash("0x02")}, Data: []byte{1, 2, 3}, TxHash: txn.Hash()}},
+		OK:    true,
+	}
+
+	dto, err := block.ToDTO(true)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(dto)
+	require.NoError(t, err)
+
+	var dto2 *BlockDTO
+	require.NoError(t, json.Unmarshal(data, &dto2))
+
+	block2, err := FromDTO(dto2)
+	require.NoError(t, err)
+
+	require.Equal(t, block.Hash(), block2.Hash())
+	require.Equal(t, block.NumberU64(), block2.NumberU64())
+	require.Equal(t, block.ParentHash(), block2.ParentHash())
+	require.Equal(t, block.Time(), block2.Time())
+	require.Equal(t, block.BaseFee(), block2.BaseFee())
+	require.Equal(t, block.Event, block2.Event)
+	require.Equal(t, block.OK, block2.OK)
+	require.Len(t, block2.Transactions(), 1)
+	require.Equal(t, txn.Hash(), block2.Transactions()[0].Hash())
+	require.Equal(t, block.Logs[0].Data, block2.Logs[0].Data)
+
+	// hashes only
+	dto, err = block.ToDTO()
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{txn.Hash()}, dto.TxnHashes)
+	require.Empty(t, dto.Transactions)
+
+	// the hashes are kept through a round trip without the full transactions
+	block3, err := FromDTO(dto)
+	require.NoError(t, err)
+	require.Empty(t, block3.Transactions())
+	require.Equal(t, []common.Hash{txn.Hash()}, block3.TxnHashes())
+
+	dto3, err := block3.ToDTO(true)
+	require.NoError(t, err)
+	require.Equal(t, dto.TxnHashes, dto3.TxnHashes)
+	require.Empty(t, dto3.Transactions)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,20 +14,67 @@ import (
 	"github.com/0xsequence/ethkit/go-ethereum"
 	"github.com/0xsequence/ethkit/go-ethereum/common"
 	"github.com/0xsequence/ethkit/go-ethereum/core/types"
-	"github.com/goware/channel"
 	"github.com/goware/logger"
 	"github.com/goware/superr"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var DefaultOptions = Options{
 	Logger:                   logger.NewLogger(logger.LogLevel_WARN),
 	PollingInterval:          1000 * time.Millisecond,
+	PollingJitter:            0,
+	StreamingMode:            false,
 	Timeout:                  20 * time.Second,
+	RetryPolicy:              nil, // LinearRetryPolicy(PollingInterval, 10)
+	HeadersOnly:              false,
+	ExpectedChainID:          nil, // not checked
 	StartBlockNumber:         nil, // latest
+	StartBlockHash:           nil,
+	CatchUpThreshold:         0, // disabled
+	MaxConcurrentFetches:     10,
+	StateStore:               nil, // disabled
 	TrailNumBlocksBehindHead: 0,   // latest
+	TrailToSafeBlock:         false,
 	BlockRetentionLimit:      200,
+	RetentionSlack:           0,
 	WithLogs:                 false,
-	LogTopics:                []common.Hash{}, // all logs
+	WithReceipts:             false,
+	UseBlockReceipts:         false,
+	LogTopics:                []common.Hash{},    // all logs
+	LogTopicGroups:           nil,                // all logs
+	LogAddresses:             []common.Address{}, // all contracts
+	DisableLogsBloomCheck:    false,
+	BackpressureMode:         BackpressureFatal,
+	PublishQueueSize:         0, // 2 * BlockRetentionLimit
+	SubscriberBufferLimit:    5000,
+	SubscriberOverflowPolicy: SubscriberDropOldest,
+	DecodeTokenTransfers:     false,
+	IndexLogsByAddress:       false,
+	NumBlocksToFinality:      0,
+	FinalityFunc:             nil,
+	FinalityTags:             false,
+	PublishFinalized:         false,
+	SparseSampling:           0,   // disabled
+	MetricsRegisterer:        nil, // disabled
+	BlockLinkFunc:            nil, // LinkByParentHash
+	MaxReorgDepth:            0,   // unlimited
+	ReorgRecoveryPause:       0,   // PollingInterval
+	ReorgHistoryLimit:        0,   // disabled
+	SyncTolerance:            0,
+	ValidateTimestamps:       false,
+	TimestampTolerance:       0,
+	FutureTimestampTolerance: 0, // disabled
+	StallTimeout:             0, // disabled
+	OnStall:                  nil,
+	OnAnomaly:                nil,
+	BlockValidator:           nil,
+	OnReorg:                  nil,
+	OnNewHead:                nil,
+	CrossCheckProvider:       nil,
+	CrossCheckDepth:          0,
+	DivergencePolicy:         DivergenceHalt,
+	OnChainGap:               nil,
+	ChainGapPolicy:           ChainGapRetry,
 	DebugLogging:             false,
 }
 
@@ -37,12 +85,67 @@ type Options struct {
 	// PollingInterval to query the chain for new blocks
 	PollingInterval time.Duration
 
+	// PollingJitter randomizes each poll by up to +/- the jitter, so monitors polling the
+	// same node don't end up in sync and spike its load. The jitter is applied on top of
+	// the adaptive poll interval, and 0 disables it.
+	PollingJitter time.Duration
+
+	// StreamingMode will subscribe to new heads over the provider's websocket
+	// endpoint, and fetch new blocks as soon as they're announced. Polling continues
+	// at PollingInterval as a fallback, which also catches up on any blocks missed
+	// while the websocket connection was down. When the provider has no websocket
+	// endpoint, the monitor only polls.
+	StreamingMode bool
+
 	// Timeout duration used by the rpc client when fetching data from the remote node.
 	Timeout time.Duration
 
+	// RetryPolicy determines the backoff between attempts to fetch a block after the
+	// provider fails, and the number of attempts after which the fetch fails. Defaults to
+	// LinearRetryPolicy(PollingInterval, 10). See also ExponentialRetryPolicy.
+	RetryPolicy RetryPolicy
+
+	// HeadersOnly fetches blocks without their transactions, ie. only their headers, which
+	// greatly reduces bandwidth on chains with many transactions when only the headers are
+	// used. In this mode, Block.Transactions() is empty, GetTransaction always returns nil
+	// and SubscribeTransactions publishes no events. Logs are still fetched with WithLogs.
+	HeadersOnly bool
+
+	// ExpectedChainID is the chain id of the network the monitor is expected to observe,
+	// which Run checks against the chain id of the provider before fetching any block, and
+	// returns ErrChainIDMismatch if they differ, ie. when pointed at the rpc endpoint of the
+	// wrong network. Not checked when nil.
+	ExpectedChainID *big.Int
+
 	// StartBlockNumber to begin the monitor from.
 	StartBlockNumber *big.Int
 
+	// StartBlockHash will begin the monitor from the child of the block with this hash,
+	// ie. the last block processed by a resuming indexer, guarding against resuming on a
+	// forked block. The block is fetched and seeded as the head of the chain without being
+	// published, and Run returns ErrStartBlockNotFound if it's not on the provider's
+	// canonical chain. Takes precedence over StartBlockNumber, which when also set must be
+	// the number of the block.
+	StartBlockHash *common.Hash
+
+	// CatchUpThreshold enables fetching blocks concurrently in ranges, instead of one
+	// block per tick, when the monitor is more than CatchUpThreshold blocks behind the
+	// head, ie. when starting far behind with StartBlockNumber. Each range is asserted
+	// to be linked by parent hashes before its blocks are added to the chain, and the
+	// monitor falls back to polling block by block once caught up. 0 disables it.
+	CatchUpThreshold int
+
+	// MaxConcurrentFetches is the max number of blocks fetched concurrently while
+	// catching up, see CatchUpThreshold, and the max number of blocks whose logs and
+	// receipts are backfilled concurrently across the retained chain.
+	MaxConcurrentFetches int
+
+	// StateStore persists the retained chain when the monitor stops, ie. on Stop or when
+	// the Run context is done, and restores it in NewMonitor when Bootstrap is set, so the
+	// monitor resumes from where it left off after a restart. Failing to save the state is
+	// logged and doesn't block the shutdown. See also NewFileStateStore.
+	StateStore StateStore
+
 	// Bootstrap flag which indicates the monitor will expect the monitor's
 	// events to be bootstrapped, and will continue from that point. This als
 	// takes precedence over StartBlockNumber when set to true.
@@ -52,20 +155,291 @@ type Options struct {
 	// the head of the chain before broadcasting new events to the subscribers.
 	TrailNumBlocksBehindHead int
 
+	// TrailToSafeBlock will only broadcast the blocks up to the node's "safe" block, instead
+	// of trailing a fixed number of blocks behind the head, for reorg-resistant delivery
+	// without guessing a confirmation count. The safe block is updated on every new head,
+	// and blocks are held back until it's known. Requires FinalityTags, and can't be used
+	// with TrailNumBlocksBehindHead. The BlockRetentionLimit must cover the distance between
+	// the head and the safe block, ie. 64 blocks on Ethereum mainnet.
+	TrailToSafeBlock bool
+
 	// BlockRetentionLimit is the number of blocks we keep on the canonical chain
-	// cache.
+	// cache. NewMonitor adds TrailNumBlocksBehindHead to it, so the trailed blocks
+	// don't count against the limit, see Monitor.Options.
 	BlockRetentionLimit int
 
-	// WithLogs will include logs with the blocks if specified true.
+	// RetentionSlack is the number of blocks the canonical chain cache may grow
+	// beyond BlockRetentionLimit before being trimmed back down in one batch,
+	// which amortizes the cost of trimming on high-throughput chains. The cache
+	// will hold up to BlockRetentionLimit+RetentionSlack blocks.
+	RetentionSlack int
+
+	// WithLogs will include logs with the blocks if specified true. The logs of a block
+	// are sorted by their log index and de-duplicated. When fetching the logs of a block
+	// fails, the block and every event after it are held back until its logs are
+	// backfilled, so each Added block is published exactly once, with its complete logs.
 	WithLogs bool
 
+	// WithReceipts will include the transaction receipts with the blocks, see Block.Receipts.
+	// Receipts are fetched with eth_getBlockReceipts, or per transaction if the node doesn't
+	// support it. Like logs, failed fetches are backfilled before the block is published.
+	// Cannot be used with HeadersOnly, as the receipts are matched to the transactions.
+	WithReceipts bool
+
+	// UseBlockReceipts will derive the logs of each block from its receipts fetched with
+	// eth_getBlockReceipts, instead of a separate eth_getLogs call, which also provides the
+	// receipts when WithReceipts is set, halving the rpc calls per block. The logs are
+	// filtered by LogAddresses and LogTopics or LogTopicGroups, the same as eth_getLogs.
+	// Falls back to eth_getLogs if the node doesn't support eth_getBlockReceipts.
+	// Requires WithLogs.
+	UseBlockReceipts bool
+
 	// LogTopics will filter only specific log topics to include.
 	LogTopics []common.Hash
 
+	// LogTopicGroups will filter logs by topic position, the same as the Topics of an
+	// ethereum.FilterQuery, ie. {{Transfer}, nil, {addr}} matches Transfer logs to addr.
+	// Each group matches any of its topics, and a nil group matches any topic. LogTopics
+	// is the same as a single group, so only one of LogTopics and LogTopicGroups may be set.
+	LogTopicGroups [][]common.Hash
+
+	// LogAddresses will filter only logs emitted by specific contracts to include.
+	// When combined with LogTopics or LogTopicGroups, logs must match both.
+	LogAddresses []common.Address
+
+	// DisableLogsBloomCheck will fetch the logs of every block. By default, when filtering
+	// by LogAddresses or topics, the logs of a block are not fetched when its logsBloom
+	// proves none of the addresses and topics filtered for are present, which saves the
+	// majority of getLogs calls with sparse log filters. An empty logsBloom is not trusted,
+	// as some nodes and proxies don't return it, so the logs of such blocks are always
+	// fetched. It should be set for chains whose logsBloom doesn't cover all of the logs of
+	// a block, ie. system logs which are not included in the bloom.
+	DisableLogsBloomCheck bool
+
+	// BackpressureMode determines how the monitor behaves when the publish queue
+	// fills up, ie. when blocks are held back waiting on logs to be backfilled.
+	// See BackpressureMode for the lag implications of each mode.
+	BackpressureMode BackpressureMode
+
+	// PublishQueueSize is the max number of events held in the publish queue, ie. events
+	// held back by TrailNumBlocksBehindHead or waiting on a slow subscriber, before the
+	// BackpressureMode applies. Defaults to 2 * BlockRetentionLimit when 0. With the
+	// default BackpressureFatal, a full queue stops the monitor, as the events can't be
+	// dropped without subscribers silently missing blocks. Raising the size helps absorb
+	// bursts, ie. a slow subscriber during a catch-up or a deep reorg, but a subscriber
+	// which is consistently slower than the chain will fill any queue, in which case
+	// BackpressureBlock or BackpressureDropTrailing is the right mitigation. Use
+	// Monitor.PublishQueueLen to alert before the queue overflows.
+	//
+	// The trailed blocks are held in the queue, so it must be larger than
+	// TrailNumBlocksBehindHead. With TrailToSafeBlock, it must hold the blocks between
+	// the head and the safe block, ie. 64 blocks on Ethereum mainnet, plus the events of
+	// reorgs and slow subscribers, which the default of 2 * BlockRetentionLimit covers
+	// when the BlockRetentionLimit covers the distance to the safe block.
+	PublishQueueSize int
+
+	// SubscriberBufferLimit is the max number of published events buffered for a
+	// subscriber which has yet to read them, after which the SubscriberOverflowPolicy
+	// applies, so a slow subscriber can't grow its buffer without limit. 0 means unlimited.
+	// A warning is logged once 100 events, or the limit if lower, are buffered for a
+	// subscriber.
+	SubscriberBufferLimit int
+
+	// SubscriberOverflowPolicy determines how the monitor treats a subscriber which
+	// falls behind by more than SubscriberBufferLimit events. Dropped events and
+	// disconnected subscribers are counted by Monitor.SubscriberOverflowCount. Defaults
+	// to SubscriberDropOldest.
+	SubscriberOverflowPolicy SubscriberOverflowPolicy
+
+	// DecodeTokenTransfers will decode the ERC20 Transfer events of each published
+	// block into Block.TokenTransfers. Requires WithLogs, and the LogTopics or
+	// LogTopicGroups filters must not filter out the Transfer topic.
+	DecodeTokenTransfers bool
+
+	// IndexLogsByAddress will maintain an in-memory index of the logs of the retained
+	// canonical chain by contract address, for use with GetLogsByAddress. Requires
+	// WithLogs. The index holds a reference to every retained log, so it costs memory
+	// proportional to the BlockRetentionLimit.
+	IndexLogsByAddress bool
+
+	// NumBlocksToFinality is the number of blocks behind the head at which a block
+	// is considered final by FinalizedBlock. It is also the fallback used when
+	// FinalityFunc fails.
+	NumBlocksToFinality int
+
+	// FinalityFunc optionally determines the latest finalized block for chains with
+	// explicit finality, ie. an L2 checkpoint contract. See CheckpointFinalityFunc.
+	FinalityFunc FinalityFunc
+
+	// FinalityTags will query the node for its "finalized" and "safe" blocks on every
+	// new head, as supported by post-merge Ethereum, for use with FinalizedBlock and
+	// SafeBlock. Falls back to NumBlocksToFinality when the node doesn't support the tags.
+	// It costs two extra rpc calls per block. Can't be used with FinalityFunc.
+	FinalityTags bool
+
+	// PublishFinalized will publish a Finalized event for every block previously published
+	// as Added once it reaches finality, as determined by NumBlocksToFinality, FinalityFunc
+	// or FinalityTags, ie. so subscribers can prune their rollback buffers. Blocks which
+	// were on the chain before Run, ie. bootstrapped, are not published as Finalized.
+	PublishFinalized bool
+
+	// BlockLinkFunc optionally determines if a block is the child of a parent block,
+	// for chains which compute block hashes differently. Defaults to LinkByParentHash.
+	BlockLinkFunc BlockLinkFunc
+
+	// MaxReorgDepth is the maximum number of blocks the monitor will pop from its
+	// canonical chain during a reorg, after which Run exits with ErrFatal wrapping
+	// ErrReorg. A reorg this deep usually means the node is forked or serving the wrong
+	// chain. 0 means unlimited.
+	MaxReorgDepth int
+
+	// ReorgRecoveryPause is the pause taken after popping each block during a reorg, to
+	// give the node time to sync to the new fork, which grows with the number of blocks
+	// popped up to 5 times the pause, ie. 1x after the first block, 2x after the second, and
+	// so on. A reorg which reaches MaxReorgDepth has then paused for up to about
+	// 5 * ReorgRecoveryPause * MaxReorgDepth before Run exits. Defaults to PollingInterval
+	// when 0.
+	ReorgRecoveryPause time.Duration
+
+	// ReorgHistoryLimit is the number of most recent reorgs kept in memory, along with
+	// the blocks they removed, to reconstruct past states of the canonical chain with
+	// ChainStateAt. 0 disables the history.
+	ReorgHistoryLimit int
+
+	// SyncTolerance is the number of blocks the monitor may be behind the head of
+	// the remote chain to be considered synced, see Monitor.Synced. When 0, the monitor
+	// is synced once it has fetched the latest block.
+	SyncTolerance int
+
+	// ValidateTimestamps will check each new block's timestamp is not earlier than
+	// its parent's, reporting violations as an Anomaly. The block is still accepted.
+	ValidateTimestamps bool
+
+	// TimestampTolerance is how much earlier than its parent a block's timestamp may
+	// be before it is reported by ValidateTimestamps.
+	TimestampTolerance time.Duration
+
+	// FutureTimestampTolerance is how far ahead of the local clock a block's timestamp
+	// may be. Blocks timestamped further in the future, ie. by a misconfigured node, are
+	// reported as an anomaly wrapping ErrFutureTimestamp and rejected like blocks failing
+	// the BlockValidator, so they're fetched again on the next poll. 0 disables the check,
+	// in which case future-dated blocks are accepted, but are still excluded from the
+	// average block time.
+	FutureTimestampTolerance time.Duration
+
+	// StallTimeout is the duration after which the monitor reports a stall when no new
+	// block was accepted, ie. when the provider silently stopped advancing behind a load
+	// balancer, with a warning and the OnStall hook. The stall is reported again every
+	// StallTimeout for as long as it lasts, and not while the monitor is paused. 0 disables
+	// the check.
+	StallTimeout time.Duration
+
+	// OnStall is called when the monitor stalls, see StallTimeout, with the head of the
+	// chain, which is nil if no block was accepted yet, and how long ago it was accepted,
+	// ie. to fail over to another provider. It's called from the monitor's run loop, so
+	// it must not block.
+	OnStall func(lastBlock *Block, since time.Duration)
+
+	// OnAnomaly is called for every block which fails one of the monitor's
+	// consistency checks. It's called from the monitor's run loop, so it must not block.
+	OnAnomaly func(Anomaly)
+
+	// BlockValidator is called for every block before it's added to the canonical chain,
+	// to enforce custom invariants, ie. a gas limit range or an extraData prefix on a
+	// permissioned chain. A rejected block is reported as an anomaly wrapping
+	// ErrInvalidBlock, and is not added to the chain. The block is fetched again on the
+	// next poll, in case the node serves a valid block at the same height later.
+	BlockValidator func(*types.Block) error
+
+	// OnReorg is called once per reorg, after the canonical chain has been rebuilt,
+	// with the common ancestor and the hashes of the removed and added blocks. It's
+	// called from the monitor's run loop, so it must not block.
+	OnReorg func(ReorgInfo)
+
+	// OnNewHead is called for every Added block once it's ready to be published to the
+	// subscribers, in order and before it's broadcast, ie. for lightweight cache invalidation.
+	// It's not called for Removed blocks, and blocks held back by TrailNumBlocksBehindHead or
+	// TrailToSafeBlock are only passed once they're published. It's called from the monitor's
+	// run loop, so it must return quickly.
+	OnNewHead func(*Block)
+
+	// CrossCheckProvider is an optional second, independent node of the same chain. The
+	// monitor cross-checks the hash of every block buried CrossCheckDepth blocks behind
+	// the head against it before publishing, and applies the DivergencePolicy if the
+	// providers disagree, to avoid acting on the wrong chain of a faulty or compromised
+	// primary provider. It costs one extra rpc call per block.
+	CrossCheckProvider *ethrpc.Provider
+
+	// CrossCheckDepth is the number of blocks behind the head at which a block is buried
+	// deep enough that both providers must agree on it, ie. the chain's finality depth.
+	// Required when CrossCheckProvider is set.
+	CrossCheckDepth int
+
+	// DivergencePolicy determines how the monitor reacts when the CrossCheckProvider
+	// disagrees on a buried block. Defaults to DivergenceHalt.
+	DivergencePolicy DivergencePolicy
+
+	// OnChainGap is called when the parent of a new block is not found on the provider, ie.
+	// a pruned node or one which is missing blocks, so the new block can't be linked back
+	// to the retained chain. from and to are the numbers of the missing blocks, between the
+	// head of the retained chain and the new block. It's called from the monitor's run
+	// loop, every time the gap is hit, so it must not block.
+	OnChainGap func(from, to *big.Int)
+
+	// ChainGapPolicy determines how the monitor reacts to a gap in the chain, see
+	// OnChainGap. Defaults to ChainGapRetry.
+	ChainGapPolicy ChainGapPolicy
+
+	// SparseSampling will only observe every Nth block when greater than 1, ie. the blocks
+	// whose number is a multiple of N, for low-resolution monitoring at a fraction of the
+	// rpc cost. The sampled blocks don't link to each other, so reorgs are not detected or
+	// published in this mode, and the StreamingMode, MaxReorgDepth, OnReorg, PublishFinalized
+	// and CrossCheckProvider options don't apply. Sampled blocks are published as Added
+	// events, with their logs when WithLogs is set.
+	SparseSampling int
+
+	// MetricsRegisterer optionally registers prometheus metrics of the monitor's health,
+	// ie. the number of blocks added and removed, reorg depths, the publish queue length,
+	// subscriber overflows, getLogs failures and backfills, the current poll interval, and
+	// the number of retained and evicted blocks. To label the metrics, ie. by chain id, wrap
+	// the registerer with prometheus.WrapRegistererWith. When nil, no metrics are collected.
+	MetricsRegisterer prometheus.Registerer
+
 	// DebugLogging toggle
 	DebugLogging bool
 }
 
+// BlockLinkFunc returns true if the child block directly follows the parent block
+// on the same chain.
+type BlockLinkFunc func(parent, child *types.Block) bool
+
+// LinkByParentHash links blocks by comparing the parent hash of the child block with
+// the hash of the parent block, as done by standard EVM chains.
+func LinkByParentHash(parent, child *types.Block) bool {
+	return child.ParentHash() == parent.Hash()
+}
+
+// BackpressureMode is the strategy used when the publish queue reaches its capacity.
+type BackpressureMode int
+
+const (
+	// BackpressureFatal will stop the monitor with ErrFatal once the publish
+	// queue is full. This is the default behaviour.
+	BackpressureFatal BackpressureMode = iota
+
+	// BackpressureBlock will pause fetching new blocks once the publish queue is
+	// nearly full, until the queued events drain. No events are lost, but the
+	// monitor will lag behind the head of the chain for as long as the queue
+	// remains backed up. Note, a single reorg which overflows the queue in one
+	// go is still fatal.
+	BackpressureBlock
+
+	// BackpressureDropTrailing will drop the oldest events in the publish queue
+	// to make room for new ones. The monitor keeps up with the head of the chain,
+	// but subscribers will observe a gap in the events they receive.
+	BackpressureDropTrailing
+)
+
 var (
 	ErrFatal                 = errors.New("ethmonitor: fatal error, stopping")
 	ErrReorg                 = errors.New("ethmonitor: block reorg")
@@ -73,28 +447,90 @@ var (
 	ErrUnexpectedBlockNumber = errors.New("ethmonitor: unexpected block number")
 	ErrQueueFull             = errors.New("ethmonitor: publish queue is full")
 	ErrMaxAttempts           = errors.New("ethmonitor: max attempts hit")
+	ErrMissingBlockNumber    = errors.New("ethmonitor: block is missing its number")
+	ErrStartBlockNotFound    = errors.New("ethmonitor: start block not found on the canonical chain")
+	ErrChainIDMismatch       = errors.New("ethmonitor: provider chain id does not match the expected chain id")
 )
 
 type Monitor struct {
 	options Options
 
 	log      logger.Logger
-	provider *ethrpc.Provider
+	provider BlockProvider
+	rpc      *ethrpc.Provider
+
+	// replay serves the blocks during a Replay, instead of the provider
+	replay *replayProvider
 
 	chain           *Chain
 	nextBlockNumber *big.Int
 
-	publishCh    chan Blocks
-	publishQueue *queue
-	subscribers  []*subscriber
-
-	ctx     context.Context
-	ctxStop context.CancelFunc
-	running int32
-	mu      sync.RWMutex
+	publishCh           chan Blocks
+	publishQueue        *queue
+	subscribers         []*subscriber
+	subscriberOverflows uint64
+	trailMaxBlockNum    uint64
+
+	// published are the canonical blocks published to the subscribers, see
+	// SubscribeWithBacklog
+	published Blocks
+
+	// remoteHeadNum, lastPollAt and pollInterval are reported by Status
+	remoteHeadNum uint64
+	lastPollAt    int64
+	pollInterval  int64
+
+	ticks chan time.Time
+
+	finality finality
+	logIndex *logIndex
+	metrics  *metrics
+
+	reorgHistory      []reorgRecord
+	reorgHistoryFloor uint64
+
+	synced        chan struct{}
+	isSynced      int32
+	lastSyncCheck time.Time
+
+	// catchUpHead is the head block number last seen when catching up, which was
+	// fetched when the next block number was catchUpCheckedAt
+	catchUpHead      uint64
+	catchUpCheckedAt uint64
+
+	// logFilter matches the logs derived from block receipts, see Options.UseBlockReceipts
+	logFilter logMatcher
+
+	// blockReceiptsUnsupported is set to 1 once the node fails eth_getBlockReceipts as
+	// unsupported, and is accessed atomically as logs are backfilled concurrently
+	blockReceiptsUnsupported int32
+
+	// crossCheckedBlockNum is the last block number which passed the cross-check
+	crossCheckedBlockNum uint64
+
+	// stall tracks the time since the head last changed, see Options.StallTimeout
+	stall stall
+
+	// finalizedEventNum is the last block number published as Finalized, when
+	// finalizedEventSet is true. finalizedEventNone is set instead when no block has
+	// been published as Finalized yet, ie. starting from the genesis block.
+	finalizedEventNum  uint64
+	finalizedEventSet  bool
+	finalizedEventNone bool
+
+	ctx      context.Context
+	ctxStop  context.CancelFunc
+	running  int32
+	paused   int32
+	resumeCh chan struct{}
+	resetCh  chan resetRequest
+	mu       sync.RWMutex
+
+	// broadcastMu serializes the broadcasts to the subscribers, see broadcast
+	broadcastMu sync.Mutex
 }
 
-func NewMonitor(provider *ethrpc.Provider, options ...Options) (*Monitor, error) {
+func NewMonitor(provider BlockProvider, options ...Options) (*Monitor, error) {
 	opts := DefaultOptions
 	if len(options) > 0 {
 		opts = options[0]
@@ -109,8 +545,97 @@ func NewMonitor(provider *ethrpc.Provider, options ...Options) (*Monitor, error)
 		return nil, fmt.Errorf("ethmonitor: logger is nil")
 	}
 
+	if opts.PollingInterval <= 0 {
+		return nil, fmt.Errorf("ethmonitor: PollingInterval (%s) must be greater than 0", opts.PollingInterval)
+	}
+
+	if opts.Timeout <= 0 {
+		return nil, fmt.Errorf("ethmonitor: Timeout (%s) must be greater than 0", opts.Timeout)
+	}
+
+	if opts.ReorgRecoveryPause < 0 {
+		return nil, fmt.Errorf("ethmonitor: ReorgRecoveryPause (%s) must not be negative", opts.ReorgRecoveryPause)
+	}
+
+	if opts.BlockRetentionLimit <= 0 {
+		return nil, fmt.Errorf("ethmonitor: BlockRetentionLimit (%d) must be greater than 0", opts.BlockRetentionLimit)
+	}
+
+	if opts.TrailNumBlocksBehindHead < 0 {
+		return nil, fmt.Errorf("ethmonitor: TrailNumBlocksBehindHead (%d) must not be negative", opts.TrailNumBlocksBehindHead)
+	}
+
+	if opts.TrailNumBlocksBehindHead > 0 && opts.TrailNumBlocksBehindHead >= opts.BlockRetentionLimit {
+		return nil, fmt.Errorf("ethmonitor: TrailNumBlocksBehindHead (%d) must be < BlockRetentionLimit (%d)", opts.TrailNumBlocksBehindHead, opts.BlockRetentionLimit)
+	}
+
+	if len(opts.LogTopics) > 0 && len(opts.LogTopicGroups) > 0 {
+		return nil, fmt.Errorf("ethmonitor: only one of LogTopics and LogTopicGroups may be set")
+	}
+
+	if !opts.WithLogs {
+		if opts.UseBlockReceipts {
+			return nil, fmt.Errorf("ethmonitor: UseBlockReceipts requires WithLogs")
+		}
+		if opts.DecodeTokenTransfers {
+			return nil, fmt.Errorf("ethmonitor: DecodeTokenTransfers requires WithLogs")
+		}
+		if opts.IndexLogsByAddress {
+			return nil, fmt.Errorf("ethmonitor: IndexLogsByAddress requires WithLogs")
+		}
+	}
+
+	if opts.WithReceipts && opts.HeadersOnly {
+		return nil, fmt.Errorf("ethmonitor: only one of WithReceipts and HeadersOnly may be set")
+	}
+
+	if opts.FinalityFunc != nil && opts.FinalityTags {
+		return nil, fmt.Errorf("ethmonitor: only one of FinalityFunc and FinalityTags may be set")
+	}
+
+	if opts.TrailToSafeBlock && !opts.FinalityTags {
+		return nil, fmt.Errorf("ethmonitor: TrailToSafeBlock requires FinalityTags")
+	}
+	if opts.TrailToSafeBlock && opts.TrailNumBlocksBehindHead > 0 {
+		return nil, fmt.Errorf("ethmonitor: only one of TrailNumBlocksBehindHead and TrailToSafeBlock may be set")
+	}
+
+	if opts.PublishFinalized && opts.NumBlocksToFinality <= 0 && opts.FinalityFunc == nil && !opts.FinalityTags {
+		return nil, fmt.Errorf("ethmonitor: PublishFinalized requires one of NumBlocksToFinality, FinalityFunc or FinalityTags")
+	}
+
+	if opts.SparseSampling < 0 {
+		return nil, fmt.Errorf("ethmonitor: SparseSampling must not be negative")
+	}
+
+	if opts.PublishQueueSize < 0 {
+		return nil, fmt.Errorf("ethmonitor: PublishQueueSize must not be negative")
+	}
+	if opts.PublishQueueSize > 0 && opts.PublishQueueSize <= opts.TrailNumBlocksBehindHead {
+		return nil, fmt.Errorf("ethmonitor: PublishQueueSize (%d) must be > TrailNumBlocksBehindHead (%d), as the trailed blocks are held in the publish queue", opts.PublishQueueSize, opts.TrailNumBlocksBehindHead)
+	}
+
+	if opts.SubscriberBufferLimit < 0 {
+		return nil, fmt.Errorf("ethmonitor: SubscriberBufferLimit must not be negative")
+	}
+
+	if opts.CrossCheckProvider != nil && opts.CrossCheckDepth <= 0 {
+		return nil, fmt.Errorf("ethmonitor: CrossCheckDepth must be set with CrossCheckProvider")
+	}
+
 	opts.BlockRetentionLimit += opts.TrailNumBlocksBehindHead
 
+	if opts.PublishQueueSize == 0 {
+		opts.PublishQueueSize = opts.BlockRetentionLimit * 2
+	}
+
+	if opts.RetryPolicy == nil {
+		opts.RetryPolicy = LinearRetryPolicy(opts.PollingInterval, 10)
+	}
+	if opts.ReorgRecoveryPause == 0 {
+		opts.ReorgRecoveryPause = opts.PollingInterval
+	}
+
 	if opts.DebugLogging {
 		stdLogger, ok := opts.Logger.(*logger.StdLogAdapter)
 		if ok {
@@ -118,15 +643,54 @@ func NewMonitor(provider *ethrpc.Provider, options ...Options) (*Monitor, error)
 		}
 	}
 
-	return &Monitor{
+	var logIndex *logIndex
+	if opts.IndexLogsByAddress {
+		logIndex = newLogIndex()
+	}
+
+	if err := validateProvider(provider, opts); err != nil {
+		return nil, err
+	}
+
+	chain := newChain(opts.BlockRetentionLimit, opts.RetentionSlack, opts.Bootstrap)
+	if opts.BlockLinkFunc != nil {
+		chain.blockLinkFunc = opts.BlockLinkFunc
+	}
+	if opts.SparseSampling > 1 {
+		chain.sampling = uint64(opts.SparseSampling)
+	}
+
+	metrics, err := newMetrics(opts.MetricsRegisterer, chain)
+	if err != nil {
+		return nil, fmt.Errorf("ethmonitor: failed to register metrics: %w", err)
+	}
+
+	m := &Monitor{
 		options:      opts,
 		log:          opts.Logger,
 		provider:     provider,
-		chain:        newChain(opts.BlockRetentionLimit, opts.Bootstrap),
+		chain:        chain,
 		publishCh:    make(chan Blocks),
-		publishQueue: newQueue(opts.BlockRetentionLimit * 2),
+		publishQueue: newQueue(opts.PublishQueueSize),
 		subscribers:  make([]*subscriber, 0),
-	}, nil
+		logIndex:     logIndex,
+		metrics:      metrics,
+		resumeCh:     make(chan struct{}, 1),
+		resetCh:      make(chan resetRequest),
+	}
+	m.rpc, _ = provider.(*ethrpc.Provider)
+	if opts.UseBlockReceipts {
+		m.logFilter = newLogMatcher(SubscriptionFilter{Addresses: opts.LogAddresses, Topics: m.logTopics()})
+	}
+
+	if opts.Bootstrap && opts.StateStore != nil {
+		err := m.loadState()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
 }
 
 func (m *Monitor) Run(ctx context.Context) error {
@@ -135,9 +699,17 @@ func (m *Monitor) Run(ctx context.Context) error {
 	}
 
 	m.ctx, m.ctxStop = context.WithCancel(ctx)
+	m.resetSynced()
+	m.resetFinalizedEvents()
+	m.resetStall()
 
 	atomic.StoreInt32(&m.running, 1)
 	defer atomic.StoreInt32(&m.running, 0)
+	defer m.saveState()
+
+	m.log.Infof("ethmonitor: effective options pollingInterval=%s streamingMode=%t withLogs=%t trailNumBlocksBehindHead=%d blockRetentionLimit=%d retentionSlack=%d publishQueueCapacity=%d",
+		m.options.PollingInterval, m.options.StreamingMode, m.options.WithLogs, m.options.TrailNumBlocksBehindHead,
+		m.options.BlockRetentionLimit, m.options.RetentionSlack, m.PublishQueueCapacity())
 
 	// Check if in bootstrap mode -- in which case we expect nextBlockNumber
 	// to already be set.
@@ -145,18 +717,37 @@ func (m *Monitor) Run(ctx context.Context) error {
 		return errors.New("ethmonitor: monitor is in Bootstrap mode, and must be bootstrapped before run")
 	}
 
+	// Check the provider is on the expected network
+	if m.options.ExpectedChainID != nil {
+		err := m.checkChainID(m.ctx)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Start from latest, or start from a specific block number
 	if m.chain.Head() != nil {
 		// starting from last block of our canonical chain
 		m.nextBlockNumber = big.NewInt(0).Add(m.chain.Head().Number(), big.NewInt(1))
+	} else if m.options.StartBlockHash != nil {
+		// starting from the child of a specific block
+		err := m.seedStartBlock(m.ctx, *m.options.StartBlockHash)
+		if err != nil {
+			return err
+		}
+		m.nextBlockNumber = big.NewInt(0).Add(m.chain.Head().Number(), big.NewInt(1))
 	} else if m.options.StartBlockNumber != nil {
 		if m.options.StartBlockNumber.Cmp(big.NewInt(0)) >= 0 {
 			// starting from specific block number
 			m.nextBlockNumber = m.options.StartBlockNumber
 		} else {
 			// starting some number blocks behind the latest block num
-			latestBlock, _ := m.provider.BlockByNumber(m.ctx, nil)
-			if latestBlock != nil && latestBlock.Number() != nil {
+			latestBlock, err := m.blockByNumber(m.ctx, nil)
+			if errors.Is(err, ethrpc.ErrMissingBlockHeader) {
+				m.log.Errorf("ethmonitor: provider returned the latest block without a number: %v", err)
+				return superr.New(ErrMissingBlockNumber, fmt.Errorf("failed to start %d blocks behind the latest block: %w", m.options.StartBlockNumber, err))
+			}
+			if latestBlock != nil {
 				m.nextBlockNumber = big.NewInt(0).Add(latestBlock.Number(), m.options.StartBlockNumber)
 				if m.nextBlockNumber.Cmp(big.NewInt(0)) < 0 {
 					m.nextBlockNumber = nil
@@ -181,7 +772,9 @@ func (m *Monitor) Run(ctx context.Context) error {
 				return
 			case blocks := <-m.publishCh:
 				if m.options.DebugLogging {
-					m.log.Debug("ethmonitor: publishing block", blocks.LatestBlock().NumberU64(), "# events:", len(blocks))
+					// the head is not necessarily an added block, ie. for a Reset event
+					head := blocks.Head()
+					m.log.Debug("ethmonitor: publishing block", head.NumberU64(), "event:", head.Event, "# events:", len(blocks))
 				}
 
 				// broadcast to subscribers
@@ -190,6 +783,11 @@ func (m *Monitor) Run(ctx context.Context) error {
 		}
 	}()
 
+	// Monitor a sample of the chain's blocks
+	if m.options.SparseSampling > 1 {
+		return m.monitorSparse()
+	}
+
 	// Monitor the chain for canonical representation
 	return m.monitor()
 }
@@ -203,21 +801,80 @@ func (m *Monitor) IsRunning() bool {
 	return atomic.LoadInt32(&m.running) == 1
 }
 
+// Pause stops the monitor from polling the node for new blocks, without stopping the
+// monitor. Subscribers stay attached and the retained chain is kept, so no events are
+// dropped during a pause, they are only delayed until Resume is called.
+func (m *Monitor) Pause() {
+	if atomic.CompareAndSwapInt32(&m.paused, 0, 1) {
+		m.log.Info("ethmonitor: paused")
+	}
+}
+
+// Resume continues polling after Pause, from the block after the head of the retained
+// chain, catching up with any blocks produced during the pause.
+func (m *Monitor) Resume() {
+	if !atomic.CompareAndSwapInt32(&m.paused, 1, 0) {
+		return
+	}
+	m.log.Info("ethmonitor: resumed")
+	select {
+	case m.resumeCh <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Monitor) IsPaused() bool {
+	return atomic.LoadInt32(&m.paused) == 1
+}
+
+// Options returns the effective options of the monitor, after normalization by NewMonitor,
+// which may differ from the configured options:
+//
+//   - BlockRetentionLimit includes TrailNumBlocksBehindHead, ie. it is the number of blocks
+//     actually retained on the canonical chain, excluding the RetentionSlack.
+//   - PublishQueueSize defaults to 2*BlockRetentionLimit when not configured.
+//   - RetryPolicy is set to the default LinearRetryPolicy when not configured.
 func (m *Monitor) Options() Options {
 	return m.options
 }
 
+// PublishQueueCapacity returns the maximum number of events held in the publish queue
+// before the BackpressureMode applies, see Options.PublishQueueSize.
+func (m *Monitor) PublishQueueCapacity() int {
+	return m.publishQueue.cap
+}
+
+// PublishQueueLen returns the number of events currently held in the publish queue, for
+// operators to alert on before it reaches the PublishQueueCapacity.
+func (m *Monitor) PublishQueueLen() int {
+	return m.publishQueue.len()
+}
+
+// Provider returns the *ethrpc.Provider of the monitor, or nil if the monitor was created
+// with another BlockProvider.
 func (m *Monitor) Provider() *ethrpc.Provider {
-	return m.provider
+	return m.rpc
 }
 
 func (m *Monitor) monitor() error {
 	ctx := m.ctx
 	events := Blocks{}
 
+	// withheld are the events held back after a divergence, see DivergencePause
+	withheld := Blocks{}
+
 	// pollInterval is used for adaptive interval
 	pollInterval := m.options.PollingInterval
 
+	// heads is nil unless streaming, in which case new heads trigger a fetch. When the
+	// stream fails, the monitor polls until it re-subscribes with backoff.
+	var retry headStreamRetry
+	heads, err := m.subscribeNewHeads(ctx)
+	if err != nil {
+		retry.failed()
+	}
+	defer func() { heads.Unsubscribe() }()
+
 	// monitor run loop
 	for {
 		select {
@@ -225,14 +882,85 @@ func (m *Monitor) monitor() error {
 		case <-m.ctx.Done():
 			return nil
 
-		case <-time.After(pollInterval):
-			headBlock := m.chain.Head()
-			if headBlock != nil {
-				m.nextBlockNumber = big.NewInt(0).Add(headBlock.Number(), big.NewInt(1))
+		case header := <-heads.Heads():
+			if header != nil && header.Number != nil {
+				m.observeRemoteHead(header.Number.Uint64())
+			}
+			heads.drain()
+
+		case reconnect := <-heads.Reconnected():
+			m.log.Warnf("ethmonitor: new heads stream reconnected after '%v', catching up from block %d", reconnect.Err, reconnect.LastBlockNumber)
+
+		case err := <-heads.DecodeErr():
+			m.log.Warnf("ethmonitor: skipped a new heads notification: %v", err)
+
+		case err := <-heads.Err():
+			m.log.Warnf("ethmonitor: new heads stream failed, polling until re-subscribed: %v", err)
+			heads.Unsubscribe()
+			heads = nil
+			retry.failed()
+			continue
+
+		case <-m.resumeCh:
+
+		case req := <-m.resetCh:
+			m.reset(req)
+			events, withheld = Blocks{}, Blocks{}
+			pollInterval = m.options.PollingInterval
+
+		case <-time.After(m.jitter(pollInterval)):
+		}
+
+		if heads == nil {
+			heads = m.resubscribeNewHeads(ctx, &retry)
+		}
+
+		m.setPollInterval(pollInterval)
+
+		if m.IsPaused() {
+			m.resetStall()
+			pollInterval = m.options.PollingInterval
+			continue
+		}
+
+		m.tick()
+		m.checkStall()
+
+		// apply backpressure by not fetching any new blocks until the
+		// publish queue drains
+		if m.options.BackpressureMode == BackpressureBlock && m.publishQueue.len() >= m.backpressureThreshold() {
+			m.log.Warnf("ethmonitor: publish queue is near capacity (%d/%d), pausing block fetching", m.publishQueue.len(), m.publishQueue.cap)
+			if m.options.WithLogs || m.options.WithReceipts {
+				m.backfillChainLogs(ctx)
+			}
+			err := m.publish(ctx, Blocks{})
+			if err != nil {
+				return superr.New(ErrFatal, err)
 			}
+			pollInterval = m.options.PollingInterval
+			continue
+		}
+
+		headBlock := m.chain.Head()
+		if headBlock != nil {
+			m.nextBlockNumber = big.NewInt(0).Add(headBlock.Number(), big.NewInt(1))
+		}
 
+		// fetch a range of blocks at once if we're far behind the head
+		nextBlocks, err := m.fetchCatchUpBlocks(ctx)
+		if err != nil {
+			m.log.Warnf("ethmonitor: failed to catch up from block # %d, fetching a single block instead, due to: %v", m.nextBlockNumber, err)
+		}
+
+		if len(nextBlocks) == 0 {
 			nextBlock, err := m.fetchBlockByNumber(ctx, m.nextBlockNumber)
 			if err == ethereum.NotFound {
+				// we're at the head of the chain
+				if headBlock != nil {
+					m.observePoll(headBlock.NumberU64())
+				}
+				m.checkSynced(ctx, m.chain.Head() != nil)
+
 				// reset poll interval as by config
 				pollInterval = m.options.PollingInterval
 				continue
@@ -242,45 +970,104 @@ func (m *Monitor) monitor() error {
 				pollInterval = m.options.PollingInterval // reset poll interval
 				continue
 			}
+			nextBlocks = []*types.Block{nextBlock}
+		}
+		m.observePoll(nextBlocks[len(nextBlocks)-1].NumberU64())
 
-			// speed up the poll interval if we found the next block
-			pollInterval /= 2
+		// speed up the poll interval if we found the next block
+		pollInterval /= 2
 
-			// build deterministic set of add/remove events which construct the canonical chain
+		// build deterministic set of add/remove events which construct the canonical chain
+		var nextBlock *types.Block
+		for _, nextBlock = range nextBlocks {
 			events, err = m.buildCanonicalChain(ctx, nextBlock, events)
 			if err != nil {
-				m.log.Warnf("ethmonitor: error reported '%v', failed to build chain for next blockNum:%d blockHash:%s, retrying..",
-					err, nextBlock.NumberU64(), nextBlock.Hash().Hex())
-
-				// pause, then retry
-				time.Sleep(m.options.PollingInterval)
+				break
+			}
+		}
+		if errors.Is(err, ErrReorg) {
+			return superr.New(ErrFatal, err)
+		}
+		if errors.Is(err, errNoCommonAncestor) {
+			m.restartFromLatest(headBlock, err)
+			events, withheld = Blocks{}, Blocks{}
+			pollInterval = m.options.PollingInterval
+			continue
+		}
+		var gap *chainGapError
+		if errors.As(err, &gap) {
+			reseeded, err := m.handleChainGap(gap)
+			if err != nil {
+				return err
+			}
+			if reseeded {
+				events = Blocks{}
+				pollInterval = m.options.PollingInterval
 				continue
 			}
+		}
+		if err != nil {
+			m.log.Warnf("ethmonitor: error reported '%v', failed to build chain for next blockNum:%d blockHash:%s, retrying..",
+				err, nextBlock.NumberU64(), nextBlock.Hash().Hex())
 
-			if m.options.WithLogs {
-				m.addLogs(ctx, events)
-				m.backfillChainLogs(ctx)
-			} else {
-				for _, b := range events {
-					b.Logs = nil // nil it out to be clear to subscribers
-					b.OK = true
-				}
-			}
+			// pause, then retry
+			time.Sleep(m.options.PollingInterval)
+			continue
+		}
 
-			// publish events
-			err = m.publish(ctx, events)
-			if err != nil {
-				// failing to publish is considered a rare, but fatal error.
-				// the only time this happens is if we fail to push an event to the publish queue.
+		m.chain.updateReorgAncestor(events)
+		m.reportReorg(events)
+		m.recordReorg(events)
+		m.metrics.observeEvents(events)
+
+		m.addBlockData(ctx, events)
+
+		// update the finalized block for the new head
+		m.updateFinality(ctx)
+
+		// cross-check the chain against a second source before publishing
+		err = m.crossCheck(ctx)
+		if err != nil {
+			if m.options.DivergencePolicy != DivergencePause {
 				return superr.New(ErrFatal, err)
 			}
-
-			// clear events sink
+			withheld = append(withheld, events...)
 			events = Blocks{}
+			m.Pause()
+			continue
 		}
+		if len(withheld) > 0 {
+			events = append(withheld, events...)
+			withheld = Blocks{}
+		}
+		if m.options.PublishFinalized {
+			events = append(events, m.finalizedEvents(events)...)
+		}
+
+		// publish events
+		err = m.publish(ctx, events)
+		if err != nil {
+			// failing to publish is considered a rare, but fatal error.
+			// the only time this happens is if we fail to push an event to the publish queue.
+			return superr.New(ErrFatal, err)
+		}
+
+		m.checkSynced(ctx, false)
+
+		// clear events sink
+		events = Blocks{}
 	}
 }
 
+// maxReorgPauseSteps bounds the pause taken for every block popped during a reorg, to
+// maxReorgPauseSteps * ReorgRecoveryPause.
+const maxReorgPauseSteps = 5
+
+// buildCanonicalChain adds the next block to the canonical chain. When the next block
+// doesn't link to the head of the chain, ie. on a reorg, the head is popped and the
+// parent of the next block is fetched, until the fetched blocks link back to the chain.
+// The fetched blocks are then pushed in order, so the events are the popped blocks from
+// the head down, followed by the added blocks from the common ancestor up.
 func (m *Monitor) buildCanonicalChain(ctx context.Context, nextBlock *types.Block, events Blocks) (Blocks, error) {
 	select {
 	case <-ctx.Done():
@@ -288,55 +1075,95 @@ func (m *Monitor) buildCanonicalChain(ctx context.Context, nextBlock *types.Bloc
 	default:
 	}
 
-	headBlock := m.chain.Head()
-
 	m.log.Debugf("ethmonitor: new block #%d hash:%s prevHash:%s numTxns:%d",
 		nextBlock.NumberU64(), nextBlock.Hash().String(), nextBlock.ParentHash().String(), len(nextBlock.Transactions()))
 
-	if headBlock == nil || nextBlock.ParentHash() == headBlock.Hash() {
-		// block-chaining it up
-		block := &Block{Event: Added, Block: nextBlock}
-		events = append(events, block)
-		return events, m.chain.push(block)
+	if err := m.validateBlock(nextBlock); err != nil {
+		return events, err
 	}
 
-	// next block doest match prevHash, therefore we must pop our previous block and recursively
-	// rebuild the canonical chain
-	poppedBlock := *m.chain.pop() // assign by value so it won't be mutated later
-	poppedBlock.Event = Removed
-	poppedBlock.OK = true // removed blocks are ready
+	// pending is the stack of blocks to push, with the deepest block on top
+	pending := []*types.Block{nextBlock}
 
-	m.log.Debugf("ethmonitor: block reorg, reverting block #%d hash:%s prevHash:%s", poppedBlock.NumberU64(), poppedBlock.Hash().Hex(), poppedBlock.ParentHash().Hex())
-	events = append(events, &poppedBlock)
+	for {
+		block := pending[len(pending)-1]
+		headBlock := m.chain.Head()
 
-	// let's always take a pause between any reorg for the polling interval time
-	// to allow nodes to sync to the correct chain
-	pause := m.options.PollingInterval * time.Duration(len(events))
-	time.Sleep(pause)
+		if headBlock == nil || m.chain.blockLinkFunc(headBlock.Block, block) {
+			break
+		}
 
-	// Fetch/connect the broken chain backwards by traversing recursively via parent hashes
-	nextParentBlock, err := m.fetchBlockByHash(ctx, nextBlock.ParentHash())
-	if err != nil {
-		// NOTE: this is okay, it will auto-retry
-		return events, err
-	}
+		if m.options.MaxReorgDepth > 0 {
+			depth, lastHead := reorgDepth(events, headBlock)
+			if depth >= m.options.MaxReorgDepth {
+				return events, superr.New(ErrReorg, fmt.Errorf("reorg depth exceeds max of %d blocks, last canonical head was #%d hash:%s",
+					m.options.MaxReorgDepth, lastHead.NumberU64(), lastHead.Hash().Hex()))
+			}
+		}
 
-	events, err = m.buildCanonicalChain(ctx, nextParentBlock, events)
-	if err != nil {
-		// NOTE: this is okay, it will auto-retry
-		return events, err
+		// the last retained block doesn't link either, in which case the parent of its
+		// replacement is checked below, as the common ancestor may not be retained
+		isTail := headBlock == m.chain.Tail()
+
+		// block doest match prevHash, therefore we must pop our previous block and
+		// continue with its parent to rebuild the canonical chain
+		poppedBlock := *m.chain.pop() // assign by value so it won't be mutated later
+		poppedBlock.Event = Removed
+		poppedBlock.OK = true // removed blocks are ready
+
+		m.log.Debugf("ethmonitor: block reorg, reverting block #%d hash:%s prevHash:%s", poppedBlock.NumberU64(), poppedBlock.Hash().Hex(), poppedBlock.ParentHash().Hex())
+		events = append(events, &poppedBlock)
+
+		// let's always take a pause between any reorg for the polling interval time
+		// to allow nodes to sync to the correct chain
+		time.Sleep(m.reorgPause(len(events)))
+
+		// Fetch/connect the broken chain backwards by traversing via parent hashes
+		parentBlock, err := m.fetchBlockByHash(ctx, block.ParentHash())
+		if err == ethereum.NotFound {
+			// the node keeps missing the parent, ie. it was pruned
+			return events, m.chainGap(block)
+		}
+		if err != nil {
+			// NOTE: this is okay, it will auto-retry
+			return events, err
+		}
+		if err := m.validateBlock(parentBlock); err != nil {
+			return events, err
+		}
+		if isTail && parentBlock.ParentHash() != poppedBlock.ParentHash() {
+			return events, fmt.Errorf("%w down to block #%d hash:%s", errNoCommonAncestor, poppedBlock.NumberU64(), poppedBlock.Hash().Hex())
+		}
+		pending = append(pending, parentBlock)
 	}
 
-	block := &Block{Event: Added, Block: nextBlock}
-	err = m.chain.push(block)
-	if err != nil {
-		return events, err
+	for i := len(pending) - 1; i >= 0; i-- {
+		m.validateTimestamp(m.chain.Head(), pending[i])
+		block := &Block{Event: Added, Block: pending[i]}
+		err := m.chain.push(block)
+		if err != nil {
+			// NOTE: this is okay, it will auto-retry
+			return events, err
+		}
+		events = append(events, block)
 	}
-	events = append(events, block)
 
 	return events, nil
 }
 
+// reorgPause is the pause taken after popping a block during a reorg, which grows with
+// the number of events up to maxReorgPauseSteps times the ReorgRecoveryPause.
+func (m *Monitor) reorgPause(numEvents int) time.Duration {
+	if m.replay != nil {
+		// replayed blocks are all available already
+		return 0
+	}
+	if numEvents > maxReorgPauseSteps {
+		numEvents = maxReorgPauseSteps
+	}
+	return m.options.ReorgRecoveryPause * time.Duration(numEvents)
+}
+
 func (m *Monitor) addLogs(ctx context.Context, blocks Blocks) {
 	tctx, cancel := context.WithTimeout(ctx, m.options.Timeout)
 	defer cancel()
@@ -360,28 +1187,24 @@ func (m *Monitor) addLogs(ctx context.Context, blocks Blocks) {
 			continue
 		}
 
-		blockHash := block.Hash()
-
-		topics := [][]common.Hash{}
-		if len(m.options.LogTopics) > 0 {
-			topics = append(topics, m.options.LogTopics)
+		// the logsBloom of the block proves it has no logs matching the filters
+		if m.skipLogsByBloom(block.Bloom()) {
+			block.Logs = []types.Log{}
+			block.OK = true
+			continue
 		}
 
-		logs, err := m.provider.FilterLogs(tctx, ethereum.FilterQuery{
-			BlockHash: &blockHash,
-			Topics:    topics,
-		})
+		blockHash := block.Hash()
+
+		logs, fromReceipts, err := m.fetchLogs(tctx, block)
 
 		if err == nil {
 			// check the logsBloom from the block to check if we should be expecting logs. logsBloom
-			// will be included for any indexed logs.
-			if len(logs) > 0 || block.Bloom() == (types.Bloom{}) {
+			// will be included for any indexed logs. The logs derived from the receipts of the block
+			// are complete, as the receipts are of every transaction of the block.
+			if len(logs) > 0 || fromReceipts || !m.bloomMatchesLogFilter(block.Bloom()) {
 				// successful backfill
-				if logs == nil {
-					block.Logs = []types.Log{}
-				} else {
-					block.Logs = logs
-				}
+				block.Logs = normalizeLogs(logs)
 				block.OK = true
 				continue
 			}
@@ -394,6 +1217,169 @@ func (m *Monitor) addLogs(ctx context.Context, blocks Blocks) {
 		// NOTE: we do not error here as these logs will be backfilled before they are published anyways,
 		// but we log the error anyways.
 		m.log.Infof("ethmonitor: [getLogs failed -- marking block %s for log backfilling] %v", blockHash.Hex(), err)
+		m.metrics.getLogsFailed()
+	}
+}
+
+// fetchLogs fetches the logs of the block matching the log filters, and reports if they
+// were derived from the receipts of the block, see Options.UseBlockReceipts.
+func (m *Monitor) fetchLogs(ctx context.Context, block *Block) ([]types.Log, bool, error) {
+	if p, ok := m.provider.(blockReceiptsProvider); ok && m.options.UseBlockReceipts && atomic.LoadInt32(&m.blockReceiptsUnsupported) == 0 {
+		receipts, err := p.BlockReceipts(ctx, block.Hash())
+		if err == nil {
+			return m.logsFromReceipts(block, receipts)
+		}
+		if !errors.Is(err, ethrpc.ErrMethodUnsupported) {
+			return nil, false, err
+		}
+		if atomic.CompareAndSwapInt32(&m.blockReceiptsUnsupported, 0, 1) {
+			m.log.Warnf("ethmonitor: eth_getBlockReceipts is not supported by the node, fetching logs with eth_getLogs")
+		}
+	}
+
+	blockHash := block.Hash()
+	logs, err := m.provider.FilterLogs(ctx, ethereum.FilterQuery{
+		BlockHash: &blockHash,
+		Addresses: m.options.LogAddresses,
+		Topics:    m.logTopics(),
+	})
+	return logs, false, err
+}
+
+// logsFromReceipts returns the logs of the receipts of the block matching the log filters,
+// and keeps the receipts with the block when WithReceipts is set.
+func (m *Monitor) logsFromReceipts(block *Block, receipts []*types.Receipt) ([]types.Log, bool, error) {
+	// a node which has yet to index the block may return no receipts
+	if len(receipts) == 0 && block.Bloom() != (types.Bloom{}) {
+		return nil, false, fmt.Errorf("no receipts for block %s with a non-empty logsBloom", block.Hash().Hex())
+	}
+
+	logs := []types.Log{}
+	for _, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			if m.logFilter(log) {
+				logs = append(logs, *log)
+			}
+		}
+	}
+
+	if m.options.WithReceipts && block.receipts == nil && len(receipts) == len(block.Transactions()) {
+		// the block may be retained in the chain, where receipts are read under its lock
+		m.chain.mu.Lock()
+		block.receipts = receipts
+		m.chain.mu.Unlock()
+	}
+	return logs, true, nil
+}
+
+// normalizeLogs sorts the logs of a block by their log index, and drops the duplicate
+// logs returned by some nodes and rpc proxies, ie. when merging the results of retries.
+func normalizeLogs(logs []types.Log) []types.Log {
+	out := make([]types.Log, 0, len(logs))
+	out = append(out, logs...)
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Index < out[j].Index
+	})
+
+	n := 0
+	for i := range out {
+		if n > 0 && out[i].Index == out[n-1].Index && out[i].TxHash == out[n-1].TxHash {
+			continue
+		}
+		out[n] = out[i]
+		n++
+	}
+	return out[:n]
+}
+
+// bloomMatchesLogFilter returns true if the logsBloom of a block indicates it may contain
+// logs matching the LogAddresses and topic filters, in which case an empty result
+// from the node is unexpected.
+func (m *Monitor) bloomMatchesLogFilter(bloom types.Bloom) bool {
+	if bloom == (types.Bloom{}) {
+		return false
+	}
+
+	if len(m.options.LogAddresses) > 0 {
+		match := false
+		for _, addr := range m.options.LogAddresses {
+			if types.BloomLookup(bloom, addr) {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+
+	for _, group := range m.logTopics() {
+		if len(group) == 0 {
+			continue
+		}
+		match := false
+		for _, topic := range group {
+			if types.BloomLookup(bloom, topic) {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+
+	return true
+}
+
+// skipLogsByBloom returns true if the logsBloom of a block proves it has no logs matching
+// the LogAddresses and topic filters, in which case fetching its logs can be skipped. An
+// empty bloom proves nothing, as it may just be missing, ie. from a proxy.
+func (m *Monitor) skipLogsByBloom(bloom types.Bloom) bool {
+	if m.options.DisableLogsBloomCheck || bloom == (types.Bloom{}) {
+		return false
+	}
+
+	filtered := len(m.options.LogAddresses) > 0
+	for _, group := range m.logTopics() {
+		if len(group) > 0 {
+			filtered = true
+		}
+	}
+	return filtered && !m.bloomMatchesLogFilter(bloom)
+}
+
+// logTopics returns the topics filter of the log query, from either LogTopics or
+// LogTopicGroups.
+func (m *Monitor) logTopics() [][]common.Hash {
+	if len(m.options.LogTopicGroups) > 0 {
+		return m.options.LogTopicGroups
+	}
+	if len(m.options.LogTopics) > 0 {
+		return [][]common.Hash{m.options.LogTopics}
+	}
+	return [][]common.Hash{}
+}
+
+// addBlockData adds the logs and receipts to the blocks of the events as set by the options,
+// and backfills those which failed before across the retained chain.
+func (m *Monitor) addBlockData(ctx context.Context, events Blocks) {
+	if m.options.WithLogs {
+		m.addLogs(ctx, events)
+	} else {
+		for _, b := range events {
+			b.Logs = nil // nil it out to be clear to subscribers
+			b.OK = true
+		}
+	}
+	if m.options.WithReceipts {
+		m.addReceipts(ctx, events)
+	}
+	if m.options.WithLogs || m.options.WithReceipts {
+		m.backfillChainLogs(ctx)
+	}
+	if m.options.WithLogs {
+		m.updateLogIndex(events)
 	}
 }
 
@@ -408,26 +1394,108 @@ func (m *Monitor) backfillChainLogs(ctx context.Context) {
 	//
 	// NOTE: we only back-fill 'Added' blocks, as any 'Removed' blocks could be reverted
 	// and their logs will never be available from a node.
+	//
+	// The blocks are backfilled concurrently, with at most MaxConcurrentFetches blocks in
+	// flight. Every block is only updated by a single worker, and the backfill completes
+	// before the events are published, so the publish order is unaffected.
 	blocks := m.chain.Blocks()
 
+	concurrency := m.options.MaxConcurrentFetches
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
 	for i := len(blocks) - 1; i >= 0; i-- {
+		if blocks[i].OK {
+			continue
+		}
+
 		select {
 		case <-ctx.Done():
+			wg.Wait()
 			return
-		default:
+		case sem <- struct{}{}:
 		}
 
-		if !blocks[i].OK {
-			m.addLogs(ctx, Blocks{blocks[i]})
-			if blocks[i].Event == Added && blocks[i].OK {
-				m.log.Infof("ethmonitor: [getLogs backfill successful for block:%d %s]", blocks[i].NumberU64(), blocks[i].Hash().Hex())
-			}
+		wg.Add(1)
+		go func(block *Block) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			m.backfillBlock(ctx, block)
+		}(blocks[i])
+	}
+	wg.Wait()
+}
+
+// backfillBlock fetches the logs and receipts of a retained block which are missing.
+func (m *Monitor) backfillBlock(ctx context.Context, block *Block) {
+	if m.options.WithLogs {
+		m.addLogs(ctx, Blocks{block})
+	} else {
+		block.OK = true
+	}
+	if m.options.WithReceipts {
+		m.addReceipts(ctx, Blocks{block})
+	}
+	if block.Event == Added && block.OK {
+		m.log.Infof("ethmonitor: [getLogs backfill successful for block:%d %s]", block.NumberU64(), block.Hash().Hex())
+		m.metrics.getLogsBackfilled()
+		if m.logIndex != nil {
+			m.logIndex.add(block)
 		}
 	}
 }
 
+// checkChainID checks the chain id of the provider is Options.ExpectedChainID.
+func (m *Monitor) checkChainID(ctx context.Context) error {
+	tctx, cancel := context.WithTimeout(ctx, m.options.Timeout)
+	defer cancel()
+
+	chainID, err := m.provider.(chainIDProvider).ChainID(tctx)
+	if err != nil {
+		return fmt.Errorf("ethmonitor: failed to fetch chain id: %w", err)
+	}
+	if chainID.Cmp(m.options.ExpectedChainID) != 0 {
+		return superr.New(ErrChainIDMismatch, fmt.Errorf("provider chain id is %s, expected %s", chainID, m.options.ExpectedChainID))
+	}
+	return nil
+}
+
+// seedStartBlock fetches the block of Options.StartBlockHash, verifies it's on the canonical
+// chain of the provider, and pushes it as the head of our chain.
+func (m *Monitor) seedStartBlock(ctx context.Context, hash common.Hash) error {
+	block, err := m.fetchBlockByHash(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return superr.New(ErrStartBlockNotFound, fmt.Errorf("block hash %s not found", hash.Hex()))
+	}
+	if err != nil {
+		return fmt.Errorf("ethmonitor: failed to fetch start block %s: %w", hash.Hex(), err)
+	}
+
+	startNum := m.options.StartBlockNumber
+	if startNum != nil && startNum.Sign() >= 0 && startNum.Cmp(block.Number()) != 0 {
+		return superr.New(ErrStartBlockNotFound, fmt.Errorf("block hash %s is block #%d, not StartBlockNumber #%d", hash.Hex(), block.NumberU64(), startNum))
+	}
+
+	// the node may still serve a block by hash after it was reorged out of its chain
+	canonical, err := m.fetchBlockByNumber(ctx, block.Number())
+	if err != nil {
+		return fmt.Errorf("ethmonitor: failed to fetch start block #%d: %w", block.NumberU64(), err)
+	}
+	if canonical.Hash() != hash {
+		return superr.New(ErrStartBlockNotFound, fmt.Errorf("block hash %s was forked, block #%d of the canonical chain is %s", hash.Hex(), block.NumberU64(), canonical.Hash().Hex()))
+	}
+
+	m.log.Infof("ethmonitor: seeding chain with start block #%d hash:%s", block.NumberU64(), hash.Hex())
+	return m.chain.push(&Block{Event: Added, Block: block, OK: true})
+}
+
 func (m *Monitor) fetchBlockByNumber(ctx context.Context, num *big.Int) (*types.Block, error) {
-	maxErrAttempts, errAttempts := 10, 0 // in case of node connection failures
+	maxErrAttempts, errAttempts := m.options.RetryPolicy.MaxAttempts(), 0 // in case of node connection failures
 
 	var block *types.Block
 	var err error
@@ -447,24 +1515,31 @@ func (m *Monitor) fetchBlockByNumber(ctx context.Context, num *big.Int) (*types.
 		tctx, cancel := context.WithTimeout(ctx, m.options.Timeout)
 		defer cancel()
 
-		block, err = m.provider.BlockByNumber(tctx, num)
+		block, err = m.blockByNumber(tctx, num)
 		if err != nil {
 			if err == ethereum.NotFound {
 				return nil, ethereum.NotFound
+			} else if errors.Is(err, ethrpc.ErrMissingBlockHeader) {
+				m.log.Errorf("ethmonitor: provider returned a block without a number for block num %v: %v", num, err)
+				return nil, superr.New(ErrMissingBlockNumber, fmt.Errorf("block num %v: %w", num, err))
 			} else {
 				m.log.Warnf("ethmonitor: fetchBlockByNumber failed due to: %v", err)
 				errAttempts++
-				time.Sleep(m.options.PollingInterval * time.Duration(errAttempts) * 2)
+				m.retryBackoff(ctx, errAttempts)
 				continue
 			}
 		}
+		if num == nil && block != nil {
+			// the latest block is the tip of the node
+			m.observeRemoteHead(block.NumberU64())
+		}
 		return block, nil
 	}
 }
 
 func (m *Monitor) fetchBlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
-	maxNotFoundAttempts, notFoundAttempts := 4, 0 // waiting for node to sync
-	maxErrAttempts, errAttempts := 10, 0          // in case of node connection failures
+	maxNotFoundAttempts, notFoundAttempts := 4, 0                         // waiting for node to sync
+	maxErrAttempts, errAttempts := m.options.RetryPolicy.MaxAttempts(), 0 // in case of node connection failures
 
 	var block *types.Block
 	var err error
@@ -484,15 +1559,18 @@ func (m *Monitor) fetchBlockByHash(ctx context.Context, hash common.Hash) (*type
 			return nil, superr.New(ErrMaxAttempts, err)
 		}
 
-		block, err = m.provider.BlockByHash(ctx, hash)
+		block, err = m.blockByHash(ctx, hash)
 		if err != nil {
 			if err == ethereum.NotFound {
 				notFoundAttempts++
-				time.Sleep(m.options.PollingInterval * time.Duration(notFoundAttempts) * 2)
+				m.retryBackoff(ctx, notFoundAttempts)
 				continue
+			} else if errors.Is(err, ethrpc.ErrMissingBlockHeader) {
+				m.log.Errorf("ethmonitor: provider returned a block without a number for block hash %s: %v", hash.Hex(), err)
+				return nil, superr.New(ErrMissingBlockNumber, fmt.Errorf("block hash %s: %w", hash.Hex(), err))
 			} else {
 				errAttempts++
-				time.Sleep(m.options.PollingInterval * time.Duration(errAttempts) * 2)
+				m.retryBackoff(ctx, errAttempts)
 				continue
 			}
 		}
@@ -502,60 +1580,229 @@ func (m *Monitor) fetchBlockByHash(ctx context.Context, hash common.Hash) (*type
 	}
 }
 
+// blockByNumber fetches the block from the provider, or only its header in HeadersOnly mode.
+func (m *Monitor) blockByNumber(ctx context.Context, num *big.Int) (*types.Block, error) {
+	if m.replay != nil {
+		return m.replay.BlockByNumber(ctx, num)
+	}
+	if m.options.HeadersOnly {
+		return m.provider.(blockHeaderProvider).BlockHeaderByNumber(ctx, num)
+	}
+	return m.provider.BlockByNumber(ctx, num)
+}
+
+// blockByHash fetches the block from the provider, or only its header in HeadersOnly mode.
+func (m *Monitor) blockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	if m.replay != nil {
+		return m.replay.BlockByHash(ctx, hash)
+	}
+	if m.options.HeadersOnly {
+		return m.provider.(blockHeaderProvider).BlockHeaderByHash(ctx, hash)
+	}
+	return m.provider.BlockByHash(ctx, hash)
+}
+
 func (m *Monitor) publish(ctx context.Context, events Blocks) error {
 	// Check for trail-behind-head mode and set maxBlockNum if applicable
-	maxBlockNum := uint64(0)
-	if m.options.TrailNumBlocksBehindHead > 0 {
-		maxBlockNum = m.LatestBlock().NumberU64() - uint64(m.options.TrailNumBlocksBehindHead)
-	}
+	maxBlockNum, hold := m.trailingBlockNum()
+	atomic.StoreUint64(&m.trailMaxBlockNum, maxBlockNum)
 
 	// Enqueue
 	err := m.publishQueue.enqueue(events)
 	if err != nil {
-		return err
+		if m.options.BackpressureMode == BackpressureDropTrailing && errors.Is(err, ErrQueueFull) {
+			dropped := m.publishQueue.dropOldest()
+			m.log.Warnf("ethmonitor: publish queue is full, dropped %d oldest events", dropped)
+		} else {
+			return err
+		}
 	}
 
-	// Publish events existing in the queue
-	pubEvents, ok := m.publishQueue.dequeue(maxBlockNum)
+	// Publish events existing in the queue, unless trailing holds back all of them
+	var pubEvents Blocks
+	var ok bool
+	if !hold {
+		pubEvents, ok = m.publishQueue.dequeue(maxBlockNum)
+	}
 	if ok {
+		if m.options.DecodeTokenTransfers {
+			for _, b := range pubEvents {
+				if b.Event == Added && b.TokenTransfers == nil {
+					b.TokenTransfers = DecodeTokenTransfers(b.Logs)
+				}
+			}
+		}
+		m.notifyNewHeads(pubEvents)
 		m.publishCh <- pubEvents
 	}
+	m.metrics.setPublishQueueLen(m.publishQueue.len())
+
+	if m.options.DebugLogging && maxBlockNum > 0 {
+		m.log.Debugf("ethmonitor: trailing head, holding back %d events above block %d", m.publishQueue.countAbove(maxBlockNum), maxBlockNum)
+	}
 
 	return nil
 }
 
+// notifyNewHeads passes the added blocks of the events to the Options.OnNewHead hook.
+func (m *Monitor) notifyNewHeads(events Blocks) {
+	if m.options.OnNewHead == nil {
+		return
+	}
+	for _, block := range events {
+		if block.Event == Added {
+			m.options.OnNewHead(block)
+		}
+	}
+}
+
+// trailingBlockNum returns the highest block number which may be published when trailing
+// behind the head with TrailNumBlocksBehindHead or TrailToSafeBlock, or 0 when not trailing.
+// hold is true when no block may be published yet, ie. the safe block is not known yet.
+func (m *Monitor) trailingBlockNum() (maxBlockNum uint64, hold bool) {
+	switch {
+	case m.options.TrailToSafeBlock:
+		safe := m.SafeBlockNum()
+		if safe == nil || safe.Sign() == 0 {
+			return 0, true
+		}
+		return safe.Uint64(), false
+
+	case m.options.TrailNumBlocksBehindHead > 0:
+		return m.LatestBlock().NumberU64() - uint64(m.options.TrailNumBlocksBehindHead), false
+
+	default:
+		return 0, false
+	}
+}
+
+// backpressureThreshold is the publish queue length at which the monitor
+// stops fetching new blocks in BackpressureBlock mode.
+func (m *Monitor) backpressureThreshold() int {
+	return m.publishQueue.cap * 9 / 10
+}
+
+// broadcast sends the events to the subscribers. Broadcasts are serialized, so every
+// subscriber observes the events in order, but the events are sent without m.mu held, as
+// a subscriber waited on under the SubscriberBlock policy may call the monitor's getters.
 func (m *Monitor) broadcast(events Blocks) {
+	m.broadcastMu.Lock()
+	defer m.broadcastMu.Unlock()
+
+	m.mu.Lock()
+	m.recordPublished(events)
+	subscribers := append([]*subscriber{}, m.subscribers...)
+	m.mu.Unlock()
+
+	var disconnected []*subscriber
+	for _, sub := range subscribers {
+		ok := true
+		if sub.filter == nil {
+			ok = sub.ch.send(events)
+		} else if filtered := filterBlocks(events, sub.filter, sub.dropEmpty); len(filtered) > 0 {
+			ok = sub.ch.send(filtered)
+		}
+
+		if !ok {
+			atomic.AddUint64(&m.subscriberOverflows, 1)
+			m.metrics.subscriberOverflowed()
+
+			if m.options.SubscriberOverflowPolicy == SubscriberDisconnect {
+				m.log.Warnf("ethmonitor: subscriber is more than %d events behind, disconnecting it", m.options.SubscriberBufferLimit)
+				sub.ch.close()
+				disconnected = append(disconnected, sub)
+			}
+		}
+	}
+	if len(disconnected) == 0 {
+		return
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	remaining := m.subscribers[:0]
 	for _, sub := range m.subscribers {
-		sub.ch.Send(events)
+		if !containsSubscriber(disconnected, sub) {
+			remaining = append(remaining, sub)
+		}
+	}
+	for i := len(remaining); i < len(m.subscribers); i++ {
+		m.subscribers[i] = nil
 	}
+	m.subscribers = remaining
+}
+
+func containsSubscriber(subscribers []*subscriber, sub *subscriber) bool {
+	for _, s := range subscribers {
+		if s == sub {
+			return true
+		}
+	}
+	return false
+}
+
+// SubscriberOverflowCount returns the number of times events were lost to a subscriber
+// falling behind by more than Options.SubscriberBufferLimit events, ie. its oldest events
+// were dropped or it was disconnected, see Options.SubscriberOverflowPolicy. Waiting on a
+// slow subscriber under the SubscriberBlock policy is not counted.
+func (m *Monitor) SubscriberOverflowCount() uint64 {
+	return atomic.LoadUint64(&m.subscriberOverflows)
 }
 
 func (m *Monitor) Subscribe() Subscription {
+	return m.subscribe(nil, false)
+}
+
+// SubscribeWithContext subscribes to the monitor, the same as Subscribe, and automatically
+// unsubscribes once the context is done. Calling Unsubscribe explicitly is still allowed.
+func (m *Monitor) SubscribeWithContext(ctx context.Context) Subscription {
+	subscriber := m.subscribe(nil, false).(*subscriber)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			subscriber.Unsubscribe()
+		case <-subscriber.done:
+		}
+	}()
+
+	return subscriber
+}
+
+func (m *Monitor) subscribe(filter logMatcher, dropEmpty bool) Subscription {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	return m.newSubscriber(filter, dropEmpty)
+}
 
+// newSubscriber adds a subscriber to the monitor. It must be called with m.mu held.
+func (m *Monitor) newSubscriber(filter logMatcher, dropEmpty bool) *subscriber {
 	subscriber := &subscriber{
-		ch:   channel.NewUnboundedChan[Blocks](m.log, 100, 5000),
-		done: make(chan struct{}),
+		ch:        newSubscriberChan[Blocks](m.log, m.options.SubscriberBufferLimit, m.options.SubscriberOverflowPolicy),
+		done:      make(chan struct{}),
+		filter:    filter,
+		dropEmpty: dropEmpty,
 	}
 
+	// unsubscribing is idempotent, as Unsubscribe may be called more than once, ie. from
+	// multiple goroutines
+	var once sync.Once
 	subscriber.unsubscribe = func() {
-		close(subscriber.done)
-		subscriber.ch.Close()
-		subscriber.ch.Flush()
+		once.Do(func() {
+			close(subscriber.done)
+			subscriber.ch.close()
 
-		m.mu.Lock()
-		defer m.mu.Unlock()
+			m.mu.Lock()
+			defer m.mu.Unlock()
 
-		for i, sub := range m.subscribers {
-			if sub == subscriber {
-				m.subscribers = append(m.subscribers[:i], m.subscribers[i+1:]...)
-				return
+			for i, sub := range m.subscribers {
+				if sub == subscriber {
+					m.subscribers = append(m.subscribers[:i], m.subscribers[i+1:]...)
+					return
+				}
 			}
-		}
+		})
 	}
 
 	m.subscribers = append(m.subscribers, subscriber)
@@ -563,6 +1810,91 @@ func (m *Monitor) Subscribe() Subscription {
 	return subscriber
 }
 
+// WaitForBlocks subscribes to the monitor and waits for the next n Added blocks, which are
+// returned in order. Removed blocks are ignored, unless `optRemoveReorged` is true, in which
+// case blocks that were reorged away are dropped from the result and waited for again, so
+// the result only holds blocks on the canonical chain. If the context is cancelled, the
+// blocks collected so far are returned along with the context error.
+func (m *Monitor) WaitForBlocks(ctx context.Context, n int, optRemoveReorged ...bool) (Blocks, error) {
+	removeReorged := len(optRemoveReorged) > 0 && optRemoveReorged[0]
+
+	sub := m.Subscribe()
+	defer sub.Unsubscribe()
+
+	blocks := make(Blocks, 0, n)
+	for len(blocks) < n {
+		select {
+		case <-ctx.Done():
+			return blocks, ctx.Err()
+
+		case <-sub.Done():
+			return blocks, fmt.Errorf("ethmonitor: subscription closed")
+
+		case events := <-sub.Blocks():
+			for _, ev := range events {
+				if ev.Event == Added {
+					blocks = append(blocks, ev)
+				} else if ev.Event == Removed && removeReorged {
+					for i := len(blocks) - 1; i >= 0; i-- {
+						if blocks[i].Hash() == ev.Hash() {
+							blocks = append(blocks[:i], blocks[i+1:]...)
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+	return blocks[:n], nil
+}
+
+// Ticks returns a channel which receives the time of every poll iteration of the monitor,
+// regardless if a new block was found or not. This is purely informational, ie. for showing
+// a "last checked" time. The channel is only created once Ticks is called, and ticks are
+// dropped when the reader is not keeping up.
+func (m *Monitor) Ticks() <-chan time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ticks == nil {
+		m.ticks = make(chan time.Time, 1)
+	}
+	return m.ticks
+}
+
+func (m *Monitor) tick() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.ticks == nil {
+		return
+	}
+	select {
+	case m.ticks <- time.Now():
+	default:
+	}
+}
+
+// TrailMaxBlockNum returns the highest block number which may currently be published to
+// subscribers when TrailNumBlocksBehindHead or TrailToSafeBlock is set, or 0 otherwise.
+func (m *Monitor) TrailMaxBlockNum() uint64 {
+	return atomic.LoadUint64(&m.trailMaxBlockNum)
+}
+
+// TrailedBlockCount returns the number of events in the publish queue which are held
+// back from subscribers because they are within TrailNumBlocksBehindHead of the head, or
+// above the safe block with TrailToSafeBlock.
+func (m *Monitor) TrailedBlockCount() int {
+	if m.options.TrailToSafeBlock {
+		if _, hold := m.trailingBlockNum(); hold {
+			return m.publishQueue.len()
+		}
+	}
+	maxBlockNum := m.TrailMaxBlockNum()
+	if maxBlockNum == 0 {
+		return 0
+	}
+	return m.publishQueue.countAbove(maxBlockNum)
+}
+
 func (m *Monitor) Chain() *Chain {
 	return m.chain
 }
@@ -617,10 +1949,63 @@ func (m *Monitor) GetBlock(blockHash common.Hash) *Block {
 	return m.chain.GetBlock(blockHash)
 }
 
+// GetBlockByNumber returns the block of the retained canonical chain at the block number,
+// or nil if the number is outside of the retained blocks.
+func (m *Monitor) GetBlockByNumber(blockNum *big.Int) *Block {
+	if blockNum == nil || blockNum.Sign() < 0 || !blockNum.IsUint64() {
+		return nil
+	}
+	return m.chain.GetBlockByNumber(blockNum.Uint64(), Added)
+}
+
+// RetainedBlockRange returns the numbers of the oldest and newest blocks retained on the
+// canonical chain, or nil if no blocks are retained yet. Along with EvictedBlockCount, it
+// helps confirm the BlockRetentionLimit covers the reorg depths and finality window relied
+// on, ie. via FinalizedBlock.
+func (m *Monitor) RetainedBlockRange() (oldest, newest *big.Int) {
+	m.chain.mu.Lock()
+	defer m.chain.mu.Unlock()
+	if len(m.chain.blocks) == 0 {
+		return nil, nil
+	}
+	return m.chain.blocks.Tail().Number(), m.chain.blocks.Head().Number()
+}
+
+// EvictedBlockCount returns the number of blocks evicted from the retained canonical chain
+// by the BlockRetentionLimit, see Chain.EvictedCount.
+func (m *Monitor) EvictedBlockCount() uint64 {
+	return m.chain.EvictedCount()
+}
+
+// LastReorgAncestor returns the last block common to both the old and the new canonical
+// chain of the last reorg, which along with the removed and added blocks of the reorg is
+// the precise rollback point for indexers, see Chain.LastReorgAncestor.
+func (m *Monitor) LastReorgAncestor() (*Block, bool) {
+	return m.chain.LastReorgAncestor()
+}
+
 // GetBlock will search within the retained canonical chain for the txn hash. Passing `optMined true`
-// will only return transaction which have not been removed from the chain via a reorg.
+// will only return transaction which have not been removed from the chain via a reorg. Returns nil
+// in Options.HeadersOnly mode, as the blocks are fetched without their transactions.
 func (m *Monitor) GetTransaction(txnHash common.Hash) *types.Transaction {
-	return m.chain.GetTransaction(txnHash)
+	txn, _, _ := m.GetTransactionWithBlock(txnHash)
+	return txn
+}
+
+// GetTransactionWithBlock searches the retained canonical chain for the txn hash, and returns
+// the transaction along with the block which contains it, ie. to get its block number and
+// confirmations in one call. Returns false if the transaction is not within the retained
+// chain, which doesn't mean it was never mined, as it may be in a block older than the
+// BlockRetentionLimit.
+func (m *Monitor) GetTransactionWithBlock(txnHash common.Hash) (*types.Transaction, *Block, bool) {
+	return m.chain.GetTransactionWithBlock(txnHash)
+}
+
+// GetReceipt returns the receipt of the transaction from the retained canonical chain, so
+// consumers can look up the receipts of recently published blocks without fetching them
+// again. Only available with Options.WithReceipts, and nil if the transaction is not found.
+func (m *Monitor) GetReceipt(txnHash common.Hash) *types.Receipt {
+	return m.chain.GetReceipt(txnHash)
 }
 
 // GetAverageBlockTime returns the average block time in seconds (including fractions)
@@ -628,6 +2013,12 @@ func (m *Monitor) GetAverageBlockTime() float64 {
 	return m.chain.GetAverageBlockTime()
 }
 
+// GetAverageBlockTimeWindow returns the mean block time in seconds over the most recent n
+// blocks, see Chain.GetAverageBlockTimeWindow.
+func (m *Monitor) GetAverageBlockTimeWindow(n int) float64 {
+	return m.chain.GetAverageBlockTimeWindow(n)
+}
+
 // PurgeHistory clears all but the head of the chain. Useful for tests, but should almost
 // never be used in a normal application.
 func (m *Monitor) PurgeHistory() {
@@ -0,0 +1,260 @@
+package ethmonitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/0xsequence/ethkit/ethcoder"
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/rpc"
+)
+
+// FinalityFunc returns the number of the latest finalized block as of the given head
+// block, ie. by reading the latest checkpoint from an on-chain contract. It is called
+// once for every new head of the canonical chain.
+type FinalityFunc func(ctx context.Context, provider *ethrpc.Provider, head *Block) (uint64, error)
+
+// CheckpointFinalityFunc returns a FinalityFunc which calls a view method on a checkpoint
+// contract at the head block, and expects the method to return the latest finalized
+// block number as a uint256. The methodExpr is in the form of "latestCheckpoint()".
+func CheckpointFinalityFunc(contract common.Address, methodExpr string) FinalityFunc {
+	return func(ctx context.Context, provider *ethrpc.Provider, head *Block) (uint64, error) {
+		calldata, err := ethcoder.AbiEncodeMethodCalldata(methodExpr, nil)
+		if err != nil {
+			return 0, err
+		}
+
+		out, err := provider.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: calldata}, head.Number())
+		if err != nil {
+			return 0, err
+		}
+
+		values, err := ethcoder.AbiDecoderWithReturnedValues([]string{"uint256"}, out)
+		if err != nil {
+			return 0, fmt.Errorf("ethmonitor: failed to decode checkpoint %s: %w", hexutil.Encode(out), err)
+		}
+		blockNum, ok := values[0].(*big.Int)
+		if !ok {
+			return 0, fmt.Errorf("ethmonitor: failed to decode checkpoint %s", hexutil.Encode(out))
+		}
+		if !blockNum.IsUint64() {
+			return 0, fmt.Errorf("ethmonitor: checkpoint block number %s out of range", blockNum)
+		}
+		return blockNum.Uint64(), nil
+	}
+}
+
+// finality is the finalized and safe block numbers computed for a given head.
+type finality struct {
+	head     common.Hash
+	blockNum uint64
+	ok       bool
+
+	// safe block, only set with FinalityTags
+	safeBlockNum uint64
+	safeOK       bool
+}
+
+// updateFinality computes the finalized block number for the current head, using the
+// FinalityFunc or FinalityTags when set, and falling back to NumBlocksToFinality if the
+// call fails, see fallbackFinality. The result is cached until the head changes.
+func (m *Monitor) updateFinality(ctx context.Context) {
+	head := m.chain.Head()
+	if head == nil || (m.options.FinalityFunc == nil && !m.options.FinalityTags) {
+		return
+	}
+
+	m.mu.RLock()
+	cached := m.finality.head == head.Hash()
+	m.mu.RUnlock()
+	if cached {
+		return
+	}
+
+	f := finality{head: head.Hash()}
+
+	tctx, cancel := context.WithTimeout(ctx, m.options.Timeout)
+	defer cancel()
+
+	if m.options.FinalityTags {
+		f.blockNum, f.ok = m.fetchTaggedBlockNum(tctx, head, rpc.FinalizedBlockNumber)
+		f.safeBlockNum, f.safeOK = m.fetchTaggedBlockNum(tctx, head, rpc.SafeBlockNumber)
+	} else {
+		blockNum, err := m.options.FinalityFunc(tctx, m.rpc, head)
+		if err == nil {
+			// a checkpoint can never be ahead of the head we've observed
+			if blockNum > head.NumberU64() {
+				blockNum = head.NumberU64()
+			}
+			f.blockNum, f.ok = blockNum, true
+		} else {
+			m.log.Warnf("ethmonitor: finality func failed for head %d, falling back to %d blocks to finality: %v", head.NumberU64(), m.options.NumBlocksToFinality, err)
+			f.blockNum, f.ok = m.fallbackFinality(head.NumberU64())
+		}
+	}
+
+	m.mu.Lock()
+	m.finality = f
+	m.mu.Unlock()
+}
+
+// fetchTaggedBlockNum fetches the number of the block with the given tag from the node, ie.
+// "finalized" or "safe", falling back to NumBlocksToFinality if the node doesn't support the
+// tag, see fallbackFinality.
+func (m *Monitor) fetchTaggedBlockNum(ctx context.Context, head *Block, tag rpc.BlockNumber) (uint64, bool) {
+	block, err := m.blockByNumber(ctx, big.NewInt(tag.Int64()))
+	if err != nil || block == nil {
+		tagName, _ := tag.MarshalText()
+		m.log.Warnf("ethmonitor: failed to fetch %s block for head %d, falling back to %d blocks to finality: %v", tagName, head.NumberU64(), m.options.NumBlocksToFinality, err)
+		return m.fallbackFinality(head.NumberU64())
+	}
+
+	// the node may be ahead of the head we've observed
+	blockNum := block.NumberU64()
+	if blockNum > head.NumberU64() {
+		blockNum = head.NumberU64()
+	}
+	return blockNum, true
+}
+
+// FinalizedBlock returns the latest finalized block within the retained canonical chain.
+// When Options.FinalityFunc or Options.FinalityTags is set, finality is determined by it,
+// otherwise a block is final once it is Options.NumBlocksToFinality blocks behind the head,
+// and the finalized block is unknown if NumBlocksToFinality is not set. Returns nil if the
+// finalized block is not known yet, or is older than the retained blocks.
+func (m *Monitor) FinalizedBlock() *Block {
+	blockNum := m.FinalizedBlockNum()
+	if blockNum == nil {
+		return nil
+	}
+	return m.chain.GetBlockByNumber(blockNum.Uint64(), Added)
+}
+
+// FinalizedBlockNum returns the latest finalized block number, or nil if it is not known yet.
+// See FinalizedBlock.
+func (m *Monitor) FinalizedBlockNum() *big.Int {
+	if m.options.FinalityFunc == nil && !m.options.FinalityTags {
+		head := m.chain.Head()
+		if head == nil {
+			return nil
+		}
+		blockNum, ok := m.fallbackFinality(head.NumberU64())
+		if !ok {
+			return nil
+		}
+		return big.NewInt(0).SetUint64(blockNum)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if !m.finality.ok {
+		return nil
+	}
+	return big.NewInt(0).SetUint64(m.finality.blockNum)
+}
+
+// SafeBlock returns the latest safe block within the retained canonical chain, as reported
+// by the node's "safe" block tag when Options.FinalityTags is set, which is generally ahead
+// of the finalized block. Without FinalityTags, it is the same as FinalizedBlock. Returns
+// nil if the safe block is not known yet, or is older than the retained blocks.
+func (m *Monitor) SafeBlock() *Block {
+	blockNum := m.SafeBlockNum()
+	if blockNum == nil {
+		return nil
+	}
+	return m.chain.GetBlockByNumber(blockNum.Uint64(), Added)
+}
+
+// SafeBlockNum returns the latest safe block number, or nil if it is not known yet.
+// See SafeBlock.
+func (m *Monitor) SafeBlockNum() *big.Int {
+	if !m.options.FinalityTags {
+		return m.FinalizedBlockNum()
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if !m.finality.safeOK {
+		return nil
+	}
+	return big.NewInt(0).SetUint64(m.finality.safeBlockNum)
+}
+
+// resetFinalizedEvents marks the blocks on the chain before Run as already finalized, as
+// they were not published as Added by this run of the monitor.
+func (m *Monitor) resetFinalizedEvents() {
+	m.finalizedEventNum, m.finalizedEventSet, m.finalizedEventNone = 0, false, false
+	if head := m.chain.Head(); head != nil {
+		m.finalizedEventNum, m.finalizedEventSet = head.NumberU64(), true
+	}
+}
+
+// finalizedEvents returns the Finalized events of the blocks which reached finality since
+// the last call, given the events about to be published, so that a block is never published
+// as Finalized before it's published as Added.
+func (m *Monitor) finalizedEvents(events Blocks) Blocks {
+	for _, block := range events {
+		switch block.Event {
+		case Added:
+			if !m.finalizedEventSet {
+				m.setFinalizedEventsBefore(block.NumberU64())
+			}
+		case Removed:
+			// finality was wrong if a finalized block is reorged, so its replacement
+			// must be published as Finalized again
+			if m.finalizedEventSet && !m.finalizedEventNone && block.NumberU64() <= m.finalizedEventNum {
+				m.setFinalizedEventsBefore(block.NumberU64())
+			}
+		}
+	}
+	if !m.finalizedEventSet {
+		return nil
+	}
+
+	finalizedNum := m.FinalizedBlockNum()
+	if finalizedNum == nil || (!m.finalizedEventNone && finalizedNum.Uint64() <= m.finalizedEventNum) {
+		return nil
+	}
+
+	fromBlockNum := m.finalizedEventNum + 1
+	if m.finalizedEventNone {
+		fromBlockNum = 0
+	}
+
+	finalized := Blocks{}
+	for blockNum := fromBlockNum; blockNum <= finalizedNum.Uint64(); blockNum++ {
+		block := m.chain.GetBlockByNumber(blockNum, Added)
+		if block == nil {
+			// no longer retained
+			continue
+		}
+		finalized = append(finalized, &Block{Block: block.Block, Event: Finalized, OK: true})
+	}
+	m.finalizedEventNum, m.finalizedEventNone = finalizedNum.Uint64(), false
+	return finalized
+}
+
+// setFinalizedEventsBefore marks the blocks before blockNum as published as Finalized,
+// without wrapping around at the genesis block.
+func (m *Monitor) setFinalizedEventsBefore(blockNum uint64) {
+	m.finalizedEventSet = true
+	if blockNum == 0 {
+		m.finalizedEventNum, m.finalizedEventNone = 0, true
+		return
+	}
+	m.finalizedEventNum, m.finalizedEventNone = blockNum-1, false
+}
+
+// fallbackFinality is the finality used when the FinalityFunc or FinalityTags fail, which
+// is NumBlocksToFinality blocks behind the head when set. Otherwise the finalized block is
+// unknown, as the head must never be considered final without a source of finality.
+func (m *Monitor) fallbackFinality(headNum uint64) (uint64, bool) {
+	if m.options.NumBlocksToFinality <= 0 || headNum < uint64(m.options.NumBlocksToFinality) {
+		return 0, false
+	}
+	return headNum - uint64(m.options.NumBlocksToFinality), true
+}
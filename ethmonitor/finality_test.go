@@ -0,0 +1,365 @@
+package ethmonitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinalizedBlock(t *testing.T) {
+	calls := 0
+	var checkpoint uint64
+	var checkpointErr error
+
+	opts := DefaultOptions
+	opts.NumBlocksToFinality = 3
+	opts.FinalityFunc = func(ctx context.Context, provider *ethrpc.Provider, head *Block) (uint64, error) {
+		calls++
+		return checkpoint, checkpointErr
+	}
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	bc := mockBlockchain(10)
+	for _, b := range bc[:8] {
+		require.NoError(t, monitor.chain.push(&Block{Block: b, Event: Added}))
+	}
+
+	// not computed yet
+	require.Nil(t, monitor.FinalizedBlock())
+
+	checkpoint = 5
+	monitor.updateFinality(context.Background())
+	require.Equal(t, uint64(5), monitor.FinalizedBlock().NumberU64())
+
+	// cached for the same head
+	checkpoint = 6
+	monitor.updateFinality(context.Background())
+	require.Equal(t, 1, calls)
+	require.Equal(t, uint64(5), monitor.FinalizedBlockNum().Uint64())
+
+	// call fails on the next head, fall back to the offset
+	checkpointErr = errors.New("call reverted")
+	require.NoError(t, monitor.chain.push(&Block{Block: bc[8], Event: Added}))
+	monitor.updateFinality(context.Background())
+	require.Equal(t, 2, calls)
+	require.Equal(t, uint64(6), monitor.FinalizedBlock().NumberU64())
+
+	// checkpoints ahead of the head are capped
+	checkpointErr = nil
+	checkpoint = 100
+	require.NoError(t, monitor.chain.push(&Block{Block: bc[9], Event: Added}))
+	monitor.updateFinality(context.Background())
+	require.Equal(t, uint64(10), monitor.FinalizedBlock().NumberU64())
+}
+
+func TestFinalityFuncFailureWithoutOffset(t *testing.T) {
+	opts := DefaultOptions
+	opts.FinalityFunc = func(ctx context.Context, provider *ethrpc.Provider, head *Block) (uint64, error) {
+		return 0, errors.New("call reverted")
+	}
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	for _, b := range mockBlockchain(5) {
+		require.NoError(t, monitor.chain.push(&Block{Block: b, Event: Added}))
+	}
+
+	// without NumBlocksToFinality, the head is not considered final when the call fails
+	monitor.updateFinality(context.Background())
+	require.Nil(t, monitor.FinalizedBlockNum())
+	require.Nil(t, monitor.FinalizedBlock())
+}
+
+func TestFinalizedBlockOffset(t *testing.T) {
+	opts := DefaultOptions
+	opts.NumBlocksToFinality = 3
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	bc := mockBlockchain(5)
+	for _, b := range bc[:2] {
+		require.NoError(t, monitor.chain.push(&Block{Block: b, Event: Added}))
+	}
+	require.Nil(t, monitor.FinalizedBlock())
+
+	for _, b := range bc[2:] {
+		require.NoError(t, monitor.chain.push(&Block{Block: b, Event: Added}))
+	}
+	require.Equal(t, uint64(2), monitor.FinalizedBlock().NumberU64())
+
+	// the head is never considered final without a source of finality
+	opts.NumBlocksToFinality = 0
+	monitor, err = NewMonitor(nil, opts)
+	require.NoError(t, err)
+	for _, b := range bc {
+		require.NoError(t, monitor.chain.push(&Block{Block: b, Event: Added}))
+	}
+	require.Nil(t, monitor.FinalizedBlockNum())
+	require.Nil(t, monitor.FinalizedBlock())
+}
+
+func TestFinalityTags(t *testing.T) {
+	bc := mockChain(nil, 0, 10)
+
+	var finalized, safe, unsupported int32 = 4, 6, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Params []string        `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		var num int32
+		switch req.Params[0] {
+		case "finalized":
+			num = atomic.LoadInt32(&finalized)
+		case "safe":
+			num = atomic.LoadInt32(&safe)
+		}
+		if num == 0 || atomic.LoadInt32(&unsupported) == 1 {
+			resp["error"] = map[string]interface{}{"code": -32602, "message": "invalid block tag"}
+		} else {
+			var block map[string]interface{}
+			data, _ := json.Marshal(bc[num-1].Header())
+			json.Unmarshal(data, &block)
+			block["transactions"] = []interface{}{}
+			block["uncles"] = []interface{}{}
+			resp["result"] = block
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+
+	opts := DefaultOptions
+	opts.NumBlocksToFinality = 3
+	opts.FinalityTags = true
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+
+	for _, b := range bc[:8] {
+		require.NoError(t, monitor.chain.push(&Block{Block: b, Event: Added}))
+	}
+
+	// not computed yet
+	require.Nil(t, monitor.FinalizedBlock())
+	require.Nil(t, monitor.SafeBlock())
+
+	monitor.updateFinality(context.Background())
+	require.Equal(t, uint64(4), monitor.FinalizedBlock().NumberU64())
+	require.Equal(t, uint64(6), monitor.SafeBlock().NumberU64())
+
+	// the node doesn't support the tags, fall back to the offset
+	atomic.StoreInt32(&unsupported, 1)
+	require.NoError(t, monitor.chain.push(&Block{Block: bc[8], Event: Added}))
+	monitor.updateFinality(context.Background())
+	require.Equal(t, uint64(6), monitor.FinalizedBlock().NumberU64())
+	require.Equal(t, uint64(6), monitor.SafeBlock().NumberU64())
+
+	// the node supports the tags again on the next head
+	atomic.StoreInt32(&unsupported, 0)
+	atomic.StoreInt32(&safe, 10)
+	require.NoError(t, monitor.chain.push(&Block{Block: bc[9], Event: Added}))
+	monitor.updateFinality(context.Background())
+	require.Equal(t, uint64(4), monitor.FinalizedBlock().NumberU64())
+	require.Equal(t, uint64(10), monitor.SafeBlock().NumberU64())
+
+	// can't be combined with a FinalityFunc
+	opts.FinalityFunc = CheckpointFinalityFunc(common.Address{}, "latestCheckpoint()")
+	_, err = NewMonitor(provider, opts)
+	require.Error(t, err)
+
+	// without NumBlocksToFinality, the head is not considered final or safe when the
+	// node doesn't support the tags
+	atomic.StoreInt32(&unsupported, 1)
+	opts = DefaultOptions
+	opts.FinalityTags = true
+
+	monitor, err = NewMonitor(provider, opts)
+	require.NoError(t, err)
+	for _, b := range bc {
+		require.NoError(t, monitor.chain.push(&Block{Block: b, Event: Added}))
+	}
+	monitor.updateFinality(context.Background())
+	require.Nil(t, monitor.FinalizedBlockNum())
+	require.Nil(t, monitor.SafeBlockNum())
+}
+
+func TestPublishFinalized(t *testing.T) {
+	var chain atomic.Value
+	chain.Store(mockChain(nil, 0, 10))
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.NumBlocksToFinality = 3
+	opts.PublishFinalized = true
+
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	added := map[uint64]bool{}
+	finalized := []uint64{}
+	for len(finalized) < 7 {
+		select {
+		case blocks := <-sub.Blocks():
+			for _, b := range blocks {
+				switch b.Event {
+				case Added:
+					added[b.NumberU64()] = true
+				case Finalized:
+					require.True(t, added[b.NumberU64()], "block %d finalized before added", b.NumberU64())
+					require.Nil(t, b.Logs)
+					finalized = append(finalized, b.NumberU64())
+				default:
+					t.Fatalf("unexpected event %d", b.Event)
+				}
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+	require.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 7}, finalized)
+
+	// requires a way to determine finality
+	opts.NumBlocksToFinality = 0
+	_, err = NewMonitor(nil, opts)
+	require.Error(t, err)
+}
+
+func TestPublishFinalizedFromGenesis(t *testing.T) {
+	opts := DefaultOptions
+	opts.NumBlocksToFinality = 2
+	opts.PublishFinalized = true
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	bc := []*types.Block{mockBlock("0x0", 0)}
+	for i := 1; i < 5; i++ {
+		bc = append(bc, mockBlock(bc[i-1].Hash().Hex(), i))
+	}
+
+	events := Blocks{}
+	for _, b := range bc {
+		block := &Block{Block: b, Event: Added, OK: true}
+		require.NoError(t, monitor.chain.push(block))
+		events = append(events, block)
+	}
+
+	finalizedNum := monitor.FinalizedBlockNum().Uint64()
+	finalized := monitor.finalizedEvents(events)
+	require.Len(t, finalized, int(finalizedNum)+1)
+	for i, b := range finalized {
+		require.Equal(t, Finalized, b.Event)
+		require.Equal(t, uint64(i), b.NumberU64())
+	}
+
+	// nothing more until the head advances
+	require.Empty(t, monitor.finalizedEvents(Blocks{}))
+}
+
+// fakeSafeProvider serves the block at safe for the "safe" and "finalized" block tags.
+type fakeSafeProvider struct {
+	fakeProvider
+	safe int32
+}
+
+func (p *fakeSafeProvider) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	if number != nil && number.Sign() < 0 {
+		return p.fakeProvider.BlockByNumber(ctx, big.NewInt(int64(atomic.LoadInt32(&p.safe))))
+	}
+	return p.fakeProvider.BlockByNumber(ctx, number)
+}
+
+func TestTrailToSafeBlock(t *testing.T) {
+	provider := &fakeSafeProvider{safe: 4}
+	provider.bc.Store(mockChain(nil, 0, 10))
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.FinalityTags = true
+	opts.TrailToSafeBlock = true
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	var next uint64 = 1
+	waitFor := func(num uint64) {
+		for next <= num {
+			select {
+			case blocks := <-sub.Blocks():
+				for _, b := range blocks {
+					require.Equal(t, next, b.NumberU64())
+					next++
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for block #%d", num)
+			}
+		}
+		require.Equal(t, num+1, next)
+	}
+
+	// blocks above the safe block are held back
+	waitFor(4)
+	require.Eventually(t, func() bool {
+		return monitor.LatestBlock() != nil && monitor.LatestBlock().NumberU64() == 10
+	}, 5*time.Second, 5*time.Millisecond)
+	select {
+	case blocks := <-sub.Blocks():
+		t.Fatalf("unexpected events for block #%d", blocks.LatestBlock().NumberU64())
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.Equal(t, uint64(4), monitor.TrailMaxBlockNum())
+	require.Equal(t, 6, monitor.TrailedBlockCount())
+
+	// the safe block advances with the next head
+	atomic.StoreInt32(&provider.safe, 8)
+	provider.bc.Store(mockChain(nil, 0, 11))
+	waitFor(8)
+
+	// requires FinalityTags, and can't be combined with TrailNumBlocksBehindHead
+	opts.FinalityTags = false
+	_, err = NewMonitor(provider, opts)
+	require.ErrorContains(t, err, "TrailToSafeBlock requires FinalityTags")
+
+	opts.FinalityTags = true
+	opts.TrailNumBlocksBehindHead = 2
+	_, err = NewMonitor(provider, opts)
+	require.ErrorContains(t, err, "only one of TrailNumBlocksBehindHead and TrailToSafeBlock")
+}
@@ -0,0 +1,88 @@
+package ethmonitor
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/goware/superr"
+)
+
+var ErrChainGap = errors.New("ethmonitor: gap in the chain, parent block not found")
+
+// ChainGapPolicy is how the monitor reacts to a gap in the chain, ie. when the parent of a
+// new block can't be fetched from the provider, such as from a pruned node or one which
+// is missing blocks, so the new block can't be linked back to the retained chain.
+type ChainGapPolicy int
+
+const (
+	// ChainGapRetry will keep retrying to link the new block to the chain, until the
+	// provider returns the missing blocks. This is the default behaviour.
+	ChainGapRetry ChainGapPolicy = iota
+
+	// ChainGapHalt will stop the monitor with ErrFatal wrapping ErrChainGap.
+	ChainGapHalt
+
+	// ChainGapReseed will reset the monitor to restart from the block after the gap,
+	// accepting the discontinuity, see Monitor.Reset. Subscribers are sent a Reset event,
+	// followed by the Added events from the block after the gap.
+	ChainGapReseed
+)
+
+// chainGapError is returned by buildCanonicalChain when the parent of a block is not
+// found, along with the range of block numbers missing between the head of the retained
+// chain and the block.
+type chainGapError struct {
+	from, to *big.Int
+	err      error
+}
+
+func (e *chainGapError) Error() string {
+	return e.err.Error()
+}
+
+func (e *chainGapError) Unwrap() error {
+	return e.err
+}
+
+// chainGap returns the error for the parent of the block not being found.
+func (m *Monitor) chainGap(block *types.Block) error {
+	from := big.NewInt(0)
+	if head := m.chain.Head(); head != nil {
+		from.SetUint64(head.NumberU64() + 1)
+	}
+	to := big.NewInt(0).Sub(block.Number(), big.NewInt(1))
+
+	return &chainGapError{
+		from: from,
+		to:   to,
+		err: superr.New(ErrChainGap, fmt.Errorf("parent hash:%s of block #%d hash:%s not found, missing blocks #%d to #%d",
+			block.ParentHash().Hex(), block.NumberU64(), block.Hash().Hex(), from, to)),
+	}
+}
+
+// handleChainGap reports the gap to Options.OnChainGap and applies the ChainGapPolicy.
+// It returns true if the monitor was reset to restart from the block after the gap, or an
+// error if the monitor must stop.
+func (m *Monitor) handleChainGap(gap *chainGapError) (bool, error) {
+	m.log.Warnf("ethmonitor: %v", gap)
+
+	if m.options.OnChainGap != nil {
+		m.options.OnChainGap(big.NewInt(0).Set(gap.from), big.NewInt(0).Set(gap.to))
+	}
+
+	switch m.options.ChainGapPolicy {
+	case ChainGapHalt:
+		return false, superr.New(ErrFatal, gap)
+
+	case ChainGapReseed:
+		startBlock := big.NewInt(0).Add(gap.to, big.NewInt(1))
+		m.log.Warnf("ethmonitor: skipping the gap of blocks #%d to #%d, restarting from block #%d", gap.from, gap.to, startBlock)
+		m.reset(resetRequest{startBlock: startBlock, done: make(chan struct{})})
+		return true, nil
+
+	default:
+		return false, nil
+	}
+}
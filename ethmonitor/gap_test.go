@@ -0,0 +1,110 @@
+package ethmonitor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// prunedProvider is missing a block by hash, ie. a pruned node.
+type prunedProvider struct {
+	fakeProvider
+	missing common.Hash
+}
+
+func (p *prunedProvider) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	if hash == p.missing {
+		return nil, ethereum.NotFound
+	}
+	return p.fakeProvider.BlockByHash(ctx, hash)
+}
+
+func TestChainGap(t *testing.T) {
+	bc := mockChain(nil, 0, 5)
+
+	// the provider switches to a fork from block 4, and is missing block 5 of the fork
+	fork := mockChain(bc, 3, 8)
+
+	for _, policy := range []ChainGapPolicy{ChainGapHalt, ChainGapReseed} {
+		provider := &prunedProvider{missing: fork[4].Hash()}
+		provider.bc.Store(bc)
+
+		gaps := make(chan [2]*big.Int, 16)
+		opts := DefaultOptions
+		opts.PollingInterval = 5 * time.Millisecond
+		opts.RetryPolicy = LinearRetryPolicy(time.Millisecond, 3)
+		opts.StartBlockNumber = big.NewInt(1)
+		opts.ChainGapPolicy = policy
+		opts.OnChainGap = func(from, to *big.Int) {
+			gaps <- [2]*big.Int{from, to}
+		}
+
+		monitor, err := NewMonitor(provider, opts)
+		require.NoError(t, err)
+
+		sub := monitor.Subscribe()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- monitor.Run(ctx)
+		}()
+
+		next := func() Blocks {
+			select {
+			case blocks := <-sub.Blocks():
+				return blocks
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for events")
+				return nil
+			}
+		}
+
+		events := Blocks{}
+		for len(events) < len(bc) {
+			events = append(events, next()...)
+		}
+		provider.bc.Store(fork)
+
+		select {
+		case gap := <-gaps:
+			require.Equal(t, uint64(5), gap[0].Uint64())
+			require.Equal(t, uint64(5), gap[1].Uint64())
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the gap")
+		}
+
+		if policy == ChainGapHalt {
+			select {
+			case err := <-done:
+				require.ErrorIs(t, err, ErrFatal)
+				require.ErrorIs(t, err, ErrChainGap)
+			case <-time.After(5 * time.Second):
+				t.Fatal("monitor did not stop")
+			}
+		} else {
+			// the monitor restarts from the block after the gap
+			blocks := next()
+			require.Len(t, blocks, 1)
+			require.Equal(t, Reset, blocks[0].Event)
+
+			events = Blocks{}
+			for len(events) < 3 {
+				events = append(events, next()...)
+			}
+			for i, b := range events {
+				require.Equal(t, Added, b.Event)
+				require.Equal(t, fork[5+i].Hash(), b.Hash())
+			}
+		}
+
+		sub.Unsubscribe()
+		cancel()
+	}
+}
@@ -0,0 +1,51 @@
+package ethmonitor
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeadersOnly(t *testing.T) {
+	bc := mockChain(nil, 0, 5)
+
+	var chain atomic.Value
+	chain.Store(bc)
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.HeadersOnly = true
+
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	var next uint64 = 1
+	for next <= 5 {
+		select {
+		case blocks := <-sub.Blocks():
+			for _, b := range blocks {
+				require.Equal(t, next, b.NumberU64())
+				require.Equal(t, bc[next-1].Hash(), b.Hash())
+				require.Empty(t, b.Transactions())
+				next++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+
+	require.Nil(t, monitor.GetTransaction(common.HexToHash("0x01")))
+}
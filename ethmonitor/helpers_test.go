@@ -0,0 +1,88 @@
+package ethmonitor
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// mockChain returns a chain of size blocks, forked from the given parent chain after
+// forkAt blocks, or a copy of the parent chain if it is nil.
+func mockChain(parent []*types.Block, forkAt, size int) []*types.Block {
+	bc := []*types.Block{}
+	for i := 1; i <= size; i++ {
+		if parent != nil && i <= forkAt {
+			bc = append(bc, parent[i-1])
+			continue
+		}
+		header := &types.Header{Number: big.NewInt(int64(i)), Difficulty: big.NewInt(0)}
+		if parent != nil {
+			header.Time = 1
+		}
+		if i > 1 {
+			header.ParentHash = bc[i-2].Hash()
+		}
+		bc = append(bc, types.NewBlockWithHeader(header))
+	}
+	return bc
+}
+
+// mockNode serves eth_blockNumber, eth_getBlockByNumber and eth_getBlockByHash for the
+// chain currently stored in bc.
+func mockNode(t *testing.T, bc *atomic.Value) *ethrpc.Provider {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		blocks := bc.Load().([]*types.Block)
+
+		var num hexutil.Uint64
+		if req.Method == "eth_getBlockByHash" {
+			var hash common.Hash
+			json.Unmarshal(req.Params[0], &hash)
+			for _, b := range blocks {
+				if b.Hash() == hash {
+					num = hexutil.Uint64(b.NumberU64())
+				}
+			}
+		} else if req.Method == "eth_getBlockByNumber" {
+			if string(req.Params[0]) == `"latest"` {
+				num = hexutil.Uint64(len(blocks))
+			} else {
+				json.Unmarshal(req.Params[0], &num)
+			}
+		}
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": nil}
+		if req.Method == "eth_blockNumber" {
+			resp["result"] = hexutil.Uint64(len(blocks))
+		} else if num > 0 && int(num) <= len(blocks) {
+			var block map[string]interface{}
+			data, _ := json.Marshal(blocks[num-1].Header())
+			json.Unmarshal(data, &block)
+			block["transactions"] = []interface{}{}
+			block["uncles"] = []interface{}{}
+			resp["result"] = block
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+	return provider
+}
@@ -0,0 +1,116 @@
+package ethmonitor
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnexpectedRemovedBlock = errors.New("ethmonitor: removed block is not the head of the chain")
+
+// IngestExternalBlocks applies the add/remove events published by another monitor of
+// the same chain, ie. a primary, to the canonical chain of this monitor without any rpc
+// calls, so a standby monitor mirrors the primary in lockstep and can take over from the
+// mirrored head by calling Run. The events are also broadcast to this monitor's
+// subscribers, and indexed when IndexLogsByAddress is set.
+//
+// The events must be ingested in the order they were published, without gaps, and the
+// monitors must use the same WithLogs and log filter options for the mirrored blocks to
+// carry the same logs. The events are validated against the local chain, ie. added blocks
+// must link to the head and pass the BlockValidator if set, and removed blocks must be the
+// head. A Reset event, published when the primary is reset, clears the chain, which then
+// continues from the next added block. The events are applied atomically: if any event
+// fails validation, none are applied and an error is returned, after which the standby
+// should be re-bootstrapped from a snapshot of the primary.
+//
+// IngestExternalBlocks can't be called while the monitor is running, as the chain is then
+// built from the monitor's own provider.
+func (m *Monitor) IngestExternalBlocks(blocks Blocks) error {
+	if m.IsRunning() {
+		return fmt.Errorf("ethmonitor: cannot ingest external blocks while running")
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	for _, block := range blocks {
+		if block.Block == nil {
+			return fmt.Errorf("ethmonitor: failed to ingest external blocks: %w", ErrMissingBlockNumber)
+		}
+		if block.Event != Added {
+			continue
+		}
+		if err := m.validateBlock(block.Block); err != nil {
+			return fmt.Errorf("ethmonitor: failed to ingest external blocks: %w", err)
+		}
+	}
+
+	err := m.chain.ingest(blocks)
+	if err != nil {
+		return fmt.Errorf("ethmonitor: failed to ingest external blocks: %w", err)
+	}
+
+	m.chain.updateReorgAncestor(blocks)
+	m.recordReorg(blocks)
+	if m.options.WithLogs {
+		m.updateLogIndex(blocks)
+	}
+	m.notifyNewHeads(blocks)
+	m.broadcast(blocks.Copy())
+	return nil
+}
+
+// ingest applies the events to the chain, after validating all of them.
+func (c *Chain) ingest(events Blocks) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	blocks := make(Blocks, len(c.blocks), c.retentionLimit+c.retentionSlack+1)
+	copy(blocks, c.blocks)
+	averageBlockTime := c.averageBlockTime
+
+	reset := false
+	for _, ev := range events {
+		if ev.Event == Finalized {
+			continue
+		}
+		if ev.Event == Reset {
+			// the events which follow don't link to the blocks before the reset, so
+			// the chain continues from the next added block
+			blocks = blocks[:0]
+			averageBlockTime = 0
+			reset = true
+			continue
+		}
+		head := blocks.Head()
+
+		if ev.Event == Removed {
+			if head == nil || head.Hash() != ev.Hash() {
+				return ErrUnexpectedRemovedBlock
+			}
+			blocks = blocks[:len(blocks)-1]
+			continue
+		}
+
+		if head != nil {
+			if !c.blockLinkFunc(head.Block, ev.Block) {
+				return ErrUnexpectedParentHash
+			}
+			if ev.NumberU64() != head.NumberU64()+1 {
+				return ErrUnexpectedBlockNumber
+			}
+			averageBlockTime = nextAverageBlockTime(averageBlockTime, head, ev)
+		}
+		block := *ev
+		blocks = append(blocks, &block)
+	}
+
+	c.blocks = blocks
+	c.averageBlockTime = averageBlockTime
+	if reset {
+		c.reorgAncestor = nil
+	}
+	if len(c.blocks) > c.retentionLimit+c.retentionSlack {
+		c.trim()
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package ethmonitor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestExternalBlocks(t *testing.T) {
+	opts := DefaultOptions
+	opts.BlockRetentionLimit = 10
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	bc := mockBlockchain(5)
+	events := Blocks{}
+	for _, b := range bc {
+		events = append(events, &Block{Event: Added, Block: b, OK: true})
+	}
+	require.NoError(t, monitor.IngestExternalBlocks(events))
+	require.Equal(t, uint64(5), monitor.chain.Head().NumberU64())
+	require.Len(t, <-sub.Blocks(), 5)
+
+	// reorg of block 5
+	fork5 := types.NewBlockWithHeader(&types.Header{ParentHash: bc[3].Hash(), Number: big.NewInt(5), Time: 1})
+	fork6 := mockBlock(fork5.Hash().Hex(), 6)
+	require.NoError(t, monitor.IngestExternalBlocks(Blocks{
+		{Event: Removed, Block: bc[4], OK: true},
+		{Event: Added, Block: fork5, OK: true},
+		{Event: Added, Block: fork6, OK: true},
+	}))
+	require.Equal(t, fork6.Hash(), monitor.chain.Head().Hash())
+	require.Len(t, <-sub.Blocks(), 3)
+
+	// invalid events are not applied
+	err = monitor.IngestExternalBlocks(Blocks{
+		{Event: Removed, Block: fork6, OK: true},
+		{Event: Removed, Block: bc[2], OK: true},
+	})
+	require.ErrorIs(t, err, ErrUnexpectedRemovedBlock)
+	require.Equal(t, fork6.Hash(), monitor.chain.Head().Hash())
+
+	err = monitor.IngestExternalBlocks(Blocks{{Event: Added, Block: mockBlock(bc[0].Hash().Hex(), 7), OK: true}})
+	require.ErrorIs(t, err, ErrUnexpectedParentHash)
+	require.Len(t, monitor.chain.Blocks(), 6)
+
+	// retention limit is applied
+	next := fork6
+	for i := 7; i <= 20; i++ {
+		next = mockBlock(next.Hash().Hex(), i)
+		require.NoError(t, monitor.IngestExternalBlocks(Blocks{{Event: Added, Block: next, OK: true}}))
+	}
+	require.Len(t, monitor.chain.Blocks(), 10)
+	require.Equal(t, uint64(11), monitor.chain.Tail().NumberU64())
+}
+
+func TestIngestMissingBlockNumber(t *testing.T) {
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+
+	bc := mockBlockchain(1)
+
+	err = monitor.IngestExternalBlocks(Blocks{{Event: Added, Block: bc[0]}, {Event: Added}})
+	require.ErrorIs(t, err, ErrMissingBlockNumber)
+	require.Nil(t, monitor.LatestBlock())
+}
+
+func TestIngestExternalBlocksReset(t *testing.T) {
+	opts := DefaultOptions
+	opts.WithLogs = true
+	opts.IndexLogsByAddress = true
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	addr := common.HexToAddress("0x01")
+
+	bc := mockBlockchain(3)
+	events := Blocks{}
+	for _, b := range bc {
+		events = append(events, &Block{Event: Added, Block: b, Logs: []types.Log{{Address: addr, BlockHash: b.Hash()}}, OK: true})
+	}
+	require.NoError(t, monitor.IngestExternalBlocks(events))
+	require.Len(t, monitor.GetLogsByAddress(addr), 3)
+
+	// the primary was reset, and restarted from block 10, which doesn't link to the head
+	next := mockBlock("0x0a", 10)
+	require.NoError(t, monitor.IngestExternalBlocks(Blocks{
+		{Event: Reset, Block: bc[2], OK: true},
+		{Event: Added, Block: next, OK: true},
+	}))
+	require.Len(t, monitor.chain.Blocks(), 1)
+	require.Equal(t, next.Hash(), monitor.chain.Head().Hash())
+	require.Empty(t, monitor.GetLogsByAddress(addr))
+
+	// and the chain continues from there
+	require.NoError(t, monitor.IngestExternalBlocks(Blocks{{Event: Added, Block: mockBlock(next.Hash().Hex(), 11), OK: true}}))
+	require.Equal(t, uint64(11), monitor.chain.Head().NumberU64())
+}
@@ -0,0 +1,124 @@
+package ethmonitor
+
+// ChainUpdate is the net change to the canonical chain since the previous update of a
+// LatestSubscription, which coalesces the block events published while the subscriber
+// was busy.
+type ChainUpdate struct {
+	// Head is the latest head of the chain.
+	Head *Block
+
+	// Reverted are the blocks which were previously applied and have since been removed
+	// by a reorg, from the highest block down.
+	Reverted Blocks
+
+	// Applied are the blocks added to the chain, in order. Blocks which were added and
+	// removed again within the update are left out of both Applied and Reverted.
+	Applied Blocks
+
+	// Finalized is the latest finalized block, if any was published within the update,
+	// see Options.PublishFinalized.
+	Finalized *Block
+
+	// Reset is true if the monitor was reset within the update, see Monitor.Reset, in
+	// which case Reverted is empty and Applied restarts from the new start block.
+	Reset bool
+}
+
+// apply merges the block events into the update.
+func (u *ChainUpdate) apply(blocks Blocks) {
+	for _, block := range blocks {
+		switch block.Event {
+		case Added:
+			u.Applied = append(u.Applied, block)
+			u.Head = block
+
+		case Removed:
+			if n := len(u.Applied); n > 0 && u.Applied[n-1].Hash() == block.Hash() {
+				// the block was never seen by the subscriber
+				u.Applied = u.Applied[:n-1]
+			} else {
+				u.Reverted = append(u.Reverted, block)
+			}
+
+		case Finalized:
+			u.Finalized = block
+
+		case Reset:
+			*u = ChainUpdate{Reset: true}
+		}
+	}
+}
+
+type LatestSubscription interface {
+	Updates() <-chan ChainUpdate
+	Done() <-chan struct{}
+	Unsubscribe()
+}
+
+var _ LatestSubscription = &latestSubscriber{}
+
+type latestSubscriber struct {
+	sub Subscription
+	ch  chan ChainUpdate
+}
+
+func (s *latestSubscriber) Updates() <-chan ChainUpdate {
+	return s.ch
+}
+
+func (s *latestSubscriber) Done() <-chan struct{} {
+	return s.sub.Done()
+}
+
+func (s *latestSubscriber) Unsubscribe() {
+	s.sub.Unsubscribe()
+}
+
+// SubscribeLatest subscribes to the net changes of the canonical chain, for subscribers
+// such as UIs which only care about the latest state of the chain. While the subscriber
+// is busy, the block events are coalesced into a single ChainUpdate which fast-forwards
+// the subscriber to the latest head once it reads it, instead of delivering every
+// intermediate block. A subscriber which keeps up receives an update per publish.
+func (m *Monitor) SubscribeLatest() LatestSubscription {
+	latestSub := &latestSubscriber{
+		sub: m.Subscribe(),
+		ch:  make(chan ChainUpdate),
+	}
+
+	go func() {
+		defer close(latestSub.ch)
+
+		var head *Block
+		var pending *ChainUpdate
+
+		for {
+			// only offer an update once there is one pending
+			var out chan ChainUpdate
+			var update ChainUpdate
+			if pending != nil {
+				out = latestSub.ch
+				update = *pending
+			}
+
+			select {
+			case <-latestSub.sub.Done():
+				return
+
+			case blocks, ok := <-latestSub.sub.Blocks():
+				if !ok {
+					return
+				}
+				if pending == nil {
+					pending = &ChainUpdate{Head: head}
+				}
+				pending.apply(blocks)
+				head = pending.Head
+
+			case out <- update:
+				pending = nil
+			}
+		}
+	}()
+
+	return latestSub
+}
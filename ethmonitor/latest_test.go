@@ -0,0 +1,78 @@
+package ethmonitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeLatest(t *testing.T) {
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+
+	sub := monitor.SubscribeLatest()
+
+	bc := mockBlockchain(3)
+	fork := []*Block{
+		{Event: Added, Block: mockBlock(bc[0].Hash().Hex(), 2)},
+		{Event: Added, Block: mockBlock("0x02", 3)},
+	}
+	added := func(i int) *Block { return &Block{Event: Added, Block: bc[i]} }
+	removed := func(b *Block) *Block { r := *b; r.Event = Removed; return &r }
+
+	readUpdate := func() ChainUpdate {
+		select {
+		case update := <-sub.Updates():
+			return update
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for update")
+		}
+		return ChainUpdate{}
+	}
+
+	monitor.broadcast(Blocks{added(0)})
+	update := readUpdate()
+	require.Len(t, update.Applied, 1)
+	require.Empty(t, update.Reverted)
+	require.Equal(t, bc[0].Hash(), update.Head.Hash())
+
+	// the subscriber is busy while blocks are added and reorged away
+	monitor.broadcast(Blocks{added(1)})
+	monitor.broadcast(Blocks{added(2)})
+	monitor.broadcast(Blocks{removed(added(2)), removed(added(1)), fork[0], fork[1]})
+	monitor.broadcast(Blocks{{Event: Finalized, Block: bc[0]}})
+	time.Sleep(100 * time.Millisecond)
+
+	update = readUpdate()
+	require.Empty(t, update.Reverted)
+	require.Len(t, update.Applied, 2)
+	require.Equal(t, fork[0].Hash(), update.Applied[0].Hash())
+	require.Equal(t, fork[1].Hash(), update.Applied[1].Hash())
+	require.Equal(t, fork[1].Hash(), update.Head.Hash())
+	require.Equal(t, bc[0].Hash(), update.Finalized.Hash())
+
+	// blocks the subscriber has seen are reverted
+	monitor.broadcast(Blocks{removed(fork[1]), removed(fork[0]), added(1)})
+	update = readUpdate()
+	require.Len(t, update.Reverted, 2)
+	require.Equal(t, fork[1].Hash(), update.Reverted[0].Hash())
+	require.Equal(t, fork[0].Hash(), update.Reverted[1].Hash())
+	require.Len(t, update.Applied, 1)
+	require.Equal(t, bc[1].Hash(), update.Head.Hash())
+	require.Nil(t, update.Finalized)
+
+	// only finalized events keep the head
+	monitor.broadcast(Blocks{{Event: Finalized, Block: bc[1]}})
+	update = readUpdate()
+	require.Empty(t, update.Applied)
+	require.Equal(t, bc[1].Hash(), update.Head.Hash())
+
+	sub.Unsubscribe()
+	select {
+	case _, ok := <-sub.Updates():
+		require.False(t, ok)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for unsubscribe")
+	}
+}
@@ -0,0 +1,188 @@
+package ethmonitor
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBloomMatchesLogFilter(t *testing.T) {
+	contractA := common.HexToAddress("0xaaaa")
+	contractB := common.HexToAddress("0xbbbb")
+	transferTopic := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	approvalTopic := common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")
+
+	// block with a single transfer log from contract A
+	bloom := types.CreateBloom(types.Receipts{{Logs: []*types.Log{{Address: contractA, Topics: []common.Hash{transferTopic}}}}})
+
+	newMonitor := func(addrs []common.Address, topics []common.Hash) *Monitor {
+		opts := DefaultOptions
+		opts.WithLogs = true
+		opts.LogAddresses = addrs
+		opts.LogTopics = topics
+		monitor, err := NewMonitor(nil, opts)
+		require.NoError(t, err)
+		return monitor
+	}
+
+	require.False(t, newMonitor(nil, nil).bloomMatchesLogFilter(types.Bloom{}))
+	require.True(t, newMonitor(nil, nil).bloomMatchesLogFilter(bloom))
+
+	require.True(t, newMonitor([]common.Address{contractA}, nil).bloomMatchesLogFilter(bloom))
+	require.True(t, newMonitor([]common.Address{contractB, contractA}, nil).bloomMatchesLogFilter(bloom))
+	require.False(t, newMonitor([]common.Address{contractB}, nil).bloomMatchesLogFilter(bloom))
+
+	require.True(t, newMonitor([]common.Address{contractA}, []common.Hash{transferTopic}).bloomMatchesLogFilter(bloom))
+	require.False(t, newMonitor([]common.Address{contractA}, []common.Hash{approvalTopic}).bloomMatchesLogFilter(bloom))
+	require.False(t, newMonitor([]common.Address{contractB}, []common.Hash{transferTopic}).bloomMatchesLogFilter(bloom))
+}
+
+func TestLogTopicGroups(t *testing.T) {
+	contractA := common.HexToAddress("0xaaaa")
+	transferTopic := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	approvalTopic := common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")
+	from := common.BytesToHash(common.HexToAddress("0x1111").Bytes())
+	to := common.BytesToHash(common.HexToAddress("0x2222").Bytes())
+
+	// block with a single transfer log from 0x1111 to 0x2222
+	bloom := types.CreateBloom(types.Receipts{{Logs: []*types.Log{{Address: contractA, Topics: []common.Hash{transferTopic, from, to}}}}})
+
+	opts := DefaultOptions
+	opts.WithLogs = true
+	opts.LogTopics = []common.Hash{transferTopic}
+	opts.LogTopicGroups = [][]common.Hash{{transferTopic}}
+	_, err := NewMonitor(nil, opts)
+	require.Error(t, err)
+
+	// LogTopics is the same as the first group
+	opts.LogTopicGroups = nil
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+	require.Equal(t, [][]common.Hash{{transferTopic}}, monitor.logTopics())
+
+	cases := []struct {
+		groups [][]common.Hash
+		match  bool
+	}{
+		{[][]common.Hash{{transferTopic}, nil, {to}}, true},
+		{[][]common.Hash{{transferTopic, approvalTopic}, {from}}, true},
+		{[][]common.Hash{nil, nil, {from, to}}, true},
+		{[][]common.Hash{{approvalTopic}, nil, {to}}, false},
+		{[][]common.Hash{{transferTopic}, nil, {common.HexToHash("0x3333")}}, false},
+	}
+	for i, c := range cases {
+		opts.LogTopics = nil
+		opts.LogTopicGroups = c.groups
+		monitor, err := NewMonitor(nil, opts)
+		require.NoError(t, err)
+		require.Equal(t, c.groups, monitor.logTopics())
+		require.Equal(t, c.match, monitor.bloomMatchesLogFilter(bloom), "case %d", i)
+	}
+}
+
+// countingLogsProvider records the blocks the logs of which were fetched.
+type countingLogsProvider struct {
+	fakeProvider
+	mu      sync.Mutex
+	fetched []common.Hash
+}
+
+func (p *countingLogsProvider) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fetched = append(p.fetched, *q.BlockHash)
+	return []types.Log{{BlockHash: *q.BlockHash}}, nil
+}
+
+func TestLogsBloomCheck(t *testing.T) {
+	transferTopic := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	approvalTopic := common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")
+
+	// block 1 has an empty logsBloom, ie. as returned by some proxies, block 2 only has an
+	// approval log and block 3 has a transfer log
+	blooms := []types.Bloom{{}, {}, {}}
+	blooms[1].Add(approvalTopic.Bytes())
+	blooms[2].Add(transferTopic.Bytes())
+
+	bc := []*types.Block{}
+	for i, bloom := range blooms {
+		header := &types.Header{Number: big.NewInt(int64(i + 1)), Difficulty: big.NewInt(0), Bloom: bloom}
+		if i > 0 {
+			header.ParentHash = bc[i-1].Hash()
+		}
+		bc = append(bc, types.NewBlockWithHeader(header))
+	}
+
+	cases := []struct {
+		topics   []common.Hash
+		disabled bool
+		fetched  []common.Hash
+	}{
+		// the logs of block 2 are skipped, as its bloom excludes the topic, while the
+		// empty bloom of block 1 is not trusted
+		{[]common.Hash{transferTopic}, false, []common.Hash{bc[0].Hash(), bc[2].Hash()}},
+		{[]common.Hash{transferTopic}, true, []common.Hash{bc[0].Hash(), bc[1].Hash(), bc[2].Hash()}},
+
+		// without filters, the logs of every block are fetched
+		{nil, false, []common.Hash{bc[0].Hash(), bc[1].Hash(), bc[2].Hash()}},
+	}
+
+	for i, c := range cases {
+		provider := &countingLogsProvider{}
+		provider.bc.Store(bc)
+
+		opts := DefaultOptions
+		opts.PollingInterval = 5 * time.Millisecond
+		opts.StartBlockNumber = big.NewInt(1)
+		opts.WithLogs = true
+		opts.LogTopics = c.topics
+		opts.DisableLogsBloomCheck = c.disabled
+
+		monitor, err := NewMonitor(provider, opts)
+		require.NoError(t, err)
+
+		sub := monitor.Subscribe()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go monitor.Run(ctx)
+
+		events := Blocks{}
+		for len(events) < len(bc) {
+			select {
+			case blocks := <-sub.Blocks():
+				events = append(events, blocks...)
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for events")
+			}
+		}
+		sub.Unsubscribe()
+		cancel()
+
+		provider.mu.Lock()
+		require.Equal(t, c.fetched, provider.fetched, "case %d", i)
+		provider.mu.Unlock()
+
+		for j, b := range events {
+			require.Equal(t, uint64(j+1), b.NumberU64())
+			require.True(t, b.OK)
+			require.NotNil(t, b.Logs)
+
+			fetched := false
+			for _, hash := range c.fetched {
+				fetched = fetched || hash == b.Hash()
+			}
+			if fetched {
+				require.Len(t, b.Logs, 1, "case %d", i)
+			} else {
+				require.Empty(t, b.Logs, "case %d", i)
+			}
+		}
+	}
+}
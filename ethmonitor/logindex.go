@@ -0,0 +1,162 @@
+package ethmonitor
+
+import (
+	"sync"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+)
+
+// LogRef is a reference to a log of a block, ie. of the retained canonical chain, or of
+// a published batch, see Blocks.Logs.
+type LogRef struct {
+	Block *Block
+	Index int
+}
+
+func (r *LogRef) Log() types.Log {
+	return r.Block.Logs[r.Index]
+}
+
+// logIndex indexes the logs of the retained canonical chain by contract address.
+// Refs for each address are kept in block order.
+type logIndex struct {
+	refs    map[common.Address][]*LogRef
+	indexed map[common.Hash]*Block
+	mu      sync.RWMutex
+}
+
+func newLogIndex() *logIndex {
+	return &logIndex{
+		refs:    map[common.Address][]*LogRef{},
+		indexed: map[common.Hash]*Block{},
+	}
+}
+
+// add indexes the logs of an added block, once its logs are available.
+func (x *logIndex) add(block *Block) {
+	if block.Event != Added || !block.OK {
+		return
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if _, ok := x.indexed[block.Hash()]; ok {
+		return
+	}
+	x.indexed[block.Hash()] = block
+
+	for i, log := range block.Logs {
+		refs := append(x.refs[log.Address], &LogRef{Block: block, Index: i})
+
+		// blocks which were backfilled are indexed late, keep refs in block order
+		for j := len(refs) - 1; j > 0 && refs[j-1].Block.NumberU64() > block.NumberU64(); j-- {
+			refs[j-1], refs[j] = refs[j], refs[j-1]
+		}
+		x.refs[log.Address] = refs
+	}
+}
+
+// remove drops the logs of a block which was removed from the canonical chain.
+func (x *logIndex) remove(blockHash common.Hash) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	block, ok := x.indexed[blockHash]
+	if !ok {
+		return
+	}
+	delete(x.indexed, blockHash)
+
+	for _, log := range block.Logs {
+		refs := x.refs[log.Address]
+		kept := refs[:0]
+		for _, ref := range refs {
+			if ref.Block != block {
+				kept = append(kept, ref)
+			}
+		}
+		x.setRefs(log.Address, kept)
+	}
+}
+
+// clear drops the logs of all blocks.
+func (x *logIndex) clear() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.refs = map[common.Address][]*LogRef{}
+	x.indexed = map[common.Hash]*Block{}
+}
+
+// prune drops the logs of blocks older than the oldest retained block.
+func (x *logIndex) prune(oldestBlockNum uint64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for hash, block := range x.indexed {
+		if block.NumberU64() < oldestBlockNum {
+			delete(x.indexed, hash)
+		}
+	}
+	for addr, refs := range x.refs {
+		i := 0
+		for i < len(refs) && refs[i].Block.NumberU64() < oldestBlockNum {
+			i++
+		}
+		if i > 0 {
+			x.setRefs(addr, refs[i:])
+		}
+	}
+}
+
+func (x *logIndex) setRefs(addr common.Address, refs []*LogRef) {
+	if len(refs) == 0 {
+		delete(x.refs, addr)
+	} else {
+		x.refs[addr] = refs
+	}
+}
+
+func (x *logIndex) get(addr common.Address) []types.Log {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	refs := x.refs[addr]
+	logs := make([]types.Log, 0, len(refs))
+	for _, ref := range refs {
+		logs = append(logs, ref.Log())
+	}
+	return logs
+}
+
+// updateLogIndex applies the block events to the log index, and prunes blocks
+// which are no longer retained by the canonical chain.
+func (m *Monitor) updateLogIndex(events Blocks) {
+	if m.logIndex == nil {
+		return
+	}
+	for _, block := range events {
+		if block.Event == Removed {
+			m.logIndex.remove(block.Hash())
+		} else if block.Event == Added {
+			m.logIndex.add(block)
+		} else if block.Event == Reset {
+			m.logIndex.clear()
+		}
+	}
+	if tail := m.chain.Tail(); tail != nil {
+		m.logIndex.prune(tail.NumberU64())
+	}
+}
+
+// GetLogsByAddress returns the logs emitted by the contract address within the retained
+// canonical chain, ordered from oldest to newest. Requires the IndexLogsByAddress and
+// WithLogs options, otherwise nil is returned.
+func (m *Monitor) GetLogsByAddress(addr common.Address) []types.Log {
+	if m.logIndex == nil {
+		return nil
+	}
+	return m.logIndex.get(addr)
+}
@@ -0,0 +1,69 @@
+package ethmonitor
+
+import (
+	"testing"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLogsByAddress(t *testing.T) {
+	opts := DefaultOptions
+	opts.WithLogs = true
+	opts.IndexLogsByAddress = true
+	opts.BlockRetentionLimit = 10
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	contractA := common.HexToAddress("0xaaaa")
+	contractB := common.HexToAddress("0xbbbb")
+
+	newBlock := func(b *types.Block, addrs ...common.Address) *Block {
+		block := &Block{Event: Added, Block: b, OK: true, Logs: []types.Log{}}
+		for i, addr := range addrs {
+			block.Logs = append(block.Logs, types.Log{Address: addr, BlockNumber: b.NumberU64(), Index: uint(i)})
+		}
+		return block
+	}
+
+	push := func(blocks ...*Block) {
+		for _, b := range blocks {
+			require.NoError(t, monitor.chain.push(b))
+		}
+		monitor.updateLogIndex(blocks)
+	}
+
+	bc := mockBlockchain(15)
+	push(newBlock(bc[0], contractA), newBlock(bc[1], contractA, contractB), newBlock(bc[2], contractB))
+
+	require.Len(t, monitor.GetLogsByAddress(contractA), 2)
+	require.Len(t, monitor.GetLogsByAddress(contractB), 2)
+	require.Empty(t, monitor.GetLogsByAddress(common.HexToAddress("0xcccc")))
+
+	// reorg block 3
+	popped := *monitor.chain.pop()
+	popped.Event = Removed
+	monitor.updateLogIndex(Blocks{&popped})
+	require.Len(t, monitor.GetLogsByAddress(contractB), 1)
+
+	// a block which is missing logs is indexed once backfilled
+	pending := newBlock(bc[2], contractA)
+	pending.OK = false
+	push(pending)
+	require.Len(t, monitor.GetLogsByAddress(contractA), 2)
+	pending.OK = true
+	monitor.logIndex.add(pending)
+	logs := monitor.GetLogsByAddress(contractA)
+	require.Len(t, logs, 3)
+	require.Equal(t, uint64(3), logs[2].BlockNumber)
+
+	// blocks beyond retention are pruned
+	for _, b := range bc[3:] {
+		push(newBlock(b))
+	}
+	require.Equal(t, uint64(6), monitor.chain.Tail().NumberU64())
+	require.Empty(t, monitor.GetLogsByAddress(contractA))
+	require.Empty(t, monitor.GetLogsByAddress(contractB))
+}
@@ -0,0 +1,141 @@
+package ethmonitor
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metrics of the monitor, registered with Options.MetricsRegisterer. A nil *metrics is
+// valid, and all of its methods are no-ops.
+type metrics struct {
+	blocksAdded      prometheus.Counter
+	blocksRemoved    prometheus.Counter
+	reorgDepth       prometheus.Histogram
+	publishQueueLen  prometheus.Gauge
+	subOverflows     prometheus.Counter
+	getLogsFailures  prometheus.Counter
+	getLogsBackfills prometheus.Counter
+	pollInterval     prometheus.Gauge
+	retainedBlocks   prometheus.GaugeFunc
+	blocksEvicted    prometheus.CounterFunc
+}
+
+func newMetrics(reg prometheus.Registerer, chain *Chain) (*metrics, error) {
+	if reg == nil {
+		return nil, nil
+	}
+
+	m := &metrics{
+		blocksAdded: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "ethmonitor_blocks_added_total",
+			Help: "Number of blocks added to the canonical chain.",
+		}),
+		blocksRemoved: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "ethmonitor_blocks_removed_total",
+			Help: "Number of blocks removed from the canonical chain by reorgs.",
+		}),
+		reorgDepth: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "ethmonitor_reorg_depth",
+			Help:    "Number of blocks removed by each reorg.",
+			Buckets: []float64{1, 2, 3, 5, 8, 13, 21, 34, 55, 89},
+		}),
+		publishQueueLen: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "ethmonitor_publish_queue_length",
+			Help: "Number of events waiting in the publish queue.",
+		}),
+		subOverflows: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "ethmonitor_subscriber_overflows_total",
+			Help: "Number of times events were dropped for, or a subscriber was disconnected for, falling behind by more than the subscriber buffer limit.",
+		}),
+		getLogsFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "ethmonitor_get_logs_failures_total",
+			Help: "Number of failed calls to fetch the logs of a block, which are marked for backfilling.",
+		}),
+		getLogsBackfills: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "ethmonitor_get_logs_backfills_total",
+			Help: "Number of blocks whose logs were successfully backfilled.",
+		}),
+		pollInterval: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "ethmonitor_poll_interval_seconds",
+			Help: "Current adaptive interval between polls for new blocks.",
+		}),
+		retainedBlocks: prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "ethmonitor_retained_blocks",
+			Help: "Number of blocks retained on the canonical chain.",
+		}, func() float64 {
+			return float64(chain.Len())
+		}),
+		blocksEvicted: prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "ethmonitor_blocks_evicted_total",
+			Help: "Number of blocks evicted from the canonical chain by the retention limit.",
+		}, func() float64 {
+			return float64(chain.EvictedCount())
+		}),
+	}
+
+	collectors := []prometheus.Collector{
+		m.blocksAdded, m.blocksRemoved, m.reorgDepth, m.publishQueueLen, m.subOverflows,
+		m.getLogsFailures, m.getLogsBackfills, m.pollInterval, m.retainedBlocks, m.blocksEvicted,
+	}
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *metrics) observeEvents(events Blocks) {
+	if m == nil {
+		return
+	}
+	removed := 0
+	for _, block := range events {
+		switch block.Event {
+		case Added:
+			m.blocksAdded.Inc()
+		case Removed:
+			m.blocksRemoved.Inc()
+			removed++
+		}
+	}
+	if removed > 0 {
+		m.reorgDepth.Observe(float64(removed))
+	}
+}
+
+func (m *metrics) setPublishQueueLen(n int) {
+	if m == nil {
+		return
+	}
+	m.publishQueueLen.Set(float64(n))
+}
+
+func (m *metrics) subscriberOverflowed() {
+	if m == nil {
+		return
+	}
+	m.subOverflows.Inc()
+}
+
+func (m *metrics) getLogsFailed() {
+	if m == nil {
+		return
+	}
+	m.getLogsFailures.Inc()
+}
+
+func (m *metrics) getLogsBackfilled() {
+	if m == nil {
+		return
+	}
+	m.getLogsBackfills.Inc()
+}
+
+func (m *metrics) setPollInterval(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.pollInterval.Set(d.Seconds())
+}
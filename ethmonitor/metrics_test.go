@@ -0,0 +1,84 @@
+package ethmonitor
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	primary := mockChain(nil, 0, 5)
+
+	var chain atomic.Value
+	chain.Store(primary)
+
+	reg := prometheus.NewRegistry()
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.MetricsRegisterer = prometheus.WrapRegistererWith(prometheus.Labels{"chain_id": "1"}, reg)
+
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+
+	// metrics of another chain can be registered on the same registry
+	opts.MetricsRegisterer = prometheus.WrapRegistererWith(prometheus.Labels{"chain_id": "2"}, reg)
+	_, err = NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	// but not twice for the same chain
+	_, err = NewMonitor(nil, opts)
+	require.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	require.Eventually(t, func() bool {
+		head := monitor.LatestBlock()
+		return head != nil && head.NumberU64() == 5
+	}, 5*time.Second, 5*time.Millisecond)
+
+	// reorg the last two blocks
+	chain.Store(mockChain(primary, 3, 6))
+	require.Eventually(t, func() bool {
+		head := monitor.LatestBlock()
+		return head != nil && head.NumberU64() == 6
+	}, 5*time.Second, 5*time.Millisecond)
+	cancel()
+
+	require.Equal(t, float64(8), testutil.ToFloat64(monitor.metrics.blocksAdded))
+	require.Equal(t, float64(2), testutil.ToFloat64(monitor.metrics.blocksRemoved))
+	require.Equal(t, 1, testutil.CollectAndCount(monitor.metrics.reorgDepth))
+	require.Equal(t, float64(0), testutil.ToFloat64(monitor.metrics.publishQueueLen))
+	require.Greater(t, testutil.ToFloat64(monitor.metrics.pollInterval), float64(0))
+	require.Equal(t, float64(6), testutil.ToFloat64(monitor.metrics.retainedBlocks))
+	require.Equal(t, float64(0), testutil.ToFloat64(monitor.metrics.blocksEvicted))
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	require.NotEmpty(t, families)
+	for _, family := range families {
+		require.Len(t, family.GetMetric(), 2, family.GetName())
+	}
+}
+
+func TestMetricsDisabled(t *testing.T) {
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+	require.Nil(t, monitor.metrics)
+
+	// no-ops
+	monitor.metrics.observeEvents(Blocks{})
+	monitor.metrics.setPublishQueueLen(1)
+	monitor.metrics.getLogsFailed()
+	monitor.metrics.getLogsBackfilled()
+	monitor.metrics.setPollInterval(time.Second)
+}
@@ -0,0 +1,112 @@
+package ethmonitor
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseResume(t *testing.T) {
+	bc := []*types.Block{}
+	for i := 1; i <= 20; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Difficulty: big.NewInt(0)}
+		if i > 1 {
+			header.ParentHash = bc[i-2].Hash()
+		}
+		bc = append(bc, types.NewBlockWithHeader(header))
+	}
+
+	latest := uint64(5)
+	var requests int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		var num hexutil.Uint64
+		json.Unmarshal(req.Params[0], &num)
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": nil}
+		if num > 0 && uint64(num) <= atomic.LoadUint64(&latest) {
+			var block map[string]interface{}
+			data, _ := json.Marshal(bc[num-1].Header())
+			json.Unmarshal(data, &block)
+			block["transactions"] = []interface{}{}
+			block["uncles"] = []interface{}{}
+			resp["result"] = block
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	waitForHead := func(num uint64) {
+		require.Eventually(t, func() bool {
+			head := monitor.LatestBlock()
+			return head != nil && head.NumberU64() == num
+		}, 5*time.Second, 5*time.Millisecond)
+	}
+	waitForHead(5)
+
+	monitor.Pause()
+	require.True(t, monitor.IsPaused())
+	require.True(t, monitor.IsRunning())
+
+	// no blocks are fetched while paused
+	time.Sleep(50 * time.Millisecond)
+	numRequests := atomic.LoadInt32(&requests)
+	atomic.StoreUint64(&latest, 20)
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, numRequests, atomic.LoadInt32(&requests))
+	require.Equal(t, uint64(5), monitor.LatestBlock().NumberU64())
+
+	// on resume, the monitor catches up from the head, and no events are dropped
+	monitor.Resume()
+	require.False(t, monitor.IsPaused())
+	waitForHead(20)
+
+	var next uint64 = 1
+	for next <= 20 {
+		select {
+		case blocks := <-sub.Blocks():
+			for _, b := range blocks {
+				require.Equal(t, next, b.NumberU64())
+				next++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package ethmonitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+)
+
+// BlockProvider is the source of the blocks and logs of the monitor, which is satisfied by
+// *ethrpc.Provider. Other implementations, ie. a fake serving a deterministic sequence of
+// blocks and reorgs in tests, may also implement the following methods of *ethrpc.Provider
+// to support the options which depend on them:
+//
+//   - BlockNumber, for CatchUpThreshold and SyncTolerance
+//   - ChainID, for ExpectedChainID
+//   - BlockHeaderByNumber and BlockHeaderByHash, for HeadersOnly
+//   - TransactionReceipt, and optionally BlockReceipts, for WithReceipts
+//
+// StreamingMode and FinalityFunc require an *ethrpc.Provider, see Monitor.Provider.
+type BlockProvider interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+var _ BlockProvider = &ethrpc.Provider{}
+
+type blockNumberProvider interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+type chainIDProvider interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+type blockHeaderProvider interface {
+	BlockHeaderByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	BlockHeaderByHash(ctx context.Context, hash common.Hash) (*types.Block, error)
+}
+
+type receiptProvider interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+type blockReceiptsProvider interface {
+	BlockReceipts(ctx context.Context, blockHash common.Hash) ([]*types.Receipt, error)
+}
+
+// validateProvider checks the provider supports the configured options.
+func validateProvider(provider BlockProvider, opts Options) error {
+	if provider == nil {
+		return nil
+	}
+	if _, ok := provider.(*ethrpc.Provider); ok {
+		return nil
+	}
+
+	if _, ok := provider.(blockNumberProvider); !ok && (opts.CatchUpThreshold > 0 || opts.SyncTolerance > 0) {
+		return fmt.Errorf("ethmonitor: provider does not support options, CatchUpThreshold and SyncTolerance require BlockNumber")
+	}
+	if _, ok := provider.(chainIDProvider); !ok && opts.ExpectedChainID != nil {
+		return fmt.Errorf("ethmonitor: provider does not support options, ExpectedChainID requires ChainID")
+	}
+	if _, ok := provider.(blockHeaderProvider); !ok && opts.HeadersOnly {
+		return fmt.Errorf("ethmonitor: provider does not support options, HeadersOnly requires BlockHeaderByNumber and BlockHeaderByHash")
+	}
+	if _, ok := provider.(receiptProvider); !ok && opts.WithReceipts {
+		return fmt.Errorf("ethmonitor: provider does not support options, WithReceipts requires TransactionReceipt")
+	}
+	if opts.StreamingMode || opts.FinalityFunc != nil {
+		return fmt.Errorf("ethmonitor: provider does not support options, StreamingMode and FinalityFunc require an *ethrpc.Provider")
+	}
+	return nil
+}
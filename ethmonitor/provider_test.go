@@ -0,0 +1,181 @@
+package ethmonitor
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider is an in-memory BlockProvider serving the chain currently stored in bc.
+type fakeProvider struct {
+	bc atomic.Value
+}
+
+func (p *fakeProvider) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	blocks := p.bc.Load().([]*types.Block)
+	if number == nil {
+		return blocks[len(blocks)-1], nil
+	}
+	if number.Sign() <= 0 || number.Uint64() > uint64(len(blocks)) {
+		return nil, ethereum.NotFound
+	}
+	return blocks[number.Uint64()-1], nil
+}
+
+func (p *fakeProvider) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	for _, b := range p.bc.Load().([]*types.Block) {
+		if b.Hash() == hash {
+			return b, nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+func (p *fakeProvider) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	return nil, nil
+}
+
+func TestBlockProviderReorg(t *testing.T) {
+	bc := mockChain(nil, 0, 5)
+	provider := &fakeProvider{}
+	provider.bc.Store(bc)
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+	require.Nil(t, monitor.Provider())
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	events := Blocks{}
+	waitFor := func(num uint64) {
+		for {
+			select {
+			case blocks := <-sub.Blocks():
+				events = append(events, blocks...)
+				if last := blocks[len(blocks)-1]; last.Event == Added && last.NumberU64() == num {
+					return
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for block #%d", num)
+			}
+		}
+	}
+	waitFor(5)
+
+	// the node switches to a fork of block 3
+	fork := mockChain(bc, 3, 7)
+	provider.bc.Store(fork)
+	waitFor(7)
+
+	removed := []uint64{}
+	for _, b := range events {
+		if b.Event == Removed {
+			removed = append(removed, b.NumberU64())
+		}
+	}
+	require.Equal(t, []uint64{5, 4}, removed)
+	require.Equal(t, fork[6].Hash(), monitor.LatestBlock().Hash())
+}
+
+func TestBlockProviderUnsupportedOptions(t *testing.T) {
+	opts := DefaultOptions
+	opts.WithReceipts = true
+	_, err := NewMonitor(&fakeProvider{}, opts)
+	require.ErrorContains(t, err, "WithReceipts requires TransactionReceipt")
+
+	opts = DefaultOptions
+	opts.HeadersOnly = true
+	_, err = NewMonitor(&fakeProvider{}, opts)
+	require.ErrorContains(t, err, "HeadersOnly requires")
+
+	opts = DefaultOptions
+	opts.SyncTolerance = 1
+	_, err = NewMonitor(&fakeProvider{}, opts)
+	require.ErrorContains(t, err, "SyncTolerance require BlockNumber")
+}
+
+// numberlessProvider serves the blocks of fakeProvider, except for the block numberless,
+// or the latest block if numberless is 0, which fails to decode without a header.
+type numberlessProvider struct {
+	fakeProvider
+	numberless uint64
+}
+
+func (p *numberlessProvider) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	if (number == nil && p.numberless == 0) || (number != nil && number.Uint64() == p.numberless) {
+		return nil, ethrpc.ErrMissingBlockHeader
+	}
+	return p.fakeProvider.BlockByNumber(ctx, number)
+}
+
+func TestBlockProviderMissingBlockNumber(t *testing.T) {
+	t.Run("fetchBlockByNumber", func(t *testing.T) {
+		provider := &numberlessProvider{numberless: 3}
+		provider.bc.Store(mockChain(nil, 0, 5))
+
+		opts := DefaultOptions
+		opts.PollingInterval = 5 * time.Millisecond
+		opts.StartBlockNumber = big.NewInt(1)
+
+		monitor, err := NewMonitor(provider, opts)
+		require.NoError(t, err)
+
+		_, err = monitor.fetchBlockByNumber(context.Background(), big.NewInt(3))
+		require.ErrorIs(t, err, ErrMissingBlockNumber)
+
+		sub := monitor.Subscribe()
+		defer sub.Unsubscribe()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		errCh := make(chan error, 1)
+		go func() { errCh <- monitor.Run(ctx) }()
+
+		// the monitor keeps polling past the numberless block without crashing
+		for head := uint64(0); head < 2; {
+			select {
+			case blocks := <-sub.Blocks():
+				head = blocks[len(blocks)-1].NumberU64()
+			case err := <-errCh:
+				t.Fatalf("monitor stopped: %v", err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for block #2")
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		require.True(t, monitor.IsRunning())
+		require.Equal(t, uint64(2), monitor.LatestBlock().NumberU64())
+	})
+
+	t.Run("StartBlockNumber behind the latest block", func(t *testing.T) {
+		provider := &numberlessProvider{}
+		provider.bc.Store(mockChain(nil, 0, 5))
+
+		opts := DefaultOptions
+		opts.PollingInterval = 5 * time.Millisecond
+		opts.StartBlockNumber = big.NewInt(-2)
+
+		monitor, err := NewMonitor(provider, opts)
+		require.NoError(t, err)
+
+		err = monitor.Run(context.Background())
+		require.ErrorIs(t, err, ErrMissingBlockNumber)
+	})
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/goware/channel"
+	"github.com/goware/logger"
 	"github.com/goware/superr"
 )
 
@@ -17,13 +17,17 @@ type Subscription interface {
 var _ Subscription = &subscriber{}
 
 type subscriber struct {
-	ch          channel.Channel[Blocks]
+	ch          *subscriberChan[Blocks]
 	done        chan struct{}
 	unsubscribe func()
+
+	// filter restricts the logs delivered to the subscriber, see SubscribeWithFilter
+	filter    logMatcher
+	dropEmpty bool
 }
 
 func (s *subscriber) Blocks() <-chan Blocks {
-	return s.ch.ReadChannel()
+	return s.ch.readCh
 }
 
 func (s *subscriber) Done() <-chan struct{} {
@@ -34,6 +38,171 @@ func (s *subscriber) Unsubscribe() {
 	s.unsubscribe()
 }
 
+// SubscriberOverflowPolicy is the strategy used when a subscriber falls behind by more
+// than Options.SubscriberBufferLimit events.
+type SubscriberOverflowPolicy int
+
+const (
+	// SubscriberDropOldest will drop the oldest events buffered for the subscriber to make
+	// room for new ones. The monitor and other subscribers are unaffected, but the slow
+	// subscriber will observe a gap in the events it receives. This is the default behaviour.
+	SubscriberDropOldest SubscriberOverflowPolicy = iota
+
+	// SubscriberBlock will block publishing to all subscribers until the slow subscriber
+	// catches up. No events are lost, but a wedged subscriber stalls the monitor, and the
+	// publish queue fills up as per the BackpressureMode.
+	SubscriberBlock
+
+	// SubscriberDisconnect will unsubscribe the slow subscriber and close its Blocks
+	// channel, so a single wedged subscriber can't exhaust the memory of the process.
+	// The subscriber should still call Unsubscribe once it notices.
+	SubscriberDisconnect
+)
+
+// subscriberBufferWarning is the number of events buffered for a subscriber at which
+// the monitor warns that the subscriber is falling behind, or the buffer limit if lower.
+const subscriberBufferWarning = 100
+
+// subscriberChan is the channel of events of a subscriber, which buffers the events the
+// subscriber has yet to read up to a limit, after which the overflow policy applies.
+type subscriberChan[T any] struct {
+	readCh  chan T
+	notify  chan struct{}
+	closeCh chan struct{}
+	log     logger.Logger
+
+	limit  int
+	policy SubscriberOverflowPolicy
+	warnAt int
+
+	buffer []T
+	warned bool
+	closed bool
+	mu     sync.Mutex
+	cond   *sync.Cond
+}
+
+func newSubscriberChan[T any](log logger.Logger, limit int, policy SubscriberOverflowPolicy) *subscriberChan[T] {
+	c := &subscriberChan[T]{
+		readCh:  make(chan T),
+		notify:  make(chan struct{}, 1),
+		closeCh: make(chan struct{}),
+		log:     log,
+		limit:   limit,
+		policy:  policy,
+		warnAt:  subscriberBufferWarning,
+	}
+	if limit > 0 && limit < c.warnAt {
+		c.warnAt = limit
+	}
+	c.cond = sync.NewCond(&c.mu)
+	go c.run()
+	return c
+}
+
+// run delivers the buffered events to the reader, until the channel is closed.
+func (c *subscriberChan[T]) run() {
+	defer close(c.readCh)
+
+	for {
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			return
+		}
+		if len(c.buffer) == 0 {
+			// warn again the next time the subscriber falls behind
+			c.warned = false
+			c.mu.Unlock()
+			select {
+			case <-c.notify:
+			case <-c.closeCh:
+			}
+			continue
+		}
+		var zero T
+		events := c.buffer[0]
+		c.buffer[0] = zero
+		c.buffer = c.buffer[1:]
+		c.cond.Broadcast()
+		c.mu.Unlock()
+
+		select {
+		case c.readCh <- events:
+		case <-c.closeCh:
+			return
+		}
+	}
+}
+
+// send buffers the events for the reader, and returns false if events were lost to an
+// overflow of the buffer, ie. the oldest events were dropped under the SubscriberDropOldest
+// policy, or the events were not sent under the SubscriberDisconnect policy. Waiting for
+// the reader under the SubscriberBlock policy loses no events.
+func (c *subscriberChan[T]) send(events T) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return true
+	}
+
+	ok := true
+	if c.limit > 0 && len(c.buffer) >= c.limit {
+		switch c.policy {
+		case SubscriberDropOldest:
+			var zero T
+			c.buffer[0] = zero
+			c.buffer = c.buffer[1:]
+			ok = false
+
+		case SubscriberBlock:
+			for !c.closed && len(c.buffer) >= c.limit {
+				c.cond.Wait()
+			}
+			if c.closed {
+				return true
+			}
+
+		case SubscriberDisconnect:
+			return false
+		}
+	}
+
+	c.buffer = append(c.buffer, events)
+	if !c.warned && len(c.buffer) >= c.warnAt {
+		c.warned = true
+		c.log.Warnf("ethmonitor: subscriber is falling behind, %d events are buffered for it", len(c.buffer))
+	}
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+	return ok
+}
+
+// len returns the number of events buffered for the reader.
+func (c *subscriberChan[T]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.buffer)
+}
+
+// close drops the buffered events and closes the read channel. It's safe to call
+// more than once.
+func (c *subscriberChan[T]) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.buffer = nil
+	close(c.closeCh)
+	c.cond.Broadcast()
+}
+
 // queue is the publish event queue
 type queue struct {
 	events Blocks
@@ -67,18 +236,24 @@ func (c *queue) enqueue(events Blocks) error {
 	for _, event := range events {
 		switch event.Event {
 
-		case Added:
+		case Added, Finalized:
 			c.events = append(c.events, event)
 
 		case Removed:
-			if len(c.events) > 0 {
-				tail := c.events[len(c.events)-1]
+			// skip over finalized events, which are of older blocks than the removed one
+			i := len(c.events) - 1
+			for i >= 0 && c.events[i].Event == Finalized {
+				i--
+			}
+
+			if i >= 0 {
+				tail := c.events[i]
 
 				switch tail.Event {
 				case Added:
 					if event.Hash() == tail.Hash() {
 						// instead of publishing this removal, pop the most recent event
-						c.events = c.events[:len(c.events)-1]
+						c.events = append(c.events[:i], c.events[i+1:]...)
 					} else {
 						// it should be impossible to remove anything but the most recent event
 						return fmt.Errorf("removing block %v %v %v, but last block is %v %v %v", event.Event, event.Number(), event.Hash().Hex(), tail.Event, tail.Number(), tail.Hash().Hex())
@@ -103,6 +278,20 @@ func (c *queue) enqueue(events Blocks) error {
 	return nil
 }
 
+// dropOldest removes the oldest events until the queue is within its capacity,
+// returning the number of events dropped.
+func (c *queue) dropOldest() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.events) - c.cap
+	if n <= 0 {
+		return 0
+	}
+	c.events = c.events[n:]
+	return n
+}
+
 func (c *queue) dequeue(maxBlockNum uint64) (Blocks, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -131,9 +320,9 @@ func (c *queue) dequeue(maxBlockNum uint64) (Blocks, bool) {
 	if len(events) == 0 {
 		return Blocks{}, false
 	}
-	if events[len(events)-1].Event != Added {
-		// last block must be an added one, otherwise we do
-		// not dequeue any events
+	if events[len(events)-1].Event == Removed {
+		// last block must be an added or finalized one, otherwise
+		// we do not dequeue any events
 		return Blocks{}, false
 	}
 
@@ -143,6 +332,19 @@ func (c *queue) dequeue(maxBlockNum uint64) (Blocks, bool) {
 	return events, true
 }
 
+// countAbove returns the number of events in the queue above the block number.
+func (c *queue) countAbove(blockNum uint64) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, ev := range c.events {
+		if ev.NumberU64() > blockNum {
+			n++
+		}
+	}
+	return n
+}
+
 func (c *queue) head() *Block {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -1,12 +1,17 @@
 package ethmonitor
 
 import (
+	"context"
+	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/0xsequence/ethkit/go-ethereum/common"
 	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/goware/logger"
 	"github.com/stretchr/testify/require"
 )
 
@@ -95,3 +100,445 @@ func mockBlock(parentHash string, blockNum int) *types.Block {
 		Number:     big.NewInt(int64(blockNum)),
 	})
 }
+
+func TestQueueDropOldest(t *testing.T) {
+	qu := newQueue(3)
+
+	events := Blocks{}
+	for _, b := range mockBlockchain(5) {
+		events = append(events, &Block{Block: b, Event: Added, OK: true})
+	}
+
+	err := qu.enqueue(events)
+	require.ErrorIs(t, err, ErrQueueFull)
+	require.Len(t, qu.events, 5)
+
+	require.Equal(t, 2, qu.dropOldest())
+	require.Len(t, qu.events, 3)
+	require.Equal(t, uint64(3), qu.head().NumberU64())
+	require.Equal(t, 0, qu.dropOldest())
+}
+
+func TestTrailedBlockCount(t *testing.T) {
+	opts := DefaultOptions
+	opts.TrailNumBlocksBehindHead = 3
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	published := make(chan Blocks, 10)
+	go func() {
+		for blocks := range monitor.publishCh {
+			published <- blocks
+		}
+	}()
+	defer close(monitor.publishCh)
+
+	require.Equal(t, 0, monitor.TrailedBlockCount())
+
+	events := Blocks{}
+	for _, b := range mockBlockchain(10) {
+		block := &Block{Block: b, Event: Added, OK: true}
+		require.NoError(t, monitor.chain.push(block))
+		events = append(events, block)
+	}
+
+	require.NoError(t, monitor.publish(context.Background(), events))
+	require.Len(t, <-published, 7)
+	require.Equal(t, uint64(7), monitor.TrailMaxBlockNum())
+	require.Equal(t, 3, monitor.TrailedBlockCount())
+}
+
+func TestSubscribeWithContext(t *testing.T) {
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := monitor.SubscribeWithContext(ctx)
+
+	monitor.mu.RLock()
+	require.Len(t, monitor.subscribers, 1)
+	monitor.mu.RUnlock()
+
+	cancel()
+	select {
+	case <-sub.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscription was not closed on context cancel")
+	}
+
+	require.Eventually(t, func() bool {
+		monitor.mu.RLock()
+		defer monitor.mu.RUnlock()
+		return len(monitor.subscribers) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// explicit unsubscribe after the context is done is a no-op
+	sub.Unsubscribe()
+
+	// and unsubscribing before the context is done stops the watcher
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	sub = monitor.SubscribeWithContext(ctx2)
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	cancel2()
+}
+
+func TestUnsubscribeConcurrently(t *testing.T) {
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+
+	subA := monitor.Subscribe()
+	sub := monitor.Subscribe()
+	subB := monitor.Subscribe()
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			sub.Unsubscribe()
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	select {
+	case <-sub.Done():
+	default:
+		t.Fatal("subscription was not closed")
+	}
+
+	// only the unsubscribed subscriber was removed
+	monitor.mu.RLock()
+	require.Equal(t, []*subscriber{subA.(*subscriber), subB.(*subscriber)}, monitor.subscribers)
+	monitor.mu.RUnlock()
+
+	sub.Unsubscribe()
+	subA.Unsubscribe()
+	subB.Unsubscribe()
+}
+
+func TestQueueFinalized(t *testing.T) {
+	qu := newQueue(100)
+
+	bc := mockBlockchain(3)
+	events := Blocks{}
+	for _, b := range bc {
+		events = append(events, &Block{Block: b, Event: Added, OK: true})
+	}
+	events = append(events, &Block{Block: bc[0], Event: Finalized, OK: true})
+	require.NoError(t, qu.enqueue(events))
+
+	// the removal of the head skips over the finalized event
+	require.NoError(t, qu.enqueue(Blocks{{Block: bc[2], Event: Removed, OK: true}}))
+	require.Len(t, qu.events, 3)
+	require.Equal(t, Finalized, qu.tail().Event)
+
+	// events ending with a finalized event are dequeued
+	dequeued, ok := qu.dequeue(0)
+	require.True(t, ok)
+	require.Len(t, dequeued, 3)
+	require.Equal(t, Added, dequeued[1].Event)
+	require.Equal(t, bc[1].Hash(), dequeued[1].Hash())
+}
+
+func TestSubscriberOverflow(t *testing.T) {
+	bc := mockBlockchain(4)
+	events := make([]Blocks, len(bc))
+	for i, b := range bc {
+		events[i] = Blocks{{Block: b, Event: Added}}
+	}
+
+	// newSub subscribes and fills the buffer of the subscriber up to its limit of 2, with
+	// the first event in flight to the reader
+	newSub := func(policy SubscriberOverflowPolicy) (*Monitor, *subscriber) {
+		opts := DefaultOptions
+		opts.SubscriberBufferLimit = 2
+		opts.SubscriberOverflowPolicy = policy
+
+		monitor, err := NewMonitor(nil, opts)
+		require.NoError(t, err)
+		sub := monitor.Subscribe().(*subscriber)
+
+		monitor.broadcast(events[0])
+		require.Eventually(t, func() bool { return sub.ch.len() == 0 }, time.Second, time.Millisecond)
+		monitor.broadcast(events[1])
+		monitor.broadcast(events[2])
+		require.Equal(t, uint64(0), monitor.SubscriberOverflowCount())
+		return monitor, sub
+	}
+
+	read := func(sub Subscription) uint64 {
+		select {
+		case blocks := <-sub.Blocks():
+			return blocks.LatestBlock().NumberU64()
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+		return 0
+	}
+
+	t.Run("DropOldest", func(t *testing.T) {
+		monitor, sub := newSub(SubscriberDropOldest)
+		defer sub.Unsubscribe()
+
+		monitor.broadcast(events[3])
+		require.Equal(t, uint64(1), monitor.SubscriberOverflowCount())
+
+		require.Equal(t, uint64(1), read(sub))
+		require.Equal(t, uint64(3), read(sub))
+		require.Equal(t, uint64(4), read(sub))
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		monitor, sub := newSub(SubscriberBlock)
+		defer sub.Unsubscribe()
+
+		done := make(chan struct{})
+		go func() {
+			monitor.broadcast(events[3])
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("broadcast did not block on the slow subscriber")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		// no events are lost once the subscriber catches up
+		for i := uint64(1); i <= 4; i++ {
+			require.Equal(t, i, read(sub))
+		}
+		<-done
+
+		// waiting on the subscriber loses no events, so it is not counted as an overflow
+		require.Equal(t, uint64(0), monitor.SubscriberOverflowCount())
+	})
+
+	t.Run("Disconnect", func(t *testing.T) {
+		monitor, sub := newSub(SubscriberDisconnect)
+
+		monitor.broadcast(events[3])
+		require.Equal(t, uint64(1), monitor.SubscriberOverflowCount())
+
+		monitor.mu.RLock()
+		require.Len(t, monitor.subscribers, 0)
+		monitor.mu.RUnlock()
+
+		// the channel of the subscriber is closed
+		timeout := time.After(time.Second)
+		for closed := false; !closed; {
+			select {
+			case _, ok := <-sub.Blocks():
+				closed = !ok
+			case <-timeout:
+				t.Fatal("subscriber channel was not closed")
+			}
+		}
+
+		// and unsubscribing afterwards is harmless
+		sub.Unsubscribe()
+	})
+}
+
+func TestSubscriberBlockCallsMonitor(t *testing.T) {
+	opts := DefaultOptions
+	opts.SubscriberBufferLimit = 1
+	opts.SubscriberOverflowPolicy = SubscriberBlock
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	bc := mockBlockchain(5)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, b := range bc {
+			monitor.broadcast(Blocks{{Block: b, Event: Added}})
+		}
+	}()
+
+	// the subscriber calls the monitor while the broadcast waits on it
+	for i := range bc {
+		select {
+		case blocks := <-sub.Blocks():
+			require.Equal(t, uint64(i+1), blocks.LatestBlock().NumberU64())
+			called := make(chan struct{})
+			go func() {
+				monitor.Synced()
+				monitor.Subscribe().Unsubscribe()
+				close(called)
+			}()
+			select {
+			case <-called:
+			case <-time.After(time.Second):
+				t.Fatal("deadlocked calling the monitor")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("deadlocked waiting for events")
+		}
+	}
+	<-done
+}
+
+// warnLogger records the warnings logged
+type warnLogger struct {
+	logger.Logger
+	warnings []string
+	mu       sync.Mutex
+}
+
+func (l *warnLogger) Warnf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warnings = append(l.warnings, fmt.Sprintf(format, v...))
+}
+
+func (l *warnLogger) Warnings() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, l.warnings...)
+}
+
+func TestSubscriberLagWarning(t *testing.T) {
+	log := &warnLogger{Logger: logger.NewLogger(logger.LogLevel_ERROR)}
+
+	opts := DefaultOptions
+	opts.Logger = log
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe().(*subscriber)
+	defer sub.Unsubscribe()
+
+	// the first event is in flight to the reader, and the others are buffered
+	bc := mockBlockchain(subscriberBufferWarning + 2)
+	monitor.broadcast(Blocks{{Block: bc[0], Event: Added}})
+	require.Eventually(t, func() bool { return sub.ch.len() == 0 }, time.Second, time.Millisecond)
+	for _, b := range bc[1:subscriberBufferWarning] {
+		monitor.broadcast(Blocks{{Block: b, Event: Added}})
+	}
+	require.Empty(t, log.Warnings())
+
+	// the subscriber is warned about once while it stays behind
+	for _, b := range bc[subscriberBufferWarning:] {
+		monitor.broadcast(Blocks{{Block: b, Event: Added}})
+	}
+	require.Len(t, log.Warnings(), 1)
+	require.Contains(t, log.Warnings()[0], "subscriber is falling behind")
+	require.Equal(t, uint64(0), monitor.SubscriberOverflowCount())
+
+	// and warned again once it falls behind after catching up
+	for range bc {
+		<-sub.Blocks()
+	}
+	require.Eventually(t, func() bool {
+		sub.ch.mu.Lock()
+		defer sub.ch.mu.Unlock()
+		return !sub.ch.warned
+	}, time.Second, time.Millisecond)
+	for _, b := range bc {
+		monitor.broadcast(Blocks{{Block: b, Event: Added}})
+	}
+	require.Len(t, log.Warnings(), 2)
+}
+
+func TestOnNewHead(t *testing.T) {
+	bc := mockChain(nil, 0, 5)
+	provider := &fakeProvider{}
+	provider.bc.Store(bc)
+
+	heads := make(chan *Block, 16)
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.OnNewHead = func(block *Block) {
+		heads <- block
+	}
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	waitFor := func(expected []*types.Block) {
+		for _, b := range expected {
+			select {
+			case head := <-heads:
+				require.Equal(t, Added, head.Event)
+				require.True(t, head.OK)
+				require.Equal(t, b.Hash(), head.Hash())
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for head #%d", b.NumberU64())
+			}
+		}
+	}
+	waitFor(bc)
+
+	// removed blocks are not passed to the hook
+	fork := mockChain(bc, 3, 6)
+	provider.bc.Store(fork)
+	waitFor(fork[3:])
+}
+
+func TestTicks(t *testing.T) {
+	bc := mockChain(nil, 0, 3)
+	provider := &fakeProvider{}
+	provider.bc.Store(bc)
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+	ticks := monitor.Ticks()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	waitFor := func(num uint64) {
+		for {
+			select {
+			case blocks := <-sub.Blocks():
+				if blocks.LatestBlock().NumberU64() == num {
+					return
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for block #%d", num)
+			}
+		}
+	}
+	waitFor(3)
+
+	// ticks arrive on polls which find no new block
+	for i := 0; i < 3; i++ {
+		select {
+		case <-ticks:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a tick")
+		}
+	}
+	require.Equal(t, uint64(3), monitor.LatestBlock().NumberU64())
+
+	// ticks are dropped without blocking the monitor when nobody reads them
+	time.Sleep(10 * opts.PollingInterval)
+	require.Len(t, ticks, 1)
+
+	provider.bc.Store(mockChain(bc, 3, 5))
+	waitFor(5)
+	require.Len(t, ticks, 1)
+}
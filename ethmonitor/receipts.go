@@ -0,0 +1,71 @@
+package ethmonitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+)
+
+// addReceipts fetches the receipts of the added blocks which don't have them yet. Blocks
+// whose receipts failed to fetch are marked for backfilling, the same as for logs.
+func (m *Monitor) addReceipts(ctx context.Context, blocks Blocks) {
+	for _, block := range blocks {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		// do not attempt to get receipts for re-org'd blocks, as with logs
+		if block.Event != Added || block.receipts != nil {
+			continue
+		}
+
+		receipts, err := m.fetchReceipts(ctx, block.Block)
+		if err != nil {
+			block.OK = false
+			m.log.Infof("ethmonitor: [getReceipts failed -- marking block %s for backfilling] %v", block.Hash().Hex(), err)
+			continue
+		}
+
+		// the block may be retained in the chain, where receipts are read under its lock
+		m.chain.mu.Lock()
+		block.receipts = receipts
+		m.chain.mu.Unlock()
+	}
+}
+
+// fetchReceipts fetches the receipts of the block with eth_getBlockReceipts, falling back
+// to fetching them per transaction if the node doesn't support it.
+func (m *Monitor) fetchReceipts(ctx context.Context, block *types.Block) ([]*types.Receipt, error) {
+	tctx, cancel := context.WithTimeout(ctx, m.options.Timeout)
+	defer cancel()
+
+	if p, ok := m.provider.(blockReceiptsProvider); ok && atomic.LoadInt32(&m.blockReceiptsUnsupported) == 0 {
+		receipts, err := p.BlockReceipts(tctx, block.Hash())
+		if err == nil && len(receipts) != len(block.Transactions()) {
+			// a node which has yet to index the block may return partial receipts
+			return nil, fmt.Errorf("got %d receipts for block %s with %d transactions", len(receipts), block.Hash().Hex(), len(block.Transactions()))
+		}
+		if !errors.Is(err, ethrpc.ErrMethodUnsupported) {
+			return receipts, err
+		}
+		if atomic.CompareAndSwapInt32(&m.blockReceiptsUnsupported, 0, 1) {
+			m.log.Warnf("ethmonitor: eth_getBlockReceipts is not supported by the node, fetching receipts per transaction")
+		}
+	}
+
+	receipts := make([]*types.Receipt, 0, len(block.Transactions()))
+	for _, txn := range block.Transactions() {
+		receipt, err := m.provider.(receiptProvider).TransactionReceipt(tctx, txn.Hash())
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
@@ -0,0 +1,270 @@
+package ethmonitor
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// mockReceiptsNode serves a chain of blocks with a transaction each, and their receipts
+// with eth_getBlockReceipts if blockReceipts is set, or with eth_getTransactionReceipt.
+// The first receipts request fails, the second eth_getBlockReceipts request returns no
+// receipts, as a node which has yet to index the block, and the number of receipts
+// requests is counted.
+func mockReceiptsNode(t *testing.T, size int, blockReceipts bool, requests *int32) (*ethrpc.Provider, []*types.Block) {
+	key, err := crypto.HexToECDSA("4646464646464646464646464646464646464646464646464646464646464646")
+	require.NoError(t, err)
+	signer := types.NewLondonSigner(big.NewInt(1))
+
+	bc := []*types.Block{}
+	for i, b := range mockChain(nil, 0, size) {
+		txn, err := types.SignTx(types.NewTransaction(uint64(i), common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+		require.NoError(t, err)
+		bc = append(bc, b.WithBody([]*types.Transaction{txn}, nil))
+	}
+
+	receipt := func(b *types.Block) *types.Receipt {
+		return &types.Receipt{
+			Status:      types.ReceiptStatusSuccessful,
+			TxHash:      b.Transactions()[0].Hash(),
+			BlockHash:   b.Hash(),
+			BlockNumber: b.Number(),
+			GasUsed:     21000,
+			Logs:        []*types.Log{},
+		}
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": nil}
+		var hash common.Hash
+		if len(req.Params) > 0 {
+			json.Unmarshal(req.Params[0], &hash)
+		}
+
+		switch req.Method {
+		case "eth_getBlockByNumber":
+			var num hexutil.Uint64
+			json.Unmarshal(req.Params[0], &num)
+			if num > 0 && int(num) <= len(bc) {
+				b := bc[num-1]
+				var block map[string]interface{}
+				data, _ := json.Marshal(b.Header())
+				json.Unmarshal(data, &block)
+				block["transactions"] = b.Transactions()
+				block["uncles"] = []interface{}{}
+				resp["result"] = block
+			}
+
+		case "eth_getBlockReceipts", "eth_getTransactionReceipt":
+			if (req.Method == "eth_getBlockReceipts") != blockReceipts {
+				resp["error"] = map[string]interface{}{"code": -32601, "message": "the method " + req.Method + " does not exist/is not available"}
+				break
+			}
+			n := atomic.AddInt32(requests, 1)
+			if n == 1 {
+				resp["error"] = map[string]interface{}{"code": -32000, "message": "header not found"}
+				break
+			}
+			if n == 2 && blockReceipts {
+				resp["result"] = []*types.Receipt{}
+				break
+			}
+			for _, b := range bc {
+				if b.Hash() == hash {
+					resp["result"] = []*types.Receipt{receipt(b)}
+				} else if b.Transactions()[0].Hash() == hash {
+					resp["result"] = receipt(b)
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+	return provider, bc
+}
+
+func TestWithReceipts(t *testing.T) {
+	for _, blockReceipts := range []bool{true, false} {
+		var requests int32
+		provider, bc := mockReceiptsNode(t, 3, blockReceipts, &requests)
+
+		opts := DefaultOptions
+		opts.PollingInterval = 5 * time.Millisecond
+		opts.StartBlockNumber = big.NewInt(1)
+		opts.WithReceipts = true
+
+		monitor, err := NewMonitor(provider, opts)
+		require.NoError(t, err)
+
+		sub := monitor.Subscribe()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go monitor.Run(ctx)
+
+		var next uint64 = 1
+		for next <= 3 {
+			select {
+			case blocks := <-sub.Blocks():
+				for _, b := range blocks {
+					require.Equal(t, next, b.NumberU64())
+					require.Len(t, b.Receipts(), 1)
+					require.Equal(t, bc[next-1].Transactions()[0].Hash(), b.Receipts()[0].TxHash)
+					require.Equal(t, uint64(21000), b.Receipts()[0].GasUsed)
+					next++
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for events")
+			}
+		}
+
+		// the failed request of block #1, and the missing receipts of eth_getBlockReceipts,
+		// were backfilled
+		expected := int32(4)
+		if blockReceipts {
+			expected = 5
+		}
+		require.Equal(t, expected, atomic.LoadInt32(&requests))
+
+		// receipts are served from the retained chain
+		receipt := monitor.GetReceipt(bc[1].Transactions()[0].Hash())
+		require.NotNil(t, receipt)
+		require.Equal(t, bc[1].Hash(), receipt.BlockHash)
+		require.Nil(t, monitor.GetReceipt(common.HexToHash("0x01")))
+		require.Equal(t, expected, atomic.LoadInt32(&requests))
+
+		cancel()
+		sub.Unsubscribe()
+	}
+}
+
+func TestChainGetReceipt(t *testing.T) {
+	chain := newChain(10, 0, false)
+
+	bc := mockBlockchain(12)
+	txnHash := func(i int) common.Hash { return common.BigToHash(big.NewInt(int64(i + 1))) }
+
+	for i, b := range bc {
+		block := &Block{Block: b, Event: Added}
+		block.receipts = []*types.Receipt{{TxHash: txnHash(i)}}
+		require.NoError(t, chain.push(block))
+	}
+
+	// receipts of blocks beyond the retention are evicted
+	require.Nil(t, chain.GetReceipt(txnHash(0)))
+	require.Nil(t, chain.GetReceipt(txnHash(1)))
+	require.NotNil(t, chain.GetReceipt(txnHash(2)))
+	require.NotNil(t, chain.GetReceipt(txnHash(11)))
+
+	// receipts of reorged blocks are evicted
+	chain.pop()
+	require.Nil(t, chain.GetReceipt(txnHash(11)))
+	require.NotNil(t, chain.GetReceipt(txnHash(10)))
+}
+
+// fakeBlockReceiptsProvider serves the receipts of the blocks with a transaction each,
+// with a log each of two contracts, and counts the eth_getLogs calls.
+type fakeBlockReceiptsProvider struct {
+	fakeProvider
+	unsupported bool
+	filterLogs  int32
+}
+
+func (p *fakeBlockReceiptsProvider) BlockReceipts(ctx context.Context, blockHash common.Hash) ([]*types.Receipt, error) {
+	if p.unsupported {
+		return nil, ethrpc.ErrMethodUnsupported
+	}
+	return []*types.Receipt{{
+		BlockHash: blockHash,
+		Logs: []*types.Log{
+			{Address: common.HexToAddress("0x02"), BlockHash: blockHash, Index: 1},
+			{Address: common.HexToAddress("0x01"), BlockHash: blockHash, Index: 0},
+		},
+	}}, nil
+}
+
+func (p *fakeBlockReceiptsProvider) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return &types.Receipt{TxHash: txHash}, nil
+}
+
+func (p *fakeBlockReceiptsProvider) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	atomic.AddInt32(&p.filterLogs, 1)
+	return []types.Log{{Address: common.HexToAddress("0x01"), BlockHash: *q.BlockHash}}, nil
+}
+
+func TestUseBlockReceipts(t *testing.T) {
+	for _, unsupported := range []bool{false, true} {
+		provider := &fakeBlockReceiptsProvider{unsupported: unsupported}
+		bc := []*types.Block{}
+		for i, b := range mockChain(nil, 0, 3) {
+			txn := types.NewTransaction(uint64(i), common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+			bc = append(bc, b.WithBody([]*types.Transaction{txn}, nil))
+		}
+		provider.bc.Store(bc)
+
+		opts := DefaultOptions
+		opts.PollingInterval = 5 * time.Millisecond
+		opts.StartBlockNumber = big.NewInt(1)
+		opts.WithLogs = true
+		opts.WithReceipts = true
+		opts.UseBlockReceipts = true
+		opts.LogAddresses = []common.Address{common.HexToAddress("0x01")}
+
+		monitor, err := NewMonitor(provider, opts)
+		require.NoError(t, err)
+
+		sub := monitor.Subscribe()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go monitor.Run(ctx)
+
+		var next uint64 = 1
+		for next <= 3 {
+			select {
+			case blocks := <-sub.Blocks():
+				for _, b := range blocks {
+					require.Equal(t, next, b.NumberU64())
+					require.Len(t, b.Logs, 1)
+					require.Equal(t, common.HexToAddress("0x01"), b.Logs[0].Address)
+					require.Len(t, b.Receipts(), 1)
+					next++
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for events")
+			}
+		}
+
+		// logs are only fetched with eth_getLogs when eth_getBlockReceipts is unsupported
+		if unsupported {
+			require.GreaterOrEqual(t, atomic.LoadInt32(&provider.filterLogs), int32(3))
+		} else {
+			require.Zero(t, atomic.LoadInt32(&provider.filterLogs))
+		}
+
+		cancel()
+		sub.Unsubscribe()
+	}
+}
@@ -0,0 +1,98 @@
+package ethmonitor
+
+import (
+	"errors"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+)
+
+// ReorgInfo describes a reorg of the canonical chain, once the chain has been rebuilt.
+type ReorgInfo struct {
+	// CommonAncestorNum and CommonAncestorHash identify the last block which is
+	// on both the old and the new canonical chain.
+	CommonAncestorNum  uint64
+	CommonAncestorHash common.Hash
+
+	// Depth is the number of blocks removed from the canonical chain.
+	Depth int
+
+	// Removed are the hashes of the removed blocks, from the old head down to the
+	// common ancestor.
+	Removed []common.Hash
+
+	// Added are the hashes of the blocks added in their place, from the common
+	// ancestor up to the new head.
+	Added []common.Hash
+}
+
+// reorgInfo returns the reorg described by the events of a rebuild of the canonical
+// chain, or false if no blocks were removed.
+func reorgInfo(events Blocks) (ReorgInfo, bool) {
+	var reorg ReorgInfo
+	var ancestor *Block
+
+	for _, block := range events {
+		switch block.Event {
+		case Removed:
+			reorg.Removed = append(reorg.Removed, block.Hash())
+			if ancestor == nil || block.NumberU64() <= ancestor.NumberU64() {
+				ancestor = block
+			}
+		case Added:
+			reorg.Added = append(reorg.Added, block.Hash())
+		}
+	}
+	if ancestor == nil {
+		return ReorgInfo{}, false
+	}
+
+	reorg.Depth = len(reorg.Removed)
+	reorg.CommonAncestorNum = ancestor.NumberU64() - 1
+	reorg.CommonAncestorHash = ancestor.ParentHash()
+	return reorg, true
+}
+
+// reportReorg passes the reorg described by the events, if any, to the Options.OnReorg
+// hook. It must only be called once the canonical chain has been fully rebuilt, so the
+// hook fires exactly once per reorg.
+func (m *Monitor) reportReorg(events Blocks) {
+	if m.options.OnReorg == nil {
+		return
+	}
+	reorg, ok := reorgInfo(events)
+	if !ok {
+		return
+	}
+	m.log.Debugf("ethmonitor: reorg of depth %d, common ancestor #%d hash:%s", reorg.Depth, reorg.CommonAncestorNum, reorg.CommonAncestorHash.Hex())
+	m.options.OnReorg(reorg)
+}
+
+// reorgDepth returns the number of blocks removed so far while rebuilding the canonical
+// chain, and the head of the chain before the reorg.
+func reorgDepth(events Blocks, head *Block) (int, *Block) {
+	depth := 0
+	lastHead := head
+	for _, block := range events {
+		if block.Event != Removed {
+			continue
+		}
+		if depth == 0 {
+			lastHead = block
+		}
+		depth++
+	}
+	return depth, lastHead
+}
+
+// errNoCommonAncestor is returned by buildCanonicalChain when the provider has switched
+// to a fork sharing no common ancestor with the retained canonical chain, ie. after a long
+// outage, where walking back the parent hashes would never reconnect to our chain.
+var errNoCommonAncestor = errors.New("ethmonitor: no common ancestor with the provider's chain")
+
+// restartFromLatest handles errNoCommonAncestor by resetting the monitor to the provider's
+// latest block, so subscribers are sent a Reset event of the head they last observed,
+// instead of observing a gap in the block numbers.
+func (m *Monitor) restartFromLatest(head *Block, err error) {
+	m.log.Warnf("ethmonitor: %v, the provider may have switched forks -- restarting from the latest block", err)
+	m.reset(resetRequest{head: head, done: make(chan struct{})})
+}
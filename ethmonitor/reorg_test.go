@@ -0,0 +1,310 @@
+package ethmonitor
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportReorg(t *testing.T) {
+	reorgs := []ReorgInfo{}
+
+	opts := DefaultOptions
+	opts.OnReorg = func(reorg ReorgInfo) {
+		reorgs = append(reorgs, reorg)
+	}
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	bc := mockBlockchain(5)
+
+	// fork at block 3, replacing blocks 4 and 5 with 3 new blocks
+	fork4 := mockBlock(bc[2].Hash().Hex(), 4)
+	fork5 := mockBlock(fork4.Hash().Hex(), 5)
+	fork6 := mockBlock(fork5.Hash().Hex(), 6)
+
+	// no reorg, just new blocks
+	monitor.reportReorg(Blocks{{Event: Added, Block: bc[3]}, {Event: Added, Block: bc[4]}})
+	require.Empty(t, reorgs)
+
+	// events as emitted by buildCanonicalChain, popped blocks first
+	monitor.reportReorg(Blocks{
+		{Event: Removed, Block: bc[4]},
+		{Event: Removed, Block: bc[3]},
+		{Event: Added, Block: fork4},
+		{Event: Added, Block: fork5},
+		{Event: Added, Block: fork6},
+	})
+	require.Len(t, reorgs, 1)
+
+	reorg := reorgs[0]
+	require.Equal(t, 2, reorg.Depth)
+	require.Equal(t, uint64(3), reorg.CommonAncestorNum)
+	require.Equal(t, bc[2].Hash(), reorg.CommonAncestorHash)
+	require.Equal(t, []common.Hash{bc[4].Hash(), bc[3].Hash()}, reorg.Removed)
+	require.Equal(t, []common.Hash{fork4.Hash(), fork5.Hash(), fork6.Hash()}, reorg.Added)
+}
+
+func TestMaxReorgDepth(t *testing.T) {
+	opts := DefaultOptions
+	opts.MaxReorgDepth = 1
+	opts.PollingInterval = time.Nanosecond
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	bc := mockBlockchain(5)
+	for _, b := range bc[:4] {
+		require.NoError(t, monitor.chain.push(&Block{Event: Added, Block: b, OK: true}))
+	}
+
+	// block 5 was already popped by a previous attempt to rebuild the chain, and the
+	// next block doesn't link to block 4 either
+	events := Blocks{{Event: Removed, Block: bc[4], OK: true}}
+	fork := mockBlock(common.HexToHash("0x01").Hex(), 5)
+
+	_, err = monitor.buildCanonicalChain(context.Background(), fork, events)
+	require.ErrorIs(t, err, ErrReorg)
+	require.Contains(t, err.Error(), "last canonical head was #5")
+	require.Equal(t, uint64(4), monitor.chain.Head().NumberU64())
+}
+
+func TestBuildCanonicalChainDeepReorg(t *testing.T) {
+	newBlock := func(parent *types.Block, num int, fork byte) *types.Block {
+		header := &types.Header{
+			Number:     big.NewInt(int64(num)),
+			UncleHash:  types.EmptyUncleHash,
+			TxHash:     types.EmptyRootHash,
+			Difficulty: big.NewInt(0),
+			Extra:      []byte{fork},
+		}
+		if parent != nil {
+			header.ParentHash = parent.Hash()
+		}
+		return types.NewBlockWithHeader(header)
+	}
+
+	// canonical chain of 60 blocks, and a fork from block 10 which is one block longer
+	canonical := []*types.Block{newBlock(nil, 1, 0)}
+	for i := 2; i <= 60; i++ {
+		canonical = append(canonical, newBlock(canonical[len(canonical)-1], i, 0))
+	}
+	fork := []*types.Block{newBlock(canonical[9], 11, 1)}
+	for i := 12; i <= 61; i++ {
+		fork = append(fork, newBlock(fork[len(fork)-1], i, 1))
+	}
+
+	blocksByHash := map[common.Hash]*types.Block{}
+	for _, b := range fork {
+		blocksByHash[b.Hash()] = b
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []common.Hash   `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": nil}
+		if b, ok := blocksByHash[req.Params[0]]; ok && req.Method == "eth_getBlockByHash" {
+			var block map[string]interface{}
+			data, _ := json.Marshal(b.Header())
+			json.Unmarshal(data, &block)
+			block["transactions"] = []interface{}{}
+			block["uncles"] = []interface{}{}
+			resp["result"] = block
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+
+	opts := DefaultOptions
+	opts.PollingInterval = time.Millisecond
+	opts.BlockRetentionLimit = 100
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+	for _, b := range canonical {
+		require.NoError(t, monitor.chain.push(&Block{Event: Added, Block: b, OK: true}))
+	}
+
+	events, err := monitor.buildCanonicalChain(context.Background(), fork[len(fork)-1], Blocks{})
+	require.NoError(t, err)
+	require.Len(t, events, 50+51)
+
+	// popped blocks from the old head down, then added blocks from the fork point up
+	for i := 0; i < 50; i++ {
+		require.Equal(t, Removed, events[i].Event)
+		require.Equal(t, canonical[59-i].Hash(), events[i].Hash())
+	}
+	for i := 0; i < 51; i++ {
+		require.Equal(t, Added, events[50+i].Event)
+		require.Equal(t, fork[i].Hash(), events[50+i].Hash())
+	}
+
+	require.Equal(t, fork[len(fork)-1].Hash(), monitor.chain.Head().Hash())
+	require.Len(t, monitor.chain.Blocks(), 61)
+}
+
+func TestBuildCanonicalChainNoCommonAncestor(t *testing.T) {
+	retained := mockChain(nil, 0, 5)
+
+	// the provider switched to a chain which shares no blocks with the retained chain
+	var chain atomic.Value
+	chain.Store(mockChain(retained, 0, 8))
+	remote := chain.Load().([]*types.Block)
+
+	opts := DefaultOptions
+	opts.PollingInterval = time.Millisecond
+
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+	for _, b := range retained[2:] {
+		require.NoError(t, monitor.chain.push(&Block{Event: Added, Block: b, OK: true}))
+	}
+
+	// the retained chain is walked back down to its tail, whose replacement doesn't share
+	// its parent
+	events, err := monitor.buildCanonicalChain(context.Background(), remote[5], Blocks{})
+	require.ErrorIs(t, err, errNoCommonAncestor)
+	require.Len(t, events, 3)
+	for i := 0; i < 3; i++ {
+		require.Equal(t, Removed, events[i].Event)
+		require.Equal(t, retained[4-i].Hash(), events[i].Hash())
+	}
+}
+
+func TestReorgBelowTail(t *testing.T) {
+	bc := mockChain(nil, 0, 3)
+	provider := &fakeProvider{}
+	provider.bc.Store(bc)
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(3)
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	events := Blocks{}
+	waitFor := func(hash common.Hash) {
+		for {
+			select {
+			case blocks := <-sub.Blocks():
+				events = append(events, blocks...)
+				if last := blocks[len(blocks)-1]; last.Event != Removed && last.Hash() == hash {
+					return
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for block %s", hash.Hex())
+			}
+		}
+	}
+	waitFor(bc[2].Hash())
+
+	// the node reorgs block 3, and block 2 is the common ancestor, which is not retained
+	fork := mockChain(bc, 2, 5)
+	provider.bc.Store(fork)
+	waitFor(fork[4].Hash())
+
+	require.Len(t, events, 5)
+	require.Equal(t, Added, events[0].Event)
+	require.Equal(t, bc[2].Hash(), events[0].Hash())
+	require.Equal(t, Removed, events[1].Event)
+	require.Equal(t, bc[2].Hash(), events[1].Hash())
+	for i, b := range fork[2:] {
+		require.Equal(t, Added, events[2+i].Event)
+		require.Equal(t, b.Hash(), events[2+i].Hash())
+	}
+}
+
+func TestReorgNoCommonAncestor(t *testing.T) {
+	bc := mockChain(nil, 0, 5)
+	provider := &fakeProvider{}
+	provider.bc.Store(bc)
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(3)
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	events := Blocks{}
+	waitFor := func(hash common.Hash) {
+		for {
+			select {
+			case blocks := <-sub.Blocks():
+				events = append(events, blocks...)
+				if last := blocks[len(blocks)-1]; last.Event == Added && last.Hash() == hash {
+					return
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for block %s", hash.Hex())
+			}
+		}
+	}
+	waitFor(bc[4].Hash())
+
+	// the node switches to a chain which shares no blocks with the retained chain
+	fork := mockChain(bc, 0, 8)
+	provider.bc.Store(fork)
+	waitFor(fork[7].Hash())
+
+	// a Reset of the last published head marks the restart from the latest block
+	require.Len(t, events, 5)
+	require.Equal(t, Reset, events[3].Event)
+	require.Equal(t, bc[4].Hash(), events[3].Hash())
+	require.Equal(t, Added, events[4].Event)
+	require.Equal(t, fork[7].Hash(), events[4].Hash())
+	require.Len(t, monitor.Chain().Blocks(), 1)
+}
+
+func TestReorgRecoveryPause(t *testing.T) {
+	opts := DefaultOptions
+	opts.PollingInterval = 10 * time.Second
+
+	// defaults to the polling interval
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Second, monitor.reorgPause(1))
+
+	opts.ReorgRecoveryPause = 100 * time.Millisecond
+	monitor, err = NewMonitor(nil, opts)
+	require.NoError(t, err)
+	require.Equal(t, 100*time.Millisecond, monitor.reorgPause(1))
+	require.Equal(t, 300*time.Millisecond, monitor.reorgPause(3))
+
+	// capped after maxReorgPauseSteps blocks
+	require.Equal(t, 500*time.Millisecond, monitor.reorgPause(50))
+}
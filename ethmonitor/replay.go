@@ -0,0 +1,114 @@
+package ethmonitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/goware/superr"
+)
+
+// Replay feeds the blocks through the same pipeline as Run, ie. the canonical chain builder
+// and the publish queue, instead of polling the provider, and publishes the events to the
+// subscribers, then returns. The blocks are fed in order as the next head of the chain, the
+// same as when polled from a node. A block which doesn't link to the head is handled as a
+// reorg, where its parents are looked up by hash among all the given blocks, so a production
+// reorg can be reproduced from captured blocks. Blocks which don't extend the head, ie. the
+// blocks of a fork which is not ahead of the canonical chain yet, are only used as parents.
+//
+// The blocks are replayed on top of the retained chain, and are published without logs and
+// receipts, as they're not fetched from the provider. Replay can't be called while the
+// monitor is running, and returns once all the blocks have been published, except those
+// held back by TrailNumBlocksBehindHead or TrailToSafeBlock.
+func (m *Monitor) Replay(ctx context.Context, blocks []*types.Block) error {
+	if !atomic.CompareAndSwapInt32(&m.running, 0, 1) {
+		return fmt.Errorf("ethmonitor: cannot replay, monitor is running")
+	}
+	defer atomic.StoreInt32(&m.running, 0)
+
+	m.ctx, m.ctxStop = context.WithCancel(ctx)
+	defer m.ctxStop()
+
+	m.replay = &replayProvider{blocks: blocks}
+	defer func() { m.replay = nil }()
+
+	// broadcast published events to all subscribers, until all the blocks are replayed
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-m.ctx.Done():
+				return
+			case events := <-m.publishCh:
+				m.broadcast(events)
+			}
+		}
+	}()
+	defer func() {
+		m.ctxStop()
+		wg.Wait()
+	}()
+
+	for i, block := range blocks {
+		m.replay.pos = i
+
+		if head := m.chain.Head(); head != nil && block.NumberU64() <= head.NumberU64() {
+			continue
+		}
+
+		events, err := m.buildCanonicalChain(m.ctx, block, Blocks{})
+		if err != nil {
+			return fmt.Errorf("ethmonitor: failed to replay block #%d hash:%s: %w", block.NumberU64(), block.Hash().Hex(), err)
+		}
+
+		m.chain.updateReorgAncestor(events)
+		m.reportReorg(events)
+		m.recordReorg(events)
+		m.metrics.observeEvents(events)
+
+		for _, b := range events {
+			b.OK = true
+		}
+
+		err = m.publish(m.ctx, events)
+		if err != nil {
+			return superr.New(ErrFatal, err)
+		}
+	}
+	return nil
+}
+
+// replayProvider serves the replayed blocks, as a node would have when the block at pos
+// was its latest block.
+type replayProvider struct {
+	blocks []*types.Block
+	pos    int
+}
+
+func (p *replayProvider) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	if number == nil {
+		return p.blocks[p.pos], nil
+	}
+	for i := p.pos; i >= 0; i-- {
+		if p.blocks[i].Number().Cmp(number) == 0 {
+			return p.blocks[i], nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+func (p *replayProvider) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	for i := len(p.blocks) - 1; i >= 0; i-- {
+		if p.blocks[i].Hash() == hash {
+			return p.blocks[i], nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
@@ -0,0 +1,67 @@
+package ethmonitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	opts := DefaultOptions
+	opts.PollingInterval = time.Second
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	// a captured sequence of blocks, where the node switched to a fork of block 3 once
+	// the fork was longer
+	bc := mockChain(nil, 0, 5)
+	fork := mockChain(bc, 3, 6)
+	blocks := append([]*types.Block{}, bc...)
+	blocks = append(blocks, fork[3:]...)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- monitor.Replay(context.Background(), blocks)
+	}()
+
+	events := Blocks{}
+	for len(events) == 0 || events.Head().Hash() != fork[5].Hash() {
+		select {
+		case published := <-sub.Blocks():
+			events = append(events, published...)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("replay did not return")
+	}
+
+	expected := []struct {
+		event Event
+		block *types.Block
+	}{
+		{Added, bc[0]}, {Added, bc[1]}, {Added, bc[2]}, {Added, bc[3]}, {Added, bc[4]},
+		{Removed, bc[4]}, {Removed, bc[3]}, {Added, fork[3]}, {Added, fork[4]}, {Added, fork[5]},
+	}
+	require.Len(t, events, len(expected))
+	for i, e := range expected {
+		require.Equal(t, e.event, events[i].Event)
+		require.Equal(t, e.block.Hash(), events[i].Hash())
+	}
+
+	ancestor, ok := monitor.LastReorgAncestor()
+	require.True(t, ok)
+	require.Equal(t, bc[2].Hash(), ancestor.Hash())
+	require.False(t, monitor.IsRunning())
+}
@@ -0,0 +1,109 @@
+package ethmonitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// resetRequest is a request to Reset the monitor, handled by the run loop.
+type resetRequest struct {
+	startBlock *big.Int
+	done       chan struct{}
+
+	// head overrides the head of the chain for the Reset event, ie. when blocks were
+	// already popped from the chain before the reset
+	head *Block
+}
+
+// Reset restarts the running monitor from the startBlock, or from the latest block when
+// nil, ie. to reprocess the chain after a downstream store was corrupted. The retained
+// chain, publish queue and derived state such as the log index and finality are cleared,
+// and subscribers stay attached.
+//
+// Subscribers are sent a single Reset event of the previous head of the chain, marking the
+// discontinuity, after which events restart with the Added events from the start block.
+// No Removed events are published for the blocks before the reset, and any events which
+// were not yet published, ie. held back by TrailNumBlocksBehindHead, are dropped. Reset
+// returns once the monitor has been reset, before it fetches the start block.
+func (m *Monitor) Reset(ctx context.Context, startBlock *big.Int) error {
+	if !m.IsRunning() {
+		return fmt.Errorf("ethmonitor: cannot reset, monitor is not running")
+	}
+	if startBlock != nil && startBlock.Sign() < 0 {
+		return fmt.Errorf("ethmonitor: cannot reset to negative block number %d", startBlock)
+	}
+
+	req := resetRequest{done: make(chan struct{})}
+	if startBlock != nil {
+		req.startBlock = big.NewInt(0).Set(startBlock)
+	}
+
+	select {
+	case m.resetCh <- req:
+	case <-m.ctx.Done():
+		return errors.New("ethmonitor: cannot reset, monitor has stopped")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-req.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reset handles a reset request on the run loop.
+func (m *Monitor) reset(req resetRequest) {
+	defer close(req.done)
+
+	head := m.chain.Head()
+	if req.head != nil {
+		head = req.head
+	}
+
+	m.chain.mu.Lock()
+	for i := range m.chain.blocks {
+		m.chain.blocks[i] = nil
+	}
+	m.chain.blocks = m.chain.blocks[:0]
+	m.chain.averageBlockTime = 0
+	m.chain.reorgAncestor = nil
+	m.chain.mu.Unlock()
+
+	m.publishQueue.clear()
+	m.metrics.setPublishQueueLen(0)
+	if m.logIndex != nil {
+		m.logIndex.clear()
+	}
+
+	m.mu.Lock()
+	m.finality = finality{}
+	m.reorgHistory = nil
+	m.reorgHistoryFloor = 0
+	m.mu.Unlock()
+
+	m.resetFinalizedEvents()
+	m.resetSynced()
+	m.resetStall()
+	m.catchUpHead, m.catchUpCheckedAt = 0, 0
+	m.crossCheckedBlockNum = 0
+	m.nextBlockNumber = req.startBlock
+
+	if req.startBlock == nil {
+		m.log.Warnf("ethmonitor: reset, restarting from block=latest")
+	} else {
+		m.log.Warnf("ethmonitor: reset, restarting from block=%d", req.startBlock)
+	}
+
+	if head == nil {
+		return
+	}
+	select {
+	case m.publishCh <- Blocks{{Block: head.Block, Event: Reset, OK: true}}:
+	case <-m.ctx.Done():
+	}
+}
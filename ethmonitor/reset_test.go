@@ -0,0 +1,77 @@
+package ethmonitor
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReset(t *testing.T) {
+	for _, debug := range []bool{false, true} {
+		testReset(t, debug)
+	}
+}
+
+func testReset(t *testing.T, debugLogging bool) {
+	bc := mockChain(nil, 0, 10)
+
+	var chain atomic.Value
+	chain.Store(bc)
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.DebugLogging = debugLogging
+
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+
+	// not running
+	require.Error(t, monitor.Reset(context.Background(), nil))
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	next := func() Blocks {
+		select {
+		case blocks := <-sub.Blocks():
+			return blocks
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+		return nil
+	}
+
+	for num := uint64(0); num < 10; {
+		num = next().LatestBlock().NumberU64()
+	}
+
+	require.NoError(t, monitor.Reset(ctx, big.NewInt(5)))
+
+	// the reset is marked by the previous head
+	blocks := next()
+	require.Len(t, blocks, 1)
+	require.Equal(t, Reset, blocks[0].Event)
+	require.Equal(t, bc[9].Hash(), blocks[0].Hash())
+
+	// and followed by the blocks from the start block
+	expected := uint64(5)
+	for expected <= 10 {
+		for _, b := range next() {
+			require.Equal(t, Added, b.Event)
+			require.Equal(t, expected, b.NumberU64())
+			expected++
+		}
+	}
+	require.Equal(t, bc[4].Hash(), monitor.Chain().Tail().Hash())
+
+	require.Error(t, monitor.Reset(ctx, big.NewInt(-1)))
+}
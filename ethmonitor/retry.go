@@ -0,0 +1,94 @@
+package ethmonitor
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy determines how the monitor retries fetching a block from the provider
+// after a failure, see Options.RetryPolicy.
+type RetryPolicy interface {
+	// Backoff returns how long to wait before the next attempt, after the given number
+	// of failed attempts, starting at 1.
+	Backoff(attempt int) time.Duration
+
+	// MaxAttempts returns the number of failed attempts after which the fetch fails.
+	MaxAttempts() int
+}
+
+// LinearRetryPolicy waits twice the interval longer after every failed attempt, ie.
+// 2*interval, 4*interval, 6*interval, etc. It is the default policy, with the
+// PollingInterval and 10 attempts.
+func LinearRetryPolicy(interval time.Duration, maxAttempts int) RetryPolicy {
+	return &linearRetryPolicy{interval: interval, maxAttempts: maxAttempts}
+}
+
+type linearRetryPolicy struct {
+	interval    time.Duration
+	maxAttempts int
+}
+
+func (p *linearRetryPolicy) Backoff(attempt int) time.Duration {
+	return p.interval * time.Duration(attempt) * 2
+}
+
+func (p *linearRetryPolicy) MaxAttempts() int {
+	return p.maxAttempts
+}
+
+// ExponentialRetryPolicy doubles the backoff after every failed attempt, starting from
+// base and capped at maxBackoff. A random jitter of up to half the backoff is subtracted,
+// so monitors sharing a provider don't retry in lockstep.
+func ExponentialRetryPolicy(base, maxBackoff time.Duration, maxAttempts int) RetryPolicy {
+	return &exponentialRetryPolicy{base: base, maxBackoff: maxBackoff, maxAttempts: maxAttempts}
+}
+
+type exponentialRetryPolicy struct {
+	base        time.Duration
+	maxBackoff  time.Duration
+	maxAttempts int
+}
+
+func (p *exponentialRetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := p.base
+	for i := 1; i < attempt && backoff < p.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.maxBackoff {
+		backoff = p.maxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff - time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+func (p *exponentialRetryPolicy) MaxAttempts() int {
+	return p.maxAttempts
+}
+
+// retryBackoff waits for the RetryPolicy's backoff after the given number of failed
+// attempts, or until the context is done.
+func (m *Monitor) retryBackoff(ctx context.Context, attempt int) {
+	backoff := m.options.RetryPolicy.Backoff(attempt)
+	if backoff <= 0 {
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(backoff):
+	}
+}
+
+// jitter returns the poll interval randomized by up to +/- Options.PollingJitter.
+func (m *Monitor) jitter(interval time.Duration) time.Duration {
+	if m.options.PollingJitter <= 0 {
+		return interval
+	}
+	interval += time.Duration(rand.Int63n(2*int64(m.options.PollingJitter)+1)) - m.options.PollingJitter
+	if interval < 0 {
+		return 0
+	}
+	return interval
+}
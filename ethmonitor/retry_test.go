@@ -0,0 +1,139 @@
+package ethmonitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+type noDelayRetryPolicy struct {
+	maxAttempts int
+}
+
+func (p noDelayRetryPolicy) Backoff(attempt int) time.Duration { return 0 }
+func (p noDelayRetryPolicy) MaxAttempts() int                  { return p.maxAttempts }
+
+func TestRetryPolicy(t *testing.T) {
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(0)})
+
+	var failures, requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		if atomic.AddInt32(&requests, 1) <= atomic.LoadInt32(&failures) {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		var result map[string]interface{}
+		data, _ := json.Marshal(block.Header())
+		json.Unmarshal(data, &result)
+		result["transactions"] = []interface{}{}
+		result["uncles"] = []interface{}{}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+
+	opts := DefaultOptions
+	opts.PollingInterval = time.Hour // the default policy would never retry within the test
+	opts.RetryPolicy = noDelayRetryPolicy{maxAttempts: 3}
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+
+	// succeeds after two failures
+	atomic.StoreInt32(&failures, 2)
+	fetched, err := monitor.fetchBlockByNumber(context.Background(), big.NewInt(1))
+	require.NoError(t, err)
+	require.Equal(t, block.Hash(), fetched.Hash())
+	require.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	fetched, err = monitor.fetchBlockByHash(context.Background(), block.Hash())
+	require.NoError(t, err)
+	require.Equal(t, block.Hash(), fetched.Hash())
+
+	// gives up after the max attempts
+	atomic.StoreInt32(&requests, 0)
+	atomic.StoreInt32(&failures, 100)
+	_, err = monitor.fetchBlockByNumber(context.Background(), big.NewInt(1))
+	require.True(t, errors.Is(err, ErrMaxAttempts))
+	require.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	atomic.StoreInt32(&requests, 0)
+	_, err = monitor.fetchBlockByHash(context.Background(), block.Hash())
+	require.True(t, errors.Is(err, ErrMaxAttempts))
+	require.False(t, errors.Is(err, ethereum.NotFound))
+	require.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	opts := DefaultOptions
+	opts.PollingInterval = time.Second
+
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	policy := monitor.Options().RetryPolicy
+	require.NotNil(t, policy)
+	require.Equal(t, 10, policy.MaxAttempts())
+	require.Equal(t, 2*time.Second, policy.Backoff(1))
+	require.Equal(t, 6*time.Second, policy.Backoff(3))
+}
+
+func TestExponentialRetryPolicy(t *testing.T) {
+	policy := ExponentialRetryPolicy(100*time.Millisecond, time.Second, 5)
+	require.Equal(t, 5, policy.MaxAttempts())
+
+	for i := 0; i < 100; i++ {
+		backoff := policy.Backoff(1)
+		require.True(t, backoff >= 50*time.Millisecond && backoff <= 100*time.Millisecond, backoff)
+
+		backoff = policy.Backoff(3)
+		require.True(t, backoff >= 200*time.Millisecond && backoff <= 400*time.Millisecond, backoff)
+
+		// capped
+		backoff = policy.Backoff(50)
+		require.True(t, backoff >= 500*time.Millisecond && backoff <= time.Second, backoff)
+	}
+}
+
+func TestPollingJitter(t *testing.T) {
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+	require.Equal(t, time.Second, monitor.jitter(time.Second))
+
+	opts := DefaultOptions
+	opts.PollingJitter = 100 * time.Millisecond
+	monitor, err = NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 1000; i++ {
+		d := monitor.jitter(time.Second)
+		require.GreaterOrEqual(t, d, 900*time.Millisecond)
+		require.LessOrEqual(t, d, 1100*time.Millisecond)
+		seen[d] = true
+
+		require.GreaterOrEqual(t, monitor.jitter(10*time.Millisecond), time.Duration(0))
+	}
+	require.Greater(t, len(seen), 1)
+}
@@ -0,0 +1,124 @@
+package ethmonitor
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/goware/superr"
+)
+
+// monitorSparse is the run loop of the monitor when Options.SparseSampling is set, which
+// fetches and publishes every Nth block of the chain. The sampled blocks are not linked
+// by their parent hashes, and so reorgs are not detected in this mode.
+func (m *Monitor) monitorSparse() error {
+	ctx := m.ctx
+	sampling := uint64(m.options.SparseSampling)
+
+	// pollInterval is used for adaptive interval
+	pollInterval := m.options.PollingInterval
+
+	for {
+		select {
+
+		case <-m.ctx.Done():
+			return nil
+
+		case <-m.resumeCh:
+
+		case req := <-m.resetCh:
+			m.reset(req)
+			pollInterval = m.options.PollingInterval
+
+		case <-time.After(m.jitter(pollInterval)):
+		}
+
+		m.setPollInterval(pollInterval)
+
+		if m.IsPaused() {
+			m.resetStall()
+			pollInterval = m.options.PollingInterval
+			continue
+		}
+
+		m.tick()
+		m.checkStall()
+
+		headBlock := m.chain.Head()
+		if headBlock != nil {
+			m.nextBlockNumber = big.NewInt(0).SetUint64(headBlock.NumberU64() + sampling)
+		} else {
+			nextBlockNumber, err := m.nextSampledBlockNumber(ctx, sampling)
+			if err != nil {
+				m.log.Warnf("ethmonitor: [retrying] failed to fetch latest block, due to: %v", err)
+				pollInterval = m.options.PollingInterval
+				continue
+			}
+			m.nextBlockNumber = nextBlockNumber
+		}
+
+		nextBlock, err := m.fetchBlockByNumber(ctx, m.nextBlockNumber)
+		if err == ethereum.NotFound {
+			// the next sampled block is not mined yet
+			if headBlock != nil {
+				m.observePoll(headBlock.NumberU64())
+			}
+			m.checkSynced(ctx, headBlock != nil)
+			pollInterval = m.options.PollingInterval
+			continue
+		}
+		if err != nil {
+			m.log.Warnf("ethmonitor: [retrying] failed to fetch next sampled block # %d, due to: %v", m.nextBlockNumber, err)
+			pollInterval = m.options.PollingInterval
+			continue
+		}
+
+		m.observePoll(nextBlock.NumberU64())
+
+		// speed up the poll interval if we found the next block
+		pollInterval /= 2
+
+		if err := m.validateBlock(nextBlock); err != nil {
+			pollInterval = m.options.PollingInterval
+			continue
+		}
+		m.validateTimestamp(headBlock, nextBlock)
+
+		block := &Block{Event: Added, Block: nextBlock}
+		if err := m.chain.push(block); err != nil {
+			m.log.Warnf("ethmonitor: failed to push sampled block #%d, due to: %v", nextBlock.NumberU64(), err)
+			pollInterval = m.options.PollingInterval
+			continue
+		}
+		events := Blocks{block}
+		m.metrics.observeEvents(events)
+
+		m.addBlockData(ctx, events)
+
+		err = m.publish(ctx, events)
+		if err != nil {
+			return superr.New(ErrFatal, err)
+		}
+
+		m.checkSynced(ctx, false)
+	}
+}
+
+// nextSampledBlockNumber returns the first sampled block number at or after the block
+// the monitor starts from, which is the latest block unless a start block was set.
+func (m *Monitor) nextSampledBlockNumber(ctx context.Context, sampling uint64) (*big.Int, error) {
+	num := m.nextBlockNumber
+	if num == nil {
+		latestBlock, err := m.fetchBlockByNumber(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		num = latestBlock.Number()
+	}
+	n := num.Uint64()
+	if n%sampling != 0 {
+		n += sampling - n%sampling
+	}
+	return big.NewInt(0).SetUint64(n), nil
+}
@@ -0,0 +1,66 @@
+package ethmonitor
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSparseSampling(t *testing.T) {
+	var chain atomic.Value
+	chain.Store(mockChain(nil, 0, 10))
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.SparseSampling = 3
+
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	expected := []uint64{3, 6, 9}
+	for len(expected) > 0 {
+		select {
+		case blocks := <-sub.Blocks():
+			for _, b := range blocks {
+				require.Equal(t, Added, b.Event)
+				require.True(t, b.OK)
+				require.Equal(t, expected[0], b.NumberU64())
+				expected = expected[1:]
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+
+	// the next sampled block is published once the chain reaches it
+	chain.Store(mockChain(nil, 0, 12))
+
+	select {
+	case blocks := <-sub.Blocks():
+		require.Equal(t, uint64(12), blocks.LatestBlock().NumberU64())
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for events")
+	}
+	require.Equal(t, uint64(12), monitor.LatestBlock().NumberU64())
+	require.Len(t, monitor.Chain().Blocks(), 4)
+}
+
+func TestSparseSamplingNegative(t *testing.T) {
+	opts := DefaultOptions
+	opts.SparseSampling = -1
+
+	_, err := NewMonitor(nil, opts)
+	require.Error(t, err)
+}
@@ -0,0 +1,57 @@
+package ethmonitor
+
+import (
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+)
+
+// stall tracks how long the head of the chain has not advanced, see Options.StallTimeout.
+// It's only accessed by the run loop.
+type stall struct {
+	head    common.Hash
+	since   time.Time
+	reports int
+}
+
+// resetStall restarts the stall timer on the next check, ie. after a pause.
+func (m *Monitor) resetStall() {
+	m.stall = stall{}
+}
+
+// checkStall reports a stall once no new head was accepted for Options.StallTimeout, and
+// again every StallTimeout for as long as the stall lasts. The timer restarts whenever the
+// head of the chain changes.
+func (m *Monitor) checkStall() {
+	if m.options.StallTimeout <= 0 {
+		return
+	}
+
+	now := time.Now()
+	head := m.chain.Head()
+
+	var headHash common.Hash
+	if head != nil {
+		headHash = head.Hash()
+	}
+	if m.stall.since.IsZero() || m.stall.head != headHash {
+		m.stall = stall{head: headHash, since: now}
+		return
+	}
+
+	since := now.Sub(m.stall.since)
+	if since < m.options.StallTimeout*time.Duration(m.stall.reports+1) {
+		return
+	}
+	m.stall.reports++
+
+	if head != nil {
+		m.log.Warnf("ethmonitor: stalled, no new block for %s since block #%d hash:%s, the provider may be lagging", since.Round(time.Millisecond), head.NumberU64(), head.Hash().Hex())
+	} else {
+		m.log.Warnf("ethmonitor: stalled, no block for %s since the monitor started, the provider may be lagging", since.Round(time.Millisecond))
+	}
+
+	if m.options.OnStall != nil {
+		m.options.OnStall(head, since)
+	}
+}
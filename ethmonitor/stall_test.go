@@ -0,0 +1,68 @@
+package ethmonitor
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStallTimeout(t *testing.T) {
+	var chain atomic.Value
+	chain.Store(mockChain(nil, 0, 3))
+
+	type stallReport struct {
+		head  *Block
+		since time.Duration
+	}
+	stalls := make(chan stallReport, 10)
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.StallTimeout = 100 * time.Millisecond
+	opts.OnStall = func(head *Block, since time.Duration) {
+		stalls <- stallReport{head, since}
+	}
+
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	// the provider stops advancing at block 3
+	select {
+	case stall := <-stalls:
+		require.Equal(t, uint64(3), stall.head.NumberU64())
+		require.GreaterOrEqual(t, stall.since, opts.StallTimeout)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stall was not reported")
+	}
+
+	// a new head restarts the stall timer
+	chain.Store(mockChain(nil, 0, 4))
+	require.Eventually(t, func() bool {
+		head := monitor.LatestBlock()
+		return head != nil && head.NumberU64() == 4
+	}, 5*time.Second, time.Millisecond)
+
+	// drain any stall reported before the new head was accepted
+	for len(stalls) > 0 {
+		<-stalls
+	}
+
+	select {
+	case stall := <-stalls:
+		require.Equal(t, uint64(4), stall.head.NumberU64())
+	case <-time.After(5 * time.Second):
+		t.Fatal("stall was not reported")
+	}
+}
@@ -0,0 +1,84 @@
+package ethmonitor
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartBlockHash(t *testing.T) {
+	bc := mockChain(nil, 0, 10)
+
+	var chain atomic.Value
+	chain.Store(bc)
+
+	startHash := bc[4].Hash()
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockHash = &startHash
+
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+
+	sub := monitor.Subscribe()
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	// the start block is not published, and the monitor begins from its child
+	select {
+	case blocks := <-sub.Blocks():
+		require.Equal(t, Added, blocks[0].Event)
+		require.Equal(t, uint64(6), blocks[0].NumberU64())
+		require.Equal(t, startHash, blocks[0].ParentHash())
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for events")
+	}
+	require.Equal(t, startHash, monitor.Chain().Tail().Hash())
+}
+
+func TestStartBlockHashNotFound(t *testing.T) {
+	bc := mockChain(nil, 0, 10)
+
+	// the node serves the forked block #5 by hash, but it's not on its canonical chain
+	forked := mockChain(bc, 4, 5)[4]
+
+	var chain atomic.Value
+	chain.Store(append(append([]*types.Block{}, bc...), forked))
+
+	cases := []struct {
+		name      string
+		hash      common.Hash
+		number    *big.Int
+		errSubstr string
+	}{
+		{"unknown hash", common.HexToHash("0x01"), nil, "not found"},
+		{"forked hash", forked.Hash(), nil, "was forked"},
+		{"number mismatch", bc[4].Hash(), big.NewInt(4), "not StartBlockNumber #4"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := DefaultOptions
+			opts.PollingInterval = time.Millisecond
+			opts.StartBlockHash = &c.hash
+			opts.StartBlockNumber = c.number
+
+			monitor, err := NewMonitor(mockNode(t, &chain), opts)
+			require.NoError(t, err)
+
+			err = monitor.Run(context.Background())
+			require.ErrorIs(t, err, ErrStartBlockNotFound)
+			require.Contains(t, err.Error(), c.errSubstr)
+			require.Nil(t, monitor.LatestBlock())
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package ethmonitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// StateStore persists the state of the monitor across restarts, see Options.StateStore.
+// The state is a Chain.Snapshot, and Load returns nil data when there is no saved state.
+type StateStore interface {
+	Save(data []byte) error
+	Load() ([]byte, error)
+}
+
+// NewFileStateStore returns a StateStore which persists the state to the file at path.
+// The file is replaced atomically on save, so a crash while saving leaves the previous
+// state intact.
+func NewFileStateStore(path string) StateStore {
+	return &fileStateStore{path: path}
+}
+
+type fileStateStore struct {
+	path string
+}
+
+func (s *fileStateStore) Save(data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *fileStateStore) Load() ([]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// loadState restores the chain from the state store, if it holds a saved state.
+func (m *Monitor) loadState() error {
+	data, err := m.options.StateStore.Load()
+	if err != nil {
+		return fmt.Errorf("ethmonitor: failed to load state: %w", err)
+	}
+	if len(data) == 0 {
+		// nothing saved yet, so start with an empty chain from StartBlockNumber
+		return m.chain.bootstrapBlocks(nil)
+	}
+
+	err = m.LoadSnapshot(data)
+	if err != nil {
+		return fmt.Errorf("ethmonitor: failed to restore state: %w", err)
+	}
+
+	if head := m.chain.Head(); head != nil {
+		m.log.Infof("ethmonitor: restored state with head block #%d %s", head.NumberU64(), head.Hash().Hex())
+	}
+	return nil
+}
+
+// saveState saves the chain up to the last published block to the state store, so
+// events still held in the publish queue are published again after a restore. Failures
+// are logged, so they don't block the shutdown of the monitor.
+func (m *Monitor) saveState() {
+	if m.options.StateStore == nil {
+		return
+	}
+
+	blocks := m.publishedChain()
+	if len(blocks) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(blocks)
+	if err != nil {
+		m.log.Errorf("ethmonitor: failed to snapshot state: %v", err)
+		return
+	}
+
+	err = m.options.StateStore.Save(data)
+	if err != nil {
+		m.log.Errorf("ethmonitor: failed to save state: %v", err)
+		return
+	}
+
+	m.log.Infof("ethmonitor: saved state with head block #%d", blocks.Head().NumberU64())
+}
+
+// publishedChain returns the chain as subscribers have seen it, ie. the canonical chain
+// with the events still held in the publish queue reverted.
+func (m *Monitor) publishedChain() Blocks {
+	m.chain.mu.Lock()
+	blocks := append(Blocks{}, m.chain.blocks...)
+	m.chain.mu.Unlock()
+
+	m.publishQueue.mu.Lock()
+	pending := append(Blocks{}, m.publishQueue.events...)
+	m.publishQueue.mu.Unlock()
+
+	for i := len(pending) - 1; i >= 0; i-- {
+		switch ev := pending[i]; ev.Event {
+		case Added:
+			if n := len(blocks); n > 0 && blocks[n-1].Hash() == ev.Hash() {
+				blocks = blocks[:n-1]
+			}
+		case Removed:
+			block := *ev
+			block.Event = Added
+			blocks = append(blocks, &block)
+		}
+	}
+	return blocks
+}
@@ -0,0 +1,200 @@
+package ethmonitor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type failingStateStore struct {
+	saves int32
+}
+
+func (s *failingStateStore) Save(data []byte) error {
+	atomic.AddInt32(&s.saves, 1)
+	return errors.New("disk full")
+}
+
+func (s *failingStateStore) Load() ([]byte, error) {
+	return nil, nil
+}
+
+func TestStateStore(t *testing.T) {
+	var chain atomic.Value
+	chain.Store(mockChain(nil, 0, 5))
+
+	store := NewFileStateStore(filepath.Join(t.TempDir(), "state.json"))
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.StateStore = store
+
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() { done <- monitor.Run(context.Background()) }()
+
+	require.Eventually(t, func() bool {
+		head := monitor.LatestBlock()
+		return head != nil && head.NumberU64() == 5
+	}, 5*time.Second, 5*time.Millisecond)
+
+	monitor.Stop()
+	require.NoError(t, <-done)
+
+	data, err := store.Load()
+	require.NoError(t, err)
+	require.NotEmpty(t, data)
+
+	// the next monitor resumes from the saved state
+	chain.Store(mockChain(nil, 0, 7))
+
+	opts.Bootstrap = true
+	monitor, err = NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), monitor.LatestBlock().NumberU64())
+
+	sub := monitor.Subscribe()
+
+	// wait for Run to return, as it saves the state into the temp dir on stop
+	go func() { done <- monitor.Run(context.Background()) }()
+	defer func() {
+		monitor.Stop()
+		<-done
+	}()
+
+	var next uint64 = 6
+	for next <= 7 {
+		select {
+		case blocks := <-sub.Blocks():
+			for _, b := range blocks {
+				require.Equal(t, next, b.NumberU64())
+				next++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+}
+
+func TestStateStoreSaveFailure(t *testing.T) {
+	var chain atomic.Value
+	chain.Store(mockChain(nil, 0, 2))
+
+	store := &failingStateStore{}
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.StateStore = store
+
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() { done <- monitor.Run(context.Background()) }()
+
+	require.Eventually(t, func() bool { return monitor.LatestBlock() != nil }, 5*time.Second, 5*time.Millisecond)
+	monitor.Stop()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("monitor did not stop")
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&store.saves))
+}
+
+func TestStateStoreEmpty(t *testing.T) {
+	var chain atomic.Value
+	chain.Store(mockChain(nil, 0, 3))
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.StateStore = NewFileStateStore(filepath.Join(t.TempDir(), "state.json"))
+	opts.Bootstrap = true
+
+	// the first start has no saved state, so the monitor starts from StartBlockNumber
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+	require.Nil(t, monitor.LatestBlock())
+
+	done := make(chan error)
+	go func() { done <- monitor.Run(context.Background()) }()
+
+	require.Eventually(t, func() bool {
+		head := monitor.LatestBlock()
+		return head != nil && head.NumberU64() == 3
+	}, 5*time.Second, 5*time.Millisecond)
+
+	monitor.Stop()
+	require.NoError(t, <-done)
+}
+
+func TestStateStoreTrailing(t *testing.T) {
+	var chain atomic.Value
+	chain.Store(mockChain(nil, 0, 5))
+
+	store := NewFileStateStore(filepath.Join(t.TempDir(), "state.json"))
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+	opts.StateStore = store
+	opts.TrailNumBlocksBehindHead = 2
+
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() { done <- monitor.Run(context.Background()) }()
+
+	require.Eventually(t, func() bool {
+		head := monitor.LatestBlock()
+		return head != nil && head.NumberU64() == 5
+	}, 5*time.Second, 5*time.Millisecond)
+
+	monitor.Stop()
+	require.NoError(t, <-done)
+
+	// blocks 4 and 5 were held back, so the saved state ends at block 3
+	chain.Store(mockChain(nil, 0, 7))
+
+	opts.Bootstrap = true
+	monitor, err = NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), monitor.LatestBlock().NumberU64())
+
+	sub := monitor.Subscribe()
+
+	// wait for Run to return, as it saves the state into the temp dir on stop
+	go func() { done <- monitor.Run(context.Background()) }()
+	defer func() {
+		monitor.Stop()
+		<-done
+	}()
+
+	var next uint64 = 4
+	for next <= 5 {
+		select {
+		case blocks := <-sub.Blocks():
+			for _, b := range blocks {
+				require.Equal(t, Added, b.Event)
+				require.Equal(t, next, b.NumberU64())
+				next++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package ethmonitor
+
+import (
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+)
+
+// MonitorStatus is a snapshot of the health of the monitor, see Monitor.Status.
+type MonitorStatus struct {
+	// Running is true while Run is running, and Paused while the monitor is paused.
+	Running bool
+	Paused  bool
+
+	// Synced is true once the monitor caught up with the head of the node, see Synced.
+	Synced bool
+
+	// HeadBlockNum and HeadBlockHash identify the head of the retained canonical chain,
+	// and are zero if no block was accepted yet.
+	HeadBlockNum  uint64
+	HeadBlockHash common.Hash
+
+	// RemoteHeadNum is the latest block number known to be on the node, as last observed
+	// by the monitor, and Lag is the number of blocks the head is behind it.
+	RemoteHeadNum uint64
+	Lag           uint64
+
+	// LastPollAt is the time of the last successful poll of the node, whether it found a
+	// new block or not, and is zero if the node was never polled successfully.
+	LastPollAt time.Time
+
+	// PollInterval is the current adaptive interval between polls.
+	PollInterval time.Duration
+}
+
+// Ready reports if the monitor is running, within maxLag blocks of the node's head, and
+// polled the node successfully within maxPollAge, ie. for readiness probes. A maxPollAge of
+// 0 skips the poll check, and Options.StallTimeout is generally a good value for it.
+func (s MonitorStatus) Ready(maxLag uint64, maxPollAge time.Duration) bool {
+	if !s.Running || s.HeadBlockNum == 0 || s.Lag > maxLag {
+		return false
+	}
+	if maxPollAge > 0 && (s.LastPollAt.IsZero() || time.Since(s.LastPollAt) > maxPollAge) {
+		return false
+	}
+	return true
+}
+
+// Status returns a snapshot of the health of the monitor, computed from its internal state
+// without querying the node, ie. for liveness and readiness probes.
+func (m *Monitor) Status() MonitorStatus {
+	status := MonitorStatus{
+		Running:       m.IsRunning(),
+		Paused:        m.IsPaused(),
+		Synced:        m.IsSynced(),
+		RemoteHeadNum: atomic.LoadUint64(&m.remoteHeadNum),
+		PollInterval:  time.Duration(atomic.LoadInt64(&m.pollInterval)),
+	}
+
+	if lastPollAt := atomic.LoadInt64(&m.lastPollAt); lastPollAt > 0 {
+		status.LastPollAt = time.Unix(0, lastPollAt)
+	}
+
+	if head := m.chain.Head(); head != nil {
+		status.HeadBlockNum = head.NumberU64()
+		status.HeadBlockHash = head.Hash()
+	}
+	if status.RemoteHeadNum > status.HeadBlockNum {
+		status.Lag = status.RemoteHeadNum - status.HeadBlockNum
+	}
+
+	return status
+}
+
+// NetworkLatestBlockNum returns the latest block number known to be on the node, as last
+// observed while polling, without querying the node. Unlike LatestBlockNum, which is the
+// head of the retained chain, it's the tip of the network as seen by the node, and is 0
+// until the node was polled.
+func (m *Monitor) NetworkLatestBlockNum() *big.Int {
+	return big.NewInt(0).SetUint64(atomic.LoadUint64(&m.remoteHeadNum))
+}
+
+// Lag returns the number of blocks the head of the retained chain is behind the
+// NetworkLatestBlockNum, ie. for progress bars and readiness checks.
+func (m *Monitor) Lag() *big.Int {
+	lag := big.NewInt(0).Sub(m.NetworkLatestBlockNum(), m.LatestBlockNum())
+	if lag.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return lag
+}
+
+// setPollInterval records the current poll interval.
+func (m *Monitor) setPollInterval(d time.Duration) {
+	atomic.StoreInt64(&m.pollInterval, int64(d))
+	m.metrics.setPollInterval(d)
+}
+
+// observePoll records a successful poll of the node, which has at least the remote head.
+func (m *Monitor) observePoll(remoteHeadNum uint64) {
+	atomic.StoreInt64(&m.lastPollAt, time.Now().UnixNano())
+	m.observeRemoteHead(remoteHeadNum)
+}
+
+// observeRemoteHead records the latest block number known to be on the node.
+func (m *Monitor) observeRemoteHead(remoteHeadNum uint64) {
+	for {
+		current := atomic.LoadUint64(&m.remoteHeadNum)
+		if remoteHeadNum <= current || atomic.CompareAndSwapUint64(&m.remoteHeadNum, current, remoteHeadNum) {
+			return
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package ethmonitor
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus(t *testing.T) {
+	bc := mockChain(nil, 0, 10)
+
+	var chain atomic.Value
+	chain.Store(bc)
+
+	opts := DefaultOptions
+	opts.PollingInterval = 5 * time.Millisecond
+	opts.StartBlockNumber = big.NewInt(1)
+
+	monitor, err := NewMonitor(mockNode(t, &chain), opts)
+	require.NoError(t, err)
+
+	status := monitor.Status()
+	require.False(t, status.Running)
+	require.True(t, status.LastPollAt.IsZero())
+	require.False(t, status.Ready(0, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	// once the monitor caught up and polled the node at its head
+	require.Eventually(t, func() bool {
+		status := monitor.Status()
+		return status.HeadBlockNum == 10 && status.LastPollAt.After(time.Now().Add(-time.Second))
+	}, 5*time.Second, time.Millisecond)
+
+	status = monitor.Status()
+	require.True(t, status.Running)
+	require.False(t, status.Paused)
+	require.Equal(t, bc[9].Hash(), status.HeadBlockHash)
+	require.Equal(t, uint64(10), status.RemoteHeadNum)
+	require.Equal(t, uint64(0), status.Lag)
+	require.NotZero(t, status.PollInterval)
+	require.True(t, status.Ready(0, time.Second))
+
+	// lagging behind the node
+	status.RemoteHeadNum, status.Lag = 15, 5
+	require.False(t, status.Ready(4, 0))
+	require.True(t, status.Ready(5, 0))
+
+	// not polled recently
+	status.LastPollAt = time.Now().Add(-time.Minute)
+	require.False(t, status.Ready(5, time.Second))
+	require.True(t, status.Ready(5, 0))
+}
+
+func TestNetworkLatestBlockNum(t *testing.T) {
+	bc := mockChain(nil, 0, 10)
+	provider := &fakeProvider{}
+	provider.bc.Store(bc)
+
+	monitor, err := NewMonitor(provider)
+	require.NoError(t, err)
+	require.Zero(t, monitor.NetworkLatestBlockNum().Sign())
+	require.Zero(t, monitor.Lag().Sign())
+
+	// fetching the latest block reveals the tip of the node
+	_, err = monitor.fetchBlockByNumber(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), monitor.NetworkLatestBlockNum().Uint64())
+	require.Equal(t, uint64(10), monitor.Lag().Uint64())
+
+	for _, b := range bc[:7] {
+		require.NoError(t, monitor.chain.push(&Block{Block: b, Event: Added, OK: true}))
+	}
+	require.Equal(t, uint64(3), monitor.Lag().Uint64())
+
+	// the observed tip never moves back
+	monitor.observePoll(8)
+	require.Equal(t, uint64(10), monitor.NetworkLatestBlockNum().Uint64())
+
+	monitor.observePoll(12)
+	require.Equal(t, uint64(12), monitor.NetworkLatestBlockNum().Uint64())
+	require.Equal(t, uint64(5), monitor.Lag().Uint64())
+}
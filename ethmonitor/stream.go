@@ -0,0 +1,120 @@
+package ethmonitor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+)
+
+// headStream notifies the monitor of new heads over the provider's websocket endpoint,
+// so the next block is fetched as soon as it's produced instead of on the next poll.
+// A nil headStream is valid, and never delivers.
+type headStream struct {
+	sub   *ethrpc.Subscription
+	heads chan *types.Header
+}
+
+// subscribeNewHeads returns a headStream when StreamingMode is set, or nil if the
+// provider has no websocket endpoint, in which case the monitor falls back to polling.
+// If the subscription fails, nil is returned with the error, and the monitor polls until
+// it re-subscribes, see resubscribeNewHeads.
+func (m *Monitor) subscribeNewHeads(ctx context.Context) (*headStream, error) {
+	if !m.options.StreamingMode || m.rpc == nil {
+		return nil, nil
+	}
+
+	heads := make(chan *types.Header, 16)
+	sub, err := m.rpc.StreamNewHeads(ctx, heads)
+	if errors.Is(err, ethrpc.ErrStreamingUnavailable) {
+		m.log.Warnf("ethmonitor: failed to subscribe to new heads, falling back to polling: %v", err)
+		return nil, nil
+	}
+	if err != nil {
+		m.log.Warnf("ethmonitor: failed to subscribe to new heads, polling until re-subscribed: %v", err)
+		return nil, err
+	}
+	m.log.Info("ethmonitor: streaming new heads")
+	return &headStream{sub: sub, heads: heads}, nil
+}
+
+// headStreamRetry schedules the attempts to re-subscribe to new heads after the stream
+// failed, with a delay doubling from ethrpc.StreamReconnectMinBackoff up to
+// ethrpc.StreamReconnectMaxBackoff. The zero value has no attempt scheduled.
+type headStreamRetry struct {
+	at    time.Time
+	delay time.Duration
+}
+
+// failed schedules the next attempt.
+func (r *headStreamRetry) failed() {
+	if r.delay == 0 {
+		r.delay = ethrpc.StreamReconnectMinBackoff
+	} else if r.delay *= 2; r.delay > ethrpc.StreamReconnectMaxBackoff {
+		r.delay = ethrpc.StreamReconnectMaxBackoff
+	}
+	r.at = time.Now().Add(r.delay)
+}
+
+// resubscribeNewHeads re-subscribes to new heads once the scheduled attempt is due, and
+// returns nil until it succeeds.
+func (m *Monitor) resubscribeNewHeads(ctx context.Context, retry *headStreamRetry) *headStream {
+	if retry.at.IsZero() || time.Now().Before(retry.at) {
+		return nil
+	}
+	heads, err := m.subscribeNewHeads(ctx)
+	if err != nil {
+		retry.failed()
+		return nil
+	}
+	*retry = headStreamRetry{}
+	return heads
+}
+
+func (s *headStream) Heads() <-chan *types.Header {
+	if s == nil {
+		return nil
+	}
+	return s.heads
+}
+
+func (s *headStream) Reconnected() <-chan ethrpc.Reconnect {
+	if s == nil {
+		return nil
+	}
+	return s.sub.Reconnected()
+}
+
+func (s *headStream) Err() <-chan error {
+	if s == nil {
+		return nil
+	}
+	return s.sub.Err()
+}
+
+func (s *headStream) DecodeErr() <-chan error {
+	if s == nil {
+		return nil
+	}
+	return s.sub.DecodeErr()
+}
+
+// drain discards any pending heads, as a single fetch will catch up with all of them.
+func (s *headStream) drain() {
+	for {
+		select {
+		case <-s.heads:
+		default:
+			return
+		}
+	}
+}
+
+func (s *headStream) Unsubscribe() {
+	if s == nil {
+		return
+	}
+	s.sub.Unsubscribe()
+}
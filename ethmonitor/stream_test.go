@@ -0,0 +1,101 @@
+package ethmonitor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamingModeFallback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+
+	opts := DefaultOptions
+	opts.StreamingMode = true
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+
+	// an http-only provider can't stream, so the monitor falls back to polling
+	heads, err := monitor.subscribeNewHeads(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, heads)
+	require.Nil(t, heads.Heads())
+	require.Nil(t, heads.Reconnected())
+	require.Nil(t, heads.Err())
+	require.Nil(t, heads.DecodeErr())
+	heads.Unsubscribe()
+}
+
+func TestStreamingModeResubscribe(t *testing.T) {
+	ethrpc.StreamReconnectMinBackoff = 10 * time.Millisecond
+
+	// the websocket endpoint refuses connections until available is set
+	var available int32
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&available) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var req struct {
+				ID json.RawMessage `json:"id"`
+			}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": "0x1"})
+		}
+	}))
+	defer srv.Close()
+
+	config := &ethrpc.Config{}
+	config.AddNode(ethrpc.NodeConfig{URL: srv.URL, WSURL: "ws" + strings.TrimPrefix(srv.URL, "http")})
+	provider, err := ethrpc.NewProviderWithConfig(config)
+	require.NoError(t, err)
+
+	opts := DefaultOptions
+	opts.StreamingMode = true
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+
+	var retry headStreamRetry
+	heads, err := monitor.subscribeNewHeads(context.Background())
+	require.Error(t, err)
+	require.Nil(t, heads)
+	retry.failed()
+
+	// attempts back off while the endpoint is unavailable
+	time.Sleep(2 * ethrpc.StreamReconnectMinBackoff)
+	require.Nil(t, monitor.resubscribeNewHeads(context.Background(), &retry))
+	require.Equal(t, 2*ethrpc.StreamReconnectMinBackoff, retry.delay)
+	require.Nil(t, monitor.resubscribeNewHeads(context.Background(), &retry))
+
+	// and the stream is re-established once it's available again
+	atomic.StoreInt32(&available, 1)
+	require.Eventually(t, func() bool {
+		heads = monitor.resubscribeNewHeads(context.Background(), &retry)
+		return heads != nil
+	}, 5*time.Second, 5*time.Millisecond)
+	require.True(t, retry.at.IsZero())
+	heads.Unsubscribe()
+}
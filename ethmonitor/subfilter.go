@@ -0,0 +1,110 @@
+package ethmonitor
+
+import (
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+)
+
+// SubscriptionFilter restricts the logs delivered to a subscriber, see SubscribeWithFilter.
+type SubscriptionFilter struct {
+	// Addresses of the contracts whose logs are delivered, or all when empty.
+	Addresses []common.Address
+
+	// Topics filter logs by topic position, the same as the Topics of an
+	// ethereum.FilterQuery. Each group matches any of its topics, and a nil group
+	// matches any topic.
+	Topics [][]common.Hash
+
+	// DropEmptyBlocks will skip blocks without any matching logs. By default every
+	// block is delivered, so subscribers can follow the continuity of the chain and
+	// its reorgs.
+	DropEmptyBlocks bool
+}
+
+// SubscribeWithFilter subscribes to the monitor, the same as Subscribe, but delivers only
+// the logs of each block matching the filter, and the token transfers of those logs. The
+// delivered blocks are shallow copies of the published blocks, so subscribers with
+// different filters don't affect each other. Requires WithLogs.
+func (m *Monitor) SubscribeWithFilter(filter SubscriptionFilter) Subscription {
+	return m.subscribe(newLogMatcher(filter), filter.DropEmptyBlocks)
+}
+
+// logMatcher returns true if the log matches a subscription filter.
+type logMatcher func(log *types.Log) bool
+
+func newLogMatcher(filter SubscriptionFilter) logMatcher {
+	addresses := map[common.Address]struct{}{}
+	for _, addr := range filter.Addresses {
+		addresses[addr] = struct{}{}
+	}
+
+	topics := make([]map[common.Hash]struct{}, len(filter.Topics))
+	for i, group := range filter.Topics {
+		if len(group) == 0 {
+			continue
+		}
+		topics[i] = map[common.Hash]struct{}{}
+		for _, topic := range group {
+			topics[i][topic] = struct{}{}
+		}
+	}
+
+	return func(log *types.Log) bool {
+		if len(addresses) > 0 {
+			if _, ok := addresses[log.Address]; !ok {
+				return false
+			}
+		}
+		for i, group := range topics {
+			if group == nil {
+				continue
+			}
+			if i >= len(log.Topics) {
+				return false
+			}
+			if _, ok := group[log.Topics[i]]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// filterBlocks returns shallow copies of the blocks with only the logs matching the
+// filter, skipping blocks without matching logs when dropEmpty is set.
+func filterBlocks(blocks Blocks, match logMatcher, dropEmpty bool) Blocks {
+	filtered := make(Blocks, 0, len(blocks))
+
+	for _, block := range blocks {
+		b := *block
+		b.Logs = nil
+		b.TokenTransfers = nil
+
+		if block.Logs != nil {
+			b.Logs = []types.Log{}
+			indexes := map[uint]struct{}{}
+			for i := range block.Logs {
+				if match(&block.Logs[i]) {
+					b.Logs = append(b.Logs, block.Logs[i])
+					indexes[block.Logs[i].Index] = struct{}{}
+				}
+			}
+
+			if block.TokenTransfers != nil {
+				b.TokenTransfers = []TokenTransfer{}
+				for _, transfer := range block.TokenTransfers {
+					if _, ok := indexes[transfer.LogIndex]; ok {
+						b.TokenTransfers = append(b.TokenTransfers, transfer)
+					}
+				}
+			}
+		}
+
+		if dropEmpty && len(b.Logs) == 0 && block.Event != Reset {
+			continue
+		}
+		filtered = append(filtered, &b)
+	}
+
+	return filtered
+}
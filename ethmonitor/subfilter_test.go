@@ -0,0 +1,84 @@
+package ethmonitor
+
+import (
+	"testing"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeWithFilter(t *testing.T) {
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+
+	tokenA := common.HexToAddress("0xaa")
+	tokenB := common.HexToAddress("0xbb")
+	transferTopic := common.HexToHash("0x01")
+	approvalTopic := common.HexToHash("0x02")
+
+	chain := mockBlockchain(2)
+	events := Blocks{
+		{
+			Block: chain[0],
+			Event: Added,
+			Logs: []types.Log{
+				{Address: tokenA, Topics: []common.Hash{transferTopic}, Index: 0},
+				{Address: tokenA, Topics: []common.Hash{approvalTopic}, Index: 1},
+				{Address: tokenB, Topics: []common.Hash{transferTopic}, Index: 2},
+			},
+			TokenTransfers: []TokenTransfer{
+				{Token: tokenA, LogIndex: 0},
+				{Token: tokenB, LogIndex: 2},
+			},
+		},
+		{
+			Block: chain[1],
+			Event: Added,
+			Logs: []types.Log{
+				{Address: tokenB, Topics: []common.Hash{approvalTopic}, Index: 0},
+			},
+		},
+	}
+
+	all := monitor.Subscribe()
+	defer all.Unsubscribe()
+
+	transfersA := monitor.SubscribeWithFilter(SubscriptionFilter{
+		Addresses: []common.Address{tokenA},
+		Topics:    [][]common.Hash{{transferTopic}},
+	})
+	defer transfersA.Unsubscribe()
+
+	dropEmpty := monitor.SubscribeWithFilter(SubscriptionFilter{
+		Topics:          [][]common.Hash{{transferTopic}},
+		DropEmptyBlocks: true,
+	})
+	defer dropEmpty.Unsubscribe()
+
+	monitor.broadcast(events)
+
+	blocks := <-all.Blocks()
+	require.Len(t, blocks, 2)
+	assert.Len(t, blocks[0].Logs, 3)
+	assert.Len(t, blocks[0].TokenTransfers, 2)
+
+	blocks = <-transfersA.Blocks()
+	require.Len(t, blocks, 2)
+	require.Len(t, blocks[0].Logs, 1)
+	assert.Equal(t, uint(0), blocks[0].Logs[0].Index)
+	require.Len(t, blocks[0].TokenTransfers, 1)
+	assert.Equal(t, tokenA, blocks[0].TokenTransfers[0].Token)
+	assert.Equal(t, events[1].Hash(), blocks[1].Hash())
+	assert.Empty(t, blocks[1].Logs)
+
+	blocks = <-dropEmpty.Blocks()
+	require.Len(t, blocks, 1)
+	assert.Equal(t, events[0].Hash(), blocks[0].Hash())
+	assert.Len(t, blocks[0].Logs, 2)
+
+	// the published blocks are left untouched
+	assert.Len(t, events[0].Logs, 3)
+	assert.Len(t, events[0].TokenTransfers, 2)
+}
@@ -0,0 +1,82 @@
+package ethmonitor
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Synced returns a channel which is closed once the monitor first catches up with the
+// head of the remote chain, within Options.SyncTolerance blocks, after Run is called.
+// Every call to Run starts with a new channel, so Synced should be called after Run.
+func (m *Monitor) Synced() <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.synced == nil {
+		m.synced = make(chan struct{})
+	}
+	return m.synced
+}
+
+// IsSynced returns true if the monitor has caught up with the head of the remote chain
+// during the current Run. See Synced.
+func (m *Monitor) IsSynced() bool {
+	return atomic.LoadInt32(&m.isSynced) == 1
+}
+
+func (m *Monitor) resetSynced() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if atomic.CompareAndSwapInt32(&m.isSynced, 1, 0) || m.synced == nil {
+		m.synced = make(chan struct{})
+	}
+	m.lastSyncCheck = time.Time{}
+}
+
+func (m *Monitor) markSynced() {
+	if !atomic.CompareAndSwapInt32(&m.isSynced, 0, 1) {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.synced == nil {
+		m.synced = make(chan struct{})
+	}
+	close(m.synced)
+	m.log.Infof("ethmonitor: synced with the head of the chain")
+}
+
+// checkSynced marks the monitor as synced once it's at the head of the chain, ie. the next
+// block was not found, or when a SyncTolerance is set, once its head is within the tolerance
+// of the remote head. The remote head is queried at most once per polling interval.
+func (m *Monitor) checkSynced(ctx context.Context, atHead bool) {
+	if m.IsSynced() {
+		return
+	}
+	if atHead {
+		m.markSynced()
+		return
+	}
+
+	if m.options.SyncTolerance <= 0 {
+		return
+	}
+	head := m.chain.Head()
+	if head == nil || time.Since(m.lastSyncCheck) < m.options.PollingInterval {
+		return
+	}
+	m.lastSyncCheck = time.Now()
+
+	tctx, cancel := context.WithTimeout(ctx, m.options.Timeout)
+	defer cancel()
+
+	remoteHead, err := m.provider.(blockNumberProvider).BlockNumber(tctx)
+	if err != nil {
+		m.log.Warnf("ethmonitor: failed to fetch remote head to check sync status: %v", err)
+		return
+	}
+	m.observeRemoteHead(remoteHead)
+	if head.NumberU64()+uint64(m.options.SyncTolerance) >= remoteHead {
+		m.markSynced()
+	}
+}
@@ -0,0 +1,56 @@
+package ethmonitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSynced(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0xa"}`))
+	}))
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+
+	opts := DefaultOptions
+	opts.SyncTolerance = 2
+	opts.PollingInterval = time.Nanosecond
+
+	monitor, err := NewMonitor(provider, opts)
+	require.NoError(t, err)
+	monitor.resetSynced()
+
+	synced := monitor.Synced()
+	ctx := context.Background()
+
+	// remote head is block 10, we're synced once we reach block 8
+	for _, b := range mockBlockchain(8) {
+		require.False(t, monitor.IsSynced())
+		require.NoError(t, monitor.chain.push(&Block{Event: Added, Block: b, OK: true}))
+		monitor.checkSynced(ctx, false)
+	}
+	require.True(t, monitor.IsSynced())
+
+	select {
+	case <-synced:
+	default:
+		t.Fatal("expecting synced channel to be closed")
+	}
+
+	// a new run resets the signal
+	monitor.resetSynced()
+	require.False(t, monitor.IsSynced())
+	synced = monitor.Synced()
+	monitor.checkSynced(ctx, true)
+	<-synced
+	require.True(t, monitor.IsSynced())
+}
@@ -0,0 +1,43 @@
+package ethmonitor
+
+import (
+	"math/big"
+
+	"github.com/0xsequence/ethkit/ethcoder"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+)
+
+// ERC20TransferTopic is the topic of the `Transfer(address,address,uint256)` event.
+var ERC20TransferTopic = ethcoder.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// TokenTransfer is an ERC20 Transfer event decoded from the logs of a block.
+type TokenTransfer struct {
+	Token common.Address
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+
+	TxnHash  common.Hash
+	LogIndex uint
+}
+
+// DecodeTokenTransfers returns the ERC20 transfers found in the logs. ERC721 transfers,
+// which share the same event signature but index the token id, are skipped.
+func DecodeTokenTransfers(logs []types.Log) []TokenTransfer {
+	transfers := []TokenTransfer{}
+	for _, log := range logs {
+		if len(log.Topics) != 3 || log.Topics[0] != ERC20TransferTopic || len(log.Data) != 32 {
+			continue
+		}
+		transfers = append(transfers, TokenTransfer{
+			Token:    log.Address,
+			From:     ethcoder.TopicToAddress(log.Topics[1]),
+			To:       ethcoder.TopicToAddress(log.Topics[2]),
+			Value:    big.NewInt(0).SetBytes(log.Data),
+			TxnHash:  log.TxHash,
+			LogIndex: log.Index,
+		})
+	}
+	return transfers
+}
@@ -0,0 +1,44 @@
+package ethmonitor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeTokenTransfers(t *testing.T) {
+	token := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	from := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	to := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	logs := []types.Log{
+		// erc20 transfer
+		{
+			Address: token,
+			Topics:  []common.Hash{ERC20TransferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+			Data:    common.BigToHash(big.NewInt(42)).Bytes(),
+			Index:   3,
+		},
+		// erc721 transfer, with an indexed token id
+		{
+			Address: token,
+			Topics:  []common.Hash{ERC20TransferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes()), common.BigToHash(big.NewInt(1))},
+		},
+		// another event
+		{
+			Address: token,
+			Topics:  []common.Hash{common.HexToHash("0x01")},
+		},
+	}
+
+	transfers := DecodeTokenTransfers(logs)
+	require.Len(t, transfers, 1)
+	require.Equal(t, token, transfers[0].Token)
+	require.Equal(t, from, transfers[0].From)
+	require.Equal(t, to, transfers[0].To)
+	require.Equal(t, int64(42), transfers[0].Value.Int64())
+	require.Equal(t, uint(3), transfers[0].LogIndex)
+}
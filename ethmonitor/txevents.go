@@ -0,0 +1,219 @@
+package ethmonitor
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/0xsequence/ethkit/ethcoder"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+)
+
+// TxEventStatus is the change in a transaction's inclusion on the canonical chain.
+type TxEventStatus int
+
+const (
+	// TxIncluded means the transaction was included in a block added to the canonical chain.
+	TxIncluded TxEventStatus = iota
+
+	// TxReverted means the transaction was included in a block added to the canonical
+	// chain, but its execution failed. Only reported when TxFilter.FetchReceipts is set.
+	TxReverted
+
+	// TxReorged means the block which included the transaction was removed from the
+	// canonical chain by a reorg. The transaction may be included again in a later block.
+	TxReorged
+)
+
+func (s TxEventStatus) String() string {
+	switch s {
+	case TxIncluded:
+		return "INCLUDED"
+	case TxReverted:
+		return "REVERTED"
+	case TxReorged:
+		return "REORGED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// TxEvent is a transaction entering or leaving the canonical chain.
+type TxEvent struct {
+	Hash        common.Hash
+	Status      TxEventStatus
+	Block       *Block
+	Transaction *types.Transaction
+
+	// Receipt is only set when TxFilter.FetchReceipts is set, and the transaction
+	// was included.
+	Receipt *types.Receipt
+}
+
+// TxFilter selects which transactions are delivered to a TxSubscription. A transaction
+// must match each of the non-empty sets to be delivered, an empty filter matches all
+// transactions.
+type TxFilter struct {
+	Hashes []common.Hash
+	From   []common.Address
+	To     []common.Address
+
+	// FetchReceipts will fetch the receipt of every included transaction matching
+	// the filter, in order to report TxReverted for failed transactions. This costs
+	// an rpc call per transaction, so the filter should be narrow.
+	FetchReceipts bool
+}
+
+type TxSubscription interface {
+	Transactions() <-chan TxEvent
+	Done() <-chan struct{}
+	Unsubscribe()
+}
+
+var _ TxSubscription = &txSubscriber{}
+
+type txSubscriber struct {
+	sub Subscription
+	ch  *subscriberChan[TxEvent]
+}
+
+func (s *txSubscriber) Transactions() <-chan TxEvent {
+	return s.ch.readCh
+}
+
+func (s *txSubscriber) Done() <-chan struct{} {
+	return s.sub.Done()
+}
+
+func (s *txSubscriber) Unsubscribe() {
+	s.sub.Unsubscribe()
+
+	// release a send waiting on the reader under the SubscriberBlock policy
+	s.ch.close()
+}
+
+// SubscribeTransactions subscribes to transactions matching the filter as they enter
+// or leave the canonical chain. Events are derived from the block events of Subscribe,
+// and are delivered in the same order. No events are published in Options.HeadersOnly
+// mode, as the blocks are fetched without their transactions.
+func (m *Monitor) SubscribeTransactions(filter TxFilter) TxSubscription {
+	txSub := &txSubscriber{
+		sub: m.Subscribe(),
+		ch:  newSubscriberChan[TxEvent](m.log, m.options.SubscriberBufferLimit, m.options.SubscriberOverflowPolicy),
+	}
+
+	match := newTxMatcher(filter)
+
+	go func() {
+		defer txSub.ch.close()
+
+		for {
+			select {
+			case <-txSub.sub.Done():
+				return
+
+			case blocks, ok := <-txSub.sub.Blocks():
+				if !ok {
+					return
+				}
+				for _, block := range blocks {
+					if block.Event == Finalized || block.Event == Reset {
+						continue
+					}
+					for _, txn := range block.Transactions() {
+						if !match(txn) {
+							continue
+						}
+						ev := TxEvent{Hash: txn.Hash(), Status: TxIncluded, Block: block, Transaction: txn}
+						if block.Event == Removed {
+							ev.Status = TxReorged
+						} else if filter.FetchReceipts {
+							ev.Receipt = m.fetchReceipt(block, txn.Hash())
+							if ev.Receipt != nil && ev.Receipt.Status == types.ReceiptStatusFailed {
+								ev.Status = TxReverted
+							}
+						}
+						if !txSub.ch.send(ev) {
+							atomic.AddUint64(&m.subscriberOverflows, 1)
+							m.metrics.subscriberOverflowed()
+
+							if m.options.SubscriberOverflowPolicy == SubscriberDisconnect {
+								m.log.Warnf("ethmonitor: transaction subscriber is more than %d events behind, disconnecting it", m.options.SubscriberBufferLimit)
+								txSub.Unsubscribe()
+								return
+							}
+						}
+					}
+				}
+			}
+		}
+	}()
+
+	return txSub
+}
+
+// fetchReceipt returns the receipt of the transaction of the block, which is retained with
+// the block when WithReceipts is set, or fetched from the provider otherwise.
+func (m *Monitor) fetchReceipt(block *Block, txnHash common.Hash) *types.Receipt {
+	for _, receipt := range block.Receipts() {
+		if receipt.TxHash == txnHash {
+			return receipt
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, m.options.Timeout)
+	defer cancel()
+
+	p, ok := m.provider.(receiptProvider)
+	if !ok {
+		m.log.Warnf("ethmonitor: failed to fetch receipt for txn %s: provider does not support TransactionReceipt", txnHash.Hex())
+		return nil
+	}
+	receipt, err := p.TransactionReceipt(ctx, txnHash)
+	if err != nil {
+		m.log.Warnf("ethmonitor: failed to fetch receipt for txn %s: %v", txnHash.Hex(), err)
+		return nil
+	}
+	return receipt
+}
+
+func newTxMatcher(filter TxFilter) func(txn *types.Transaction) bool {
+	hashes := map[common.Hash]struct{}{}
+	for _, h := range filter.Hashes {
+		hashes[h] = struct{}{}
+	}
+	from := map[common.Address]struct{}{}
+	for _, a := range filter.From {
+		from[a] = struct{}{}
+	}
+	to := map[common.Address]struct{}{}
+	for _, a := range filter.To {
+		to[a] = struct{}{}
+	}
+
+	return func(txn *types.Transaction) bool {
+		if len(hashes) > 0 {
+			if _, ok := hashes[txn.Hash()]; !ok {
+				return false
+			}
+		}
+		if len(to) > 0 {
+			if txn.To() == nil {
+				return false
+			}
+			if _, ok := to[*txn.To()]; !ok {
+				return false
+			}
+		}
+		if len(from) > 0 {
+			sender, err := ethcoder.TransactionSender(txn, nil)
+			if err != nil {
+				return false
+			}
+			if _, ok := from[sender]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,99 @@
+package ethmonitor
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeTransactions(t *testing.T) {
+	key, err := crypto.HexToECDSA("4646464646464646464646464646464646464646464646464646464646464646")
+	require.NoError(t, err)
+	signer := types.NewLondonSigner(big.NewInt(1))
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	newTxn := func(nonce uint64, to common.Address) *types.Transaction {
+		txn, err := types.SignTx(types.NewTransaction(nonce, to, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+		require.NoError(t, err)
+		return txn
+	}
+	txnAlice, txnBob := newTxn(0, alice), newTxn(1, bob)
+
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+
+	bc := mockBlockchain(2)
+	block := &Block{Event: Added, Block: bc[1].WithBody([]*types.Transaction{txnAlice, txnBob}, nil)}
+
+	sub := monitor.SubscribeTransactions(TxFilter{To: []common.Address{bob}})
+
+	all := monitor.SubscribeTransactions(TxFilter{From: []common.Address{crypto.PubkeyToAddress(key.PublicKey)}})
+	defer all.Unsubscribe()
+
+	// added, then reorged
+	monitor.broadcast(Blocks{block})
+	removed := *block
+	removed.Event = Removed
+	monitor.broadcast(Blocks{&removed})
+
+	expect := func(sub TxSubscription, hash common.Hash, status TxEventStatus) {
+		select {
+		case ev := <-sub.Transactions():
+			require.Equal(t, hash, ev.Hash)
+			require.Equal(t, status, ev.Status)
+			require.Equal(t, uint64(2), ev.Block.NumberU64())
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for txn event")
+		}
+	}
+
+	expect(sub, txnBob.Hash(), TxIncluded)
+	expect(sub, txnBob.Hash(), TxReorged)
+
+	expect(all, txnAlice.Hash(), TxIncluded)
+	expect(all, txnBob.Hash(), TxIncluded)
+	expect(all, txnAlice.Hash(), TxReorged)
+	expect(all, txnBob.Hash(), TxReorged)
+
+	sub.Unsubscribe()
+	select {
+	case <-sub.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected subscription to be done")
+	}
+}
+
+func TestSubscribeTransactionsRetainedReceipts(t *testing.T) {
+	txn := types.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+
+	opts := DefaultOptions
+	opts.WithReceipts = true
+
+	// no provider, so the receipt must come from the block
+	monitor, err := NewMonitor(nil, opts)
+	require.NoError(t, err)
+
+	bc := mockBlockchain(1)
+	block := &Block{Event: Added, Block: bc[0].WithBody([]*types.Transaction{txn}, nil)}
+	block.receipts = []*types.Receipt{{TxHash: txn.Hash(), Status: types.ReceiptStatusFailed}}
+
+	sub := monitor.SubscribeTransactions(TxFilter{FetchReceipts: true})
+	defer sub.Unsubscribe()
+
+	monitor.broadcast(Blocks{block})
+
+	select {
+	case ev := <-sub.Transactions():
+		require.Equal(t, TxReverted, ev.Status)
+		require.Same(t, block.receipts[0], ev.Receipt)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for txn event")
+	}
+}
@@ -0,0 +1,51 @@
+package ethmonitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForBlocks(t *testing.T) {
+	monitor, err := NewMonitor(nil)
+	require.NoError(t, err)
+
+	bc := mockBlockchain(4)
+	reorged := mockBlock(bc[1].Hash().Hex(), 3)
+
+	publish := func() {
+		require.Eventually(t, func() bool {
+			monitor.mu.Lock()
+			defer monitor.mu.Unlock()
+			return len(monitor.subscribers) > 0
+		}, 2*time.Second, time.Millisecond)
+
+		monitor.broadcast(Blocks{{Event: Added, Block: bc[0]}, {Event: Added, Block: bc[1]}, {Event: Added, Block: reorged}})
+		monitor.broadcast(Blocks{{Event: Removed, Block: reorged}, {Event: Added, Block: bc[2]}, {Event: Added, Block: bc[3]}})
+	}
+
+	// removed blocks are ignored
+	go publish()
+	blocks, err := monitor.WaitForBlocks(context.Background(), 4)
+	require.NoError(t, err)
+	require.Len(t, blocks, 4)
+	require.Equal(t, reorged.Hash(), blocks[2].Hash())
+
+	// reorged blocks are dropped from the result
+	go publish()
+	blocks, err = monitor.WaitForBlocks(context.Background(), 4, true)
+	require.NoError(t, err)
+	require.Len(t, blocks, 4)
+	for i, b := range blocks {
+		require.Equal(t, bc[i].Hash(), b.Hash())
+	}
+
+	// context cancellation
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	blocks, err = monitor.WaitForBlocks(ctx, 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Empty(t, blocks)
+}
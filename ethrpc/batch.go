@@ -0,0 +1,145 @@
+package ethrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/0xsequence/ethkit/go-ethereum/rpc"
+)
+
+// BatchSize is the number of requests sent per JSON-RPC batch by BlocksByNumberRange.
+var BatchSize = 100
+
+// BlocksByNumberRange fetches the blocks in the inclusive range [from, to] in JSON-RPC
+// batches of BatchSize requests. Batches are sent one at a time with ctx, so cancelling
+// ctx aborts the in-flight batch and no further batches are sent, in which case the
+// blocks fetched so far are returned along with ctx.Err(). If a block in the range is
+// not found, the blocks before it are returned along with ethereum.NotFound.
+func (s *Provider) BlocksByNumberRange(ctx context.Context, from, to uint64) ([]*types.Block, error) {
+	if from > to {
+		return nil, fmt.Errorf("ethrpc: invalid block range %d-%d", from, to)
+	}
+
+	batchSize := uint64(BatchSize)
+	if batchSize == 0 {
+		batchSize = 1
+	}
+
+	blocks := make([]*types.Block, 0, to-from+1)
+
+	for start := from; start <= to; start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return blocks, err
+		}
+
+		end := start + batchSize - 1
+		if end > to || end < start {
+			end = to
+		}
+
+		raws := make([]json.RawMessage, end-start+1)
+		batch := make([]rpc.BatchElem, len(raws))
+		for i := range batch {
+			batch[i] = rpc.BatchElem{
+				Method: "eth_getBlockByNumber",
+				Args:   []interface{}{toBlockNumArg(big.NewInt(0).SetUint64(start + uint64(i))), true},
+				Result: &raws[i],
+			}
+		}
+
+		err := s.RPC.BatchCallContext(ctx, batch)
+		if err != nil {
+			if ctx.Err() != nil {
+				return blocks, ctx.Err()
+			}
+			return blocks, err
+		}
+
+		for i, elem := range batch {
+			if elem.Error != nil {
+				return blocks, elem.Error
+			}
+			if len(raws[i]) == 0 || string(raws[i]) == "null" {
+				return blocks, ethereum.NotFound
+			}
+			block, err := decodeBlock(raws[i])
+			if err != nil {
+				return blocks, err
+			}
+			blocks = append(blocks, block)
+		}
+
+		if end == to {
+			break
+		}
+	}
+
+	return blocks, nil
+}
+
+// FilterLogsPaged executes the filter query over its block range in pages of pageSize
+// blocks, which avoids the response size and block range limits of most nodes. The
+// query must have a FromBlock, and a nil ToBlock means the latest block. Pages are
+// queried one at a time with ctx, so cancelling ctx aborts the in-flight request and no
+// further pages are queried, in which case the logs fetched so far are returned along
+// with ctx.Err().
+func (s *Provider) FilterLogsPaged(ctx context.Context, q ethereum.FilterQuery, pageSize uint64) ([]types.Log, error) {
+	if q.BlockHash != nil {
+		return nil, errors.New("ethrpc: FilterLogsPaged does not support BlockHash queries, use FilterLogs")
+	}
+	if q.FromBlock == nil {
+		return nil, errors.New("ethrpc: FilterLogsPaged requires FromBlock")
+	}
+	if pageSize == 0 {
+		pageSize = 1
+	}
+
+	from := q.FromBlock.Uint64()
+	var to uint64
+	if q.ToBlock != nil {
+		to = q.ToBlock.Uint64()
+	} else {
+		latest, err := s.BlockNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		to = latest
+	}
+
+	logs := []types.Log{}
+
+	for start := from; start <= to; start += pageSize {
+		if err := ctx.Err(); err != nil {
+			return logs, err
+		}
+
+		end := start + pageSize - 1
+		if end > to || end < start {
+			end = to
+		}
+
+		page := q
+		page.FromBlock = big.NewInt(0).SetUint64(start)
+		page.ToBlock = big.NewInt(0).SetUint64(end)
+
+		pageLogs, err := s.FilterLogs(ctx, page)
+		if err != nil {
+			if ctx.Err() != nil {
+				return logs, ctx.Err()
+			}
+			return logs, err
+		}
+		logs = append(logs, pageLogs...)
+
+		if end == to {
+			break
+		}
+	}
+
+	return logs, nil
+}
@@ -0,0 +1,154 @@
+package ethrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRangeNode returns a node with blocks up to latest, which calls onRequest with the
+// number of every request received. When onRequest returns false, the node stalls the
+// request until it is aborted by the client.
+func newRangeNode(t *testing.T, latest uint64, onRequest func(n int32) bool) *httptest.Server {
+	var requests int32
+
+	handle := func(req rpcRequest) map[string]interface{} {
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": nil}
+		switch req.Method {
+		case "eth_getBlockByNumber":
+			var num hexutil.Uint64
+			json.Unmarshal(req.Params[0], &num)
+			if uint64(num) > latest {
+				return resp
+			}
+			header := &types.Header{Number: big.NewInt(int64(num)), Difficulty: big.NewInt(0)}
+			var block map[string]interface{}
+			data, _ := json.Marshal(header)
+			json.Unmarshal(data, &block)
+			block["transactions"] = []interface{}{}
+			block["uncles"] = []interface{}{}
+			resp["result"] = block
+		case "eth_getLogs":
+			var q struct {
+				FromBlock hexutil.Uint64 `json:"fromBlock"`
+				ToBlock   hexutil.Uint64 `json:"toBlock"`
+			}
+			json.Unmarshal(req.Params[0], &q)
+			logs := []types.Log{}
+			for n := q.FromBlock; n <= q.ToBlock; n++ {
+				logs = append(logs, types.Log{BlockNumber: uint64(n), Topics: []common.Hash{}})
+			}
+			resp["result"] = logs
+		}
+		return resp
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// read the body first, so the server notices when the client aborts the request
+		body, _ := io.ReadAll(r.Body)
+
+		if !onRequest(atomic.AddInt32(&requests, 1)) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(10 * time.Second):
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		if len(body) > 0 && body[0] == '[' {
+			var reqs []rpcRequest
+			json.Unmarshal(body, &reqs)
+			resps := []map[string]interface{}{}
+			for _, req := range reqs {
+				resps = append(resps, handle(req))
+			}
+			json.NewEncoder(w).Encode(resps)
+			return
+		}
+
+		var req rpcRequest
+		json.Unmarshal(body, &req)
+		json.NewEncoder(w).Encode(handle(req))
+	}))
+}
+
+func TestBlocksByNumberRange(t *testing.T) {
+	defer func(batchSize int) { ethrpc.BatchSize = batchSize }(ethrpc.BatchSize)
+	ethrpc.BatchSize = 10
+
+	srv := newRangeNode(t, 30, func(int32) bool { return true })
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+
+	blocks, err := provider.BlocksByNumberRange(context.Background(), 5, 25)
+	require.NoError(t, err)
+	require.Len(t, blocks, 21)
+	for i, b := range blocks {
+		assert.Equal(t, uint64(5+i), b.NumberU64())
+	}
+
+	// blocks after the head are not found
+	blocks, err = provider.BlocksByNumberRange(context.Background(), 25, 35)
+	require.ErrorIs(t, err, ethereum.NotFound)
+	require.Len(t, blocks, 6)
+}
+
+func TestBatchCancellation(t *testing.T) {
+	defer func(batchSize int) { ethrpc.BatchSize = batchSize }(ethrpc.BatchSize)
+	ethrpc.BatchSize = 10
+
+	for _, method := range []string{"BlocksByNumberRange", "FilterLogsPaged"} {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		// cancel while the second request is in-flight, which the node never answers
+		srv := newRangeNode(t, 1000, func(n int32) bool {
+			if n == 2 {
+				time.AfterFunc(50*time.Millisecond, cancel)
+				return false
+			}
+			return n < 2
+		})
+
+		provider, err := ethrpc.NewProvider(srv.URL)
+		require.NoError(t, err)
+
+		start := time.Now()
+		var n int
+		switch method {
+		case "BlocksByNumberRange":
+			var blocks []*types.Block
+			blocks, err = provider.BlocksByNumberRange(ctx, 1, 1000)
+			n = len(blocks)
+		case "FilterLogsPaged":
+			var logs []types.Log
+			logs, err = provider.FilterLogsPaged(ctx, ethereum.FilterQuery{FromBlock: big.NewInt(1), ToBlock: big.NewInt(1000)}, 10)
+			n = len(logs)
+		}
+
+		require.ErrorIs(t, err, context.Canceled, method)
+		require.Less(t, time.Since(start), 2*time.Second, method)
+
+		// results of the first request are returned
+		require.Equal(t, 10, n, method)
+
+		cancel()
+		srv.Close()
+	}
+}
@@ -0,0 +1,97 @@
+package ethrpc
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/0xsequence/ethkit/go-ethereum/rpc"
+)
+
+// CallContract executes a message call transaction, which is directly executed in the VM
+// of the node, but never mined into the blockchain. Unlike the go-ethereum client, the
+// AccessList of the message is passed along to the node, see toCallArg.
+func (s *Provider) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	var hex hexutil.Bytes
+	err := s.RPC.CallContext(ctx, &hex, "eth_call", toCallArg(msg), toBlockNumArg(blockNumber))
+	if err != nil {
+		return nil, err
+	}
+	return hex, nil
+}
+
+// CallContractAtHash is almost the same as CallContract except that it selects
+// the block by block hash instead of block height.
+func (s *Provider) CallContractAtHash(ctx context.Context, msg ethereum.CallMsg, blockHash common.Hash) ([]byte, error) {
+	var hex hexutil.Bytes
+	err := s.RPC.CallContext(ctx, &hex, "eth_call", toCallArg(msg), rpc.BlockNumberOrHashWithHash(blockHash, false))
+	if err != nil {
+		return nil, err
+	}
+	return hex, nil
+}
+
+// PendingCallContract executes a message call transaction against the pending state.
+func (s *Provider) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	var hex hexutil.Bytes
+	err := s.RPC.CallContext(ctx, &hex, "eth_call", toCallArg(msg), "pending")
+	if err != nil {
+		return nil, err
+	}
+	return hex, nil
+}
+
+// EstimateGas estimates the gas needed to execute the message call against the pending
+// state, including the AccessList of the message.
+func (s *Provider) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	var hex hexutil.Uint64
+	err := s.RPC.CallContext(ctx, &hex, "eth_estimateGas", toCallArg(msg))
+	if err != nil {
+		return 0, err
+	}
+	return uint64(hex), nil
+}
+
+// toCallArg is the same as go-ethereum's ethclient, but also serializes the AccessList
+// of the message. When an access list is given, the call is sent as an EIP-2930
+// transaction, or as an EIP-1559 transaction if the message has fee caps set. Messages
+// without an access list are serialized as before.
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+
+	if len(msg.AccessList) > 0 {
+		arg["accessList"] = msg.AccessList
+
+		if msg.GasPrice == nil && (msg.GasFeeCap != nil || msg.GasTipCap != nil) {
+			arg["type"] = hexutil.Uint64(types.DynamicFeeTxType)
+			if msg.GasFeeCap != nil {
+				arg["maxFeePerGas"] = (*hexutil.Big)(msg.GasFeeCap)
+			}
+			if msg.GasTipCap != nil {
+				arg["maxPriorityFeePerGas"] = (*hexutil.Big)(msg.GasTipCap)
+			}
+		} else {
+			arg["type"] = hexutil.Uint64(types.AccessListTxType)
+		}
+	}
+
+	return arg
+}
@@ -0,0 +1,78 @@
+package ethrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallContractAccessList(t *testing.T) {
+	var mu sync.Mutex
+	var lastArg map[string]interface{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		var arg map[string]interface{}
+		json.Unmarshal(req.Params[0], &arg)
+		mu.Lock()
+		lastArg = arg
+		mu.Unlock()
+
+		result := "0x01"
+		if req.Method == "eth_estimateGas" {
+			result = "0x5208"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+
+	to := common.HexToAddress("0x3535353535353535353535353535353535353535")
+	accessList := types.AccessList{{Address: to, StorageKeys: []common.Hash{common.HexToHash("0x01")}}}
+
+	getArg := func() map[string]interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return lastArg
+	}
+
+	// no access list, unchanged
+	_, err = provider.CallContract(context.Background(), ethereum.CallMsg{To: &to, Data: []byte{1}}, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, getArg(), "accessList")
+	assert.NotContains(t, getArg(), "type")
+
+	// access list
+	_, err = provider.CallContract(context.Background(), ethereum.CallMsg{To: &to, AccessList: accessList}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "0x1", getArg()["type"])
+	require.Len(t, getArg()["accessList"], 1)
+
+	// access list with fee caps
+	gas, err := provider.EstimateGas(context.Background(), ethereum.CallMsg{To: &to, AccessList: accessList, GasFeeCap: big.NewInt(100), GasTipCap: big.NewInt(2)})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(21000), gas)
+	assert.Equal(t, "0x2", getArg()["type"])
+	assert.Equal(t, "0x64", getArg()["maxFeePerGas"])
+	assert.Equal(t, "0x2", getArg()["maxPriorityFeePerGas"])
+}
@@ -0,0 +1,108 @@
+package ethrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/0xsequence/ethkit/ethcoder"
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+)
+
+var (
+	ErrNoReturnValue        = errors.New("ethrpc: method has no return values")
+	ErrMultipleReturnValues = errors.New("ethrpc: method has multiple return values")
+)
+
+// CallOutput is the decoded output of a contract method call.
+type CallOutput struct {
+	// Values are the return values of the method in order, and empty when the
+	// method has no return values.
+	Values []interface{}
+
+	// Names are the names of the return values, or "" for unnamed values.
+	Names []string
+}
+
+// Value returns the return value of a method with a single return value, or
+// ErrNoReturnValue / ErrMultipleReturnValues otherwise.
+func (o CallOutput) Value() (interface{}, error) {
+	switch len(o.Values) {
+	case 0:
+		return nil, ErrNoReturnValue
+	case 1:
+		return o.Values[0], nil
+	default:
+		return nil, ErrMultipleReturnValues
+	}
+}
+
+// Map returns the return values keyed by name. Unnamed values are keyed by their
+// position, ie. "0".
+func (o CallOutput) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(o.Values))
+	for i, v := range o.Values {
+		name := o.Names[i]
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		m[name] = v
+	}
+	return m
+}
+
+// DecodeCallOutput decodes the return data of a call to the method, ie. a Result of
+// a Multicall.
+func DecodeCallOutput(method abi.Method, data []byte) (CallOutput, error) {
+	output := CallOutput{Values: []interface{}{}, Names: []string{}}
+	if len(method.Outputs) == 0 {
+		return output, nil
+	}
+
+	values, err := method.Outputs.Unpack(data)
+	if err != nil {
+		return CallOutput{}, fmt.Errorf("ethrpc: failed to decode output of %s: %w", method.Sig, err)
+	}
+	for i, arg := range method.Outputs {
+		output.Values = append(output.Values, values[i])
+		output.Names = append(output.Names, arg.Name)
+	}
+	return output, nil
+}
+
+// CallMethod calls the contract method at the given block number, or the latest block
+// when nil, and decodes its return values. The method and its return values are given as
+// short-hand expressions, ie. methodExpr `getReserves()` and returnsExpr
+// `uint112 reserve0, uint112 reserve1, uint32 blockTimestampLast`, see
+// ethcoder.ParseMethodABI.
+func (s *Provider) CallMethod(ctx context.Context, contract common.Address, methodExpr, returnsExpr string, args []interface{}, blockNumber *big.Int) (CallOutput, error) {
+	contractABI, methodName, err := ethcoder.ParseMethodABI(methodExpr, returnsExpr)
+	if err != nil {
+		return CallOutput{}, fmt.Errorf("ethrpc: invalid method expr: %w", err)
+	}
+	return s.CallABIMethod(ctx, contract, *contractABI, methodName, args, blockNumber)
+}
+
+// CallABIMethod calls the method of the contract ABI at the given block number, or the
+// latest block when nil, and decodes its return values.
+func (s *Provider) CallABIMethod(ctx context.Context, contract common.Address, contractABI abi.ABI, methodName string, args []interface{}, blockNumber *big.Int) (CallOutput, error) {
+	method, ok := contractABI.Methods[methodName]
+	if !ok {
+		return CallOutput{}, fmt.Errorf("ethrpc: method %q not found in abi", methodName)
+	}
+
+	calldata, err := contractABI.Pack(methodName, args...)
+	if err != nil {
+		return CallOutput{}, fmt.Errorf("ethrpc: failed to encode call to %s: %w", method.Sig, err)
+	}
+
+	data, err := s.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: calldata}, blockNumber)
+	if err != nil {
+		return CallOutput{}, err
+	}
+	return DecodeCallOutput(method, data)
+}
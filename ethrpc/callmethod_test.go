@@ -0,0 +1,60 @@
+package ethrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0xsequence/ethkit/ethcoder"
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallMethod(t *testing.T) {
+	returnData, err := ethcoder.AbiCoder([]string{"uint112", "uint112", "uint32"}, []interface{}{big.NewInt(100), big.NewInt(200), uint32(300)})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": hexutil.Bytes(returnData)})
+	}))
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	contract := common.HexToAddress("0x3535353535353535353535353535353535353535")
+
+	// multiple return values
+	output, err := provider.CallMethod(ctx, contract, "getReserves()", "uint112 reserve0, uint112 reserve1, uint32", nil, nil)
+	require.NoError(t, err)
+	require.Len(t, output.Values, 3)
+	assert.Equal(t, []string{"reserve0", "reserve1", ""}, output.Names)
+	assert.Equal(t, map[string]interface{}{"reserve0": big.NewInt(100), "reserve1": big.NewInt(200), "2": uint32(300)}, output.Map())
+	_, err = output.Value()
+	assert.ErrorIs(t, err, ethrpc.ErrMultipleReturnValues)
+
+	// single return value, only the first word of the return data is decoded
+	output, err = provider.CallMethod(ctx, contract, "balanceOf(address)", "uint256", []interface{}{contract}, nil)
+	require.NoError(t, err)
+	value, err := output.Value()
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(100), value)
+
+	// no return values
+	output, err = provider.CallMethod(ctx, contract, "poke()", "", nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, output.Values)
+	assert.Empty(t, output.Map())
+	_, err = output.Value()
+	assert.ErrorIs(t, err, ethrpc.ErrNoReturnValue)
+}
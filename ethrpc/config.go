@@ -10,6 +10,10 @@ type Config struct {
 type NodeConfig struct {
 	URL                 string
 	MaxRequestPerSecond float64
+
+	// WSURL is an optional websocket endpoint of the node used for subscriptions,
+	// when URL itself is not a websocket endpoint.
+	WSURL string
 }
 
 func (c *Config) AddNode(nodeConfig NodeConfig) {
@@ -9,6 +9,7 @@ import (
 	"math/big"
 	"net/http"
 	"strconv"
+	"sync"
 
 	"github.com/0xsequence/ethkit/ethcoder"
 	"github.com/0xsequence/ethkit/go-ethereum"
@@ -20,6 +21,10 @@ import (
 	"github.com/0xsequence/ethkit/go-ethereum/rpc"
 )
 
+// ErrMissingBlockHeader is returned for a block without a valid header, ie. without a
+// number, as returned by some buggy rpc proxies.
+var ErrMissingBlockHeader = errors.New("ethrpc: block is missing its header")
+
 // NOTE: most of the code in the current implementatio is from go-ethereum and been
 // modified for ease of use. Future implementations of this package will forego use of
 // go-ethereum code or as dependency.
@@ -29,6 +34,14 @@ type Provider struct {
 	Config     *Config
 	RPC        *rpc.Client
 	httpClient *http.Client
+
+	// stream is the websocket connection used by subscriptions
+	stream   *stream
+	streamMu sync.Mutex
+
+	// multicall3 caches whether Multicall3 is deployed, see Multicall
+	multicall3  *bool
+	multicallMu sync.Mutex
 }
 
 var _ bind.ContractBackend = &Provider{}
@@ -68,7 +81,9 @@ func (s *Provider) Dial() error {
 	var rpcClient *rpc.Client
 	var err error
 
-	if s.httpClient != nil {
+	if isWebsocketURL(url) {
+		rpcClient, err = rpc.DialWebsocket(context.Background(), url, "")
+	} else if s.httpClient != nil {
 		rpcClient, err = rpc.DialHTTPWithClient(url, s.httpClient)
 	} else {
 		rpcClient, err = rpc.DialHTTP(url)
@@ -212,14 +227,44 @@ func (s *Provider) BlockByHash(ctx context.Context, hash common.Hash) (*types.Bl
 	return s.getBlock2(ctx, "eth_getBlockByHash", hash, true)
 }
 
+// BlockByNumber returns the block at the given number, or the latest block when nil. The
+// finalized and safe blocks of post-merge chains are fetched with the rpc.FinalizedBlockNumber
+// and rpc.SafeBlockNumber tags, ie. big.NewInt(int64(rpc.FinalizedBlockNumber)).
 func (s *Provider) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
 	return s.getBlock2(ctx, "eth_getBlockByNumber", toBlockNumArg(number), true)
 }
 
-// TODO: still incomplete, might need new *types.MiniBlock type for this as well.
-// func (s *Provider) MiniBlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
-// 	return s.getMiniBlock(ctx, "eth_getBlockByNumber", toBlockNumArg(number), false)
-// }
+// BlockHeaderByNumber returns the block at the given number without its transactions,
+// ie. only its header, which is much lighter to fetch than the full block on chains with
+// many transactions. The block hash is the one reported by the node.
+func (s *Provider) BlockHeaderByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return s.getMiniBlock(ctx, "eth_getBlockByNumber", toBlockNumArg(number), false)
+}
+
+// BlockHeaderByHash returns the block with the given hash without its transactions, see
+// BlockHeaderByNumber.
+func (s *Provider) BlockHeaderByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return s.getMiniBlock(ctx, "eth_getBlockByHash", hash, false)
+}
+
+// BlockReceipts returns the receipts of all transactions in the block with the given hash,
+// fetched with eth_getBlockReceipts. Nodes which don't support the method return an error
+// wrapping ErrMethodUnsupported, in which case the receipts must be fetched per transaction
+// with TransactionReceipt.
+func (s *Provider) BlockReceipts(ctx context.Context, blockHash common.Hash) ([]*types.Receipt, error) {
+	var receipts []*types.Receipt
+	err := s.RPC.CallContext(ctx, &receipts, "eth_getBlockReceipts", blockHash)
+	if err != nil {
+		if isMethodUnsupportedErr(err) {
+			return nil, fmt.Errorf("%w: %v", ErrMethodUnsupported, err)
+		}
+		return nil, err
+	}
+	if receipts == nil {
+		return nil, ethereum.NotFound
+	}
+	return receipts, nil
+}
 
 func (s *Provider) SendRawTransaction(ctx context.Context, signedTxHex string) (common.Hash, error) {
 	var result common.Hash
@@ -271,13 +316,34 @@ func (s *Provider) getBlock2(ctx context.Context, method string, args ...interfa
 	} else if len(raw) == 0 {
 		return nil, ethereum.NotFound
 	}
+	return decodeBlock(raw)
+}
 
-	// Decode header and transactions.
+// decodeBlockHeader decodes the header of the raw block of an eth_getBlockBy* response,
+// returning ethereum.NotFound for a null block, and ErrMissingBlockHeader for a block
+// without a valid header.
+func decodeBlockHeader(raw json.RawMessage) (*types.Header, error) {
 	var head *types.Header
-	var body rpcBlock
 	if err := json.Unmarshal(raw, &head); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMissingBlockHeader, err)
+	}
+	if head == nil {
+		return nil, ethereum.NotFound
+	}
+	if head.Number == nil {
+		return nil, ErrMissingBlockHeader
+	}
+	return head, nil
+}
+
+// decodeBlock decodes the raw block of an eth_getBlockBy* response with full transactions.
+func decodeBlock(raw json.RawMessage) (*types.Block, error) {
+	// Decode header and transactions.
+	head, err := decodeBlockHeader(raw)
+	if err != nil {
 		return nil, err
 	}
+	var body rpcBlock
 	if err := json.Unmarshal(raw, &body); err != nil {
 		return nil, err
 	}
@@ -343,10 +409,7 @@ func (s *Provider) getBlock2(ctx context.Context, method string, args ...interfa
 	}
 
 	// return types.NewBlockWithHeader(head).WithBody(txs, uncles), nil
-	block, err := types.NewBlockWithHeader(head).WithBody(txs, nil), nil
-	if err != nil {
-		return nil, err
-	}
+	block := types.NewBlockWithHeader(head).WithBody(txs, nil)
 
 	// TODO: Remove this, we shouldn't need to use the block cache
 	// in order for it to contain the correct block hash
@@ -370,12 +433,11 @@ func (s *Provider) getMiniBlock(ctx context.Context, method string, args ...inte
 		return nil, ethereum.NotFound
 	}
 	// Decode header and transactions.
-	var head *types.Header
-	var body rpcMiniBlock
-
-	if err := json.Unmarshal(raw, &head); err != nil {
+	head, err := decodeBlockHeader(raw)
+	if err != nil {
 		return nil, err
 	}
+	var body rpcMiniBlock
 	if err := json.Unmarshal(raw, &body); err != nil {
 		return nil, err
 	}
@@ -391,7 +453,10 @@ func (s *Provider) getMiniBlock(ctx context.Context, method string, args ...inte
 
 	// TODO: might need MiniBlock return type here as well, as Transactions payload of *types.Block
 	// expects the full transaction object (need to confirm though)
-	return types.NewBlockWithHeader(head), nil
+	block := types.NewBlockWithHeader(head)
+	block.SetHash(body.Hash)
+
+	return block, nil
 }
 
 func toBlockNumArg(number *big.Int) string {
@@ -402,6 +467,14 @@ func toBlockNumArg(number *big.Int) string {
 	if number.Cmp(pending) == 0 {
 		return "pending"
 	}
+	if number.IsInt64() {
+		switch rpc.BlockNumber(number.Int64()) {
+		case rpc.FinalizedBlockNumber:
+			return "finalized"
+		case rpc.SafeBlockNumber:
+			return "safe"
+		}
+	}
 	return hexutil.EncodeBig(number)
 }
 
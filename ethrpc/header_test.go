@@ -0,0 +1,101 @@
+package ethrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockHeaderByNumber(t *testing.T) {
+	blockHash := common.HexToHash("0xabcd")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		// transactions must not be requested
+		var fullTxns bool
+		json.Unmarshal(req.Params[1], &fullTxns)
+		if fullTxns {
+			t.Errorf("unexpected request of full transactions for %s", req.Method)
+		}
+
+		var num hexutil.Uint64
+		json.Unmarshal(req.Params[0], &num)
+		if req.Method == "eth_getBlockByHash" {
+			var hash common.Hash
+			json.Unmarshal(req.Params[0], &hash)
+			if hash == blockHash {
+				num = 10
+			}
+		}
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": nil}
+		if num == 10 {
+			header := &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(0)}
+			var block map[string]interface{}
+			data, _ := json.Marshal(header)
+			json.Unmarshal(data, &block)
+			block["hash"] = blockHash
+			block["transactions"] = []common.Hash{common.HexToHash("0x01")}
+			block["uncles"] = []interface{}{}
+			resp["result"] = block
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+
+	block, err := provider.BlockHeaderByNumber(context.Background(), big.NewInt(10))
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), block.NumberU64())
+	require.Equal(t, blockHash, block.Hash())
+	require.Empty(t, block.Transactions())
+
+	block, err = provider.BlockHeaderByHash(context.Background(), blockHash)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), block.NumberU64())
+
+	_, err = provider.BlockHeaderByNumber(context.Background(), big.NewInt(11))
+	require.ErrorIs(t, err, ethereum.NotFound)
+}
+
+func TestBlockMissingHeader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		// a block without its header fields, as returned by some buggy rpc proxies
+		block := map[string]interface{}{
+			"hash":         common.HexToHash("0xabcd"),
+			"transactions": []interface{}{},
+			"uncles":       []interface{}{},
+		}
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": block}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+
+	_, err = provider.BlockByNumber(context.Background(), big.NewInt(10))
+	require.ErrorIs(t, err, ethrpc.ErrMissingBlockHeader)
+
+	_, err = provider.BlockHeaderByNumber(context.Background(), big.NewInt(10))
+	require.ErrorIs(t, err, ethrpc.ErrMissingBlockHeader)
+}
@@ -0,0 +1,88 @@
+package ethrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum/rpc"
+)
+
+var (
+	ErrUnreachable       = errors.New("ethrpc: node is unreachable")
+	ErrMethodUnsupported = errors.New("ethrpc: method is not supported by node")
+	ErrTimeout           = errors.New("ethrpc: request timed out")
+)
+
+// DefaultHealthCheckTimeout is used by HealthCheck when the passed context
+// has no deadline of its own.
+var DefaultHealthCheckTimeout = 5 * time.Second
+
+// HealthCheckError is returned by HealthCheck and reports which rpc method failed,
+// and the reason, which is one of ErrUnreachable, ErrMethodUnsupported or ErrTimeout.
+// Use errors.Is to check for the reason.
+type HealthCheckError struct {
+	Method string
+	Reason error
+	Err    error
+}
+
+func (e *HealthCheckError) Error() string {
+	return fmt.Sprintf("ethrpc: health check failed on %s: %v: %v", e.Method, e.Reason, e.Err)
+}
+
+func (e *HealthCheckError) Is(target error) bool {
+	return target == e.Reason
+}
+
+func (e *HealthCheckError) Unwrap() error {
+	return e.Err
+}
+
+// HealthCheck verifies the node is reachable and supports the basic methods
+// required by ethkit, ie. eth_chainId and eth_blockNumber. A nil error means
+// the endpoint is usable, otherwise a *HealthCheckError is returned.
+func (s *Provider) HealthCheck(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultHealthCheckTimeout)
+		defer cancel()
+	}
+
+	if _, err := s.ChainID(ctx); err != nil {
+		return newHealthCheckError(ctx, "eth_chainId", err)
+	}
+	if _, err := s.BlockNumber(ctx); err != nil {
+		return newHealthCheckError(ctx, "eth_blockNumber", err)
+	}
+	return nil
+}
+
+func newHealthCheckError(ctx context.Context, method string, err error) error {
+	reason := ErrUnreachable
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		reason = ErrTimeout
+	} else if isMethodUnsupportedErr(err) {
+		reason = ErrMethodUnsupported
+	}
+	return &HealthCheckError{Method: method, Reason: reason, Err: err}
+}
+
+// isMethodUnsupportedErr reports if the error returned by the node indicates
+// the rpc method is not available. Nodes are not consistent with the error code
+// they use, so we also check for common error messages.
+func isMethodUnsupportedErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601 {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "method not found") ||
+		strings.Contains(msg, "does not exist/is not available") ||
+		strings.Contains(msg, "not supported")
+}
@@ -0,0 +1,80 @@
+package ethrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthCheck(t *testing.T) {
+	var mu sync.Mutex
+	var unsupported string
+	var delay time.Duration
+
+	set := func(method string, d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		unsupported, delay = method, d
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		mu.Lock()
+		method, d := unsupported, delay
+		mu.Unlock()
+		time.Sleep(d)
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if req.Method == method {
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "the method " + req.Method + " does not exist/is not available"}
+		} else {
+			resp["result"] = "0x1"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+
+	// healthy
+	assert.NoError(t, provider.HealthCheck(context.Background()))
+
+	// unsupported method
+	set("eth_blockNumber", 0)
+	err = provider.HealthCheck(context.Background())
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ethrpc.ErrMethodUnsupported))
+
+	var hcErr *ethrpc.HealthCheckError
+	require.True(t, errors.As(err, &hcErr))
+	assert.Equal(t, "eth_blockNumber", hcErr.Method)
+
+	// timeout
+	set("", 200*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = provider.HealthCheck(ctx)
+	assert.True(t, errors.Is(err, ethrpc.ErrTimeout))
+
+	// unreachable
+	set("", 0)
+	srv.Close()
+	err = provider.HealthCheck(context.Background())
+	assert.True(t, errors.Is(err, ethrpc.ErrUnreachable))
+}
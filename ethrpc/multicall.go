@@ -0,0 +1,204 @@
+package ethrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/rpc"
+)
+
+// Multicall3Address is the address Multicall3 is deployed at on most chains,
+// see https://github.com/mds1/multicall.
+var Multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+var ErrMulticallFailed = errors.New("ethrpc: multicall failed, a call which does not allow failure reverted")
+
+// Call is a single contract call of a Multicall.
+type Call struct {
+	To   common.Address
+	Data []byte
+
+	// AllowFailure will report a reverted call in its Result, instead of
+	// failing the entire Multicall.
+	AllowFailure bool
+}
+
+// Result is the result of a single contract call of a Multicall.
+type Result struct {
+	Success    bool
+	ReturnData []byte
+
+	// Err is the revert error returned by the node for a failed call, when the calls were
+	// sent as a batch. It is always nil when the calls were aggregated by Multicall3,
+	// as the revert data is in ReturnData instead.
+	Err error
+}
+
+const multicall3ABIJSON = `[{"type":"function","name":"aggregate3","stateMutability":"payable","inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],"outputs":[{"name":"returnData","type":"tuple[]","components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]}]`
+
+var multicall3ABI = func() abi.ABI {
+	contractABI, err := abi.JSON(strings.NewReader(multicall3ABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return contractABI
+}()
+
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// Multicall executes the calls at the given block number, or the latest block when nil,
+// and returns their results in the same order. The calls are aggregated into a single
+// eth_call to Multicall3 when it is deployed on the chain, otherwise they are sent as a
+// JSON-RPC batch of eth_call's pinned to the same block. Whether Multicall3 is deployed
+// at the latest block is checked once, and cached by the provider, while for a given
+// block number it is checked at that block, as Multicall3 may not be deployed yet.
+// A reverted call which does not allow failure returns ErrMulticallFailed either way,
+// while other errors of a call, ie. of the node, are returned as is.
+func (s *Provider) Multicall(ctx context.Context, calls []Call, blockNumber *big.Int) ([]Result, error) {
+	if len(calls) == 0 {
+		return []Result{}, nil
+	}
+
+	available, err := s.hasMulticall3(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if available && blockNumber != nil {
+		code, err := s.CodeAt(ctx, Multicall3Address, blockNumber)
+		if err != nil {
+			return nil, fmt.Errorf("ethrpc: failed to check for multicall3 deployment at block %s: %w", blockNumber, err)
+		}
+		available = len(code) > 0
+	}
+	if available {
+		return s.multicallAggregate(ctx, calls, blockNumber)
+	}
+	return s.multicallBatch(ctx, calls, blockNumber)
+}
+
+func (s *Provider) hasMulticall3(ctx context.Context) (bool, error) {
+	s.multicallMu.Lock()
+	defer s.multicallMu.Unlock()
+
+	if s.multicall3 != nil {
+		return *s.multicall3, nil
+	}
+
+	code, err := s.CodeAt(ctx, Multicall3Address, nil)
+	if err != nil {
+		return false, fmt.Errorf("ethrpc: failed to check for multicall3 deployment: %w", err)
+	}
+	available := len(code) > 0
+	s.multicall3 = &available
+	return available, nil
+}
+
+func (s *Provider) multicallAggregate(ctx context.Context, calls []Call, blockNumber *big.Int) ([]Result, error) {
+	args := make([]multicall3Call, len(calls))
+	for i, call := range calls {
+		args[i] = multicall3Call{Target: call.To, AllowFailure: call.AllowFailure, CallData: call.Data}
+	}
+
+	calldata, err := multicall3ABI.Pack("aggregate3", args)
+	if err != nil {
+		return nil, fmt.Errorf("ethrpc: failed to encode multicall: %w", err)
+	}
+
+	out, err := s.CallContract(ctx, ethereum.CallMsg{To: &Multicall3Address, Data: calldata}, blockNumber)
+	if err != nil {
+		// aggregate3 reverts entirely when a call which does not allow failure reverts
+		if isRevertErr(err) {
+			return nil, fmt.Errorf("%w: %v", ErrMulticallFailed, err)
+		}
+		return nil, err
+	}
+
+	values, err := multicall3ABI.Unpack("aggregate3", out)
+	if err != nil {
+		return nil, fmt.Errorf("ethrpc: failed to decode multicall: %w", err)
+	}
+	var returnData []multicall3Result
+	if err := multicall3ABI.Methods["aggregate3"].Outputs.Copy(&returnData, values); err != nil {
+		return nil, fmt.Errorf("ethrpc: failed to decode multicall: %w", err)
+	}
+	if len(returnData) != len(calls) {
+		return nil, fmt.Errorf("ethrpc: multicall returned %d results for %d calls", len(returnData), len(calls))
+	}
+
+	results := make([]Result, len(calls))
+	for i, r := range returnData {
+		results[i] = Result{Success: r.Success, ReturnData: r.ReturnData}
+	}
+	return results, nil
+}
+
+func (s *Provider) multicallBatch(ctx context.Context, calls []Call, blockNumber *big.Int) ([]Result, error) {
+	// pin the calls to the same block, as the latest block may change mid-batch
+	if blockNumber == nil {
+		latest, err := s.BlockNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		blockNumber = big.NewInt(0).SetUint64(latest)
+	}
+
+	returnData := make([]hexutil.Bytes, len(calls))
+	batch := make([]rpc.BatchElem, len(calls))
+	for i, call := range calls {
+		to := call.To
+		batch[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args:   []interface{}{toCallArg(ethereum.CallMsg{To: &to, Data: call.Data}), toBlockNumArg(blockNumber)},
+			Result: &returnData[i],
+		}
+	}
+
+	err := s.RPC.BatchCallContext(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(calls))
+	for i, elem := range batch {
+		if elem.Error != nil {
+			// only a reverted call is a failure of the call, other errors fail the multicall
+			if !isRevertErr(elem.Error) {
+				return nil, fmt.Errorf("ethrpc: multicall call %d failed: %w", i, elem.Error)
+			}
+			if !calls[i].AllowFailure {
+				return nil, fmt.Errorf("%w: call %d: %v", ErrMulticallFailed, i, elem.Error)
+			}
+			results[i] = Result{Success: false, Err: elem.Error}
+			continue
+		}
+		results[i] = Result{Success: true, ReturnData: returnData[i]}
+	}
+	return results, nil
+}
+
+// isRevertErr reports if the error returned by the node for an eth_call indicates the
+// call reverted. Nodes are not consistent with the error code they use, so we also check
+// for the error message.
+func isRevertErr(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == 3 {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "execution reverted")
+}
@@ -0,0 +1,192 @@
+package ethrpc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// newMulticallNode returns a node where every eth_call echoes its calldata, reverts if
+// the calldata is 0xff, or fails with a node error if the calldata is 0xfe. Multicall3 is deployed from block 0x8 when withMulticall3 is
+// set, and its aggregate3 reverts if a call which does not allow failure reverts.
+func newMulticallNode(t *testing.T, withMulticall3 bool, getCodeCalls *int32) *httptest.Server {
+	multicall3ABI, err := abi.JSON(strings.NewReader(`[{"type":"function","name":"aggregate3","stateMutability":"payable","inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],"outputs":[{"name":"returnData","type":"tuple[]","components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]}]`))
+	require.NoError(t, err)
+
+	handle := func(req rpcRequest) map[string]interface{} {
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "eth_getCode":
+			atomic.AddInt32(getCodeCalls, 1)
+			var block string
+			json.Unmarshal(req.Params[1], &block)
+			if withMulticall3 && (block == "latest" || hexutil.MustDecodeUint64(block) >= 8) {
+				resp["result"] = "0x01"
+			} else {
+				resp["result"] = "0x"
+			}
+		case "eth_blockNumber":
+			resp["result"] = "0x10"
+		case "eth_call":
+			var arg struct {
+				To   common.Address `json:"to"`
+				Data hexutil.Bytes  `json:"data"`
+			}
+			json.Unmarshal(req.Params[0], &arg)
+
+			if arg.To != ethrpc.Multicall3Address {
+				if bytes.Equal(arg.Data, []byte{0xff}) {
+					resp["error"] = map[string]interface{}{"code": 3, "message": "execution reverted"}
+				} else if bytes.Equal(arg.Data, []byte{0xfe}) {
+					resp["error"] = map[string]interface{}{"code": -32000, "message": "header not found"}
+				} else {
+					resp["result"] = hexutil.Bytes(arg.Data)
+				}
+				return resp
+			}
+
+			values, err := multicall3ABI.Methods["aggregate3"].Inputs.Unpack(arg.Data[4:])
+			require.NoError(t, err)
+			calls := values[0].([]struct {
+				Target       common.Address `json:"target"`
+				AllowFailure bool           `json:"allowFailure"`
+				CallData     []byte         `json:"callData"`
+			})
+
+			type result struct {
+				Success    bool
+				ReturnData []byte
+			}
+			results := []result{}
+			for _, call := range calls {
+				success := !bytes.Equal(call.CallData, []byte{0xff})
+				if !success && !call.AllowFailure {
+					resp["error"] = map[string]interface{}{"code": 3, "message": "execution reverted: Multicall3: call failed"}
+					return resp
+				}
+				results = append(results, result{Success: success, ReturnData: call.CallData})
+			}
+			out, err := multicall3ABI.Methods["aggregate3"].Outputs.Pack(results)
+			require.NoError(t, err)
+			resp["result"] = hexutil.Bytes(out)
+		}
+		return resp
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+
+		if len(body) > 0 && body[0] == '[' {
+			var reqs []rpcRequest
+			json.Unmarshal(body, &reqs)
+			resps := []map[string]interface{}{}
+			for _, req := range reqs {
+				resps = append(resps, handle(req))
+			}
+			json.NewEncoder(w).Encode(resps)
+			return
+		}
+
+		var req rpcRequest
+		json.Unmarshal(body, &req)
+		json.NewEncoder(w).Encode(handle(req))
+	}))
+}
+
+func TestMulticall(t *testing.T) {
+	for _, withMulticall3 := range []bool{true, false} {
+		var getCodeCalls int32
+		srv := newMulticallNode(t, withMulticall3, &getCodeCalls)
+
+		provider, err := ethrpc.NewProvider(srv.URL)
+		require.NoError(t, err)
+
+		to := common.HexToAddress("0x3535353535353535353535353535353535353535")
+		calls := []ethrpc.Call{
+			{To: to, Data: []byte{1, 2, 3}},
+			{To: to, Data: []byte{0xff}, AllowFailure: true},
+			{To: to, Data: []byte{4}},
+		}
+
+		results, err := provider.Multicall(context.Background(), calls, nil)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.True(t, results[0].Success)
+		assert.Equal(t, []byte{1, 2, 3}, results[0].ReturnData)
+		assert.False(t, results[1].Success)
+		assert.True(t, results[2].Success)
+		assert.Equal(t, []byte{4}, results[2].ReturnData)
+
+		if !withMulticall3 {
+			assert.Error(t, results[1].Err)
+		}
+
+		// a failing call which does not allow failure fails the multicall
+		calls[1].AllowFailure = false
+		_, err = provider.Multicall(context.Background(), calls, nil)
+		assert.ErrorIs(t, err, ethrpc.ErrMulticallFailed)
+
+		// a node error is not a reverted call, even if the call allows failure
+		if !withMulticall3 {
+			_, err = provider.Multicall(context.Background(), []ethrpc.Call{{To: to, Data: []byte{0xfe}, AllowFailure: true}}, nil)
+			assert.ErrorContains(t, err, "header not found")
+			assert.NotErrorIs(t, err, ethrpc.ErrMulticallFailed)
+		}
+
+		// multicall3 deployment is only checked once
+		assert.Equal(t, int32(1), atomic.LoadInt32(&getCodeCalls))
+		srv.Close()
+	}
+}
+
+func TestMulticallAtBlock(t *testing.T) {
+	var getCodeCalls int32
+	srv := newMulticallNode(t, true, &getCodeCalls)
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+
+	to := common.HexToAddress("0x3535353535353535353535353535353535353535")
+	calls := []ethrpc.Call{
+		{To: to, Data: []byte{1, 2, 3}},
+		{To: to, Data: []byte{0xff}, AllowFailure: true},
+	}
+
+	// multicall3 is not deployed yet at block 0x5, so the calls are sent as a batch
+	results, err := provider.Multicall(context.Background(), calls, big.NewInt(5))
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Success)
+	assert.False(t, results[1].Success)
+	assert.Error(t, results[1].Err)
+
+	// and aggregated by multicall3 once deployed
+	results, err = provider.Multicall(context.Background(), calls, big.NewInt(9))
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Success)
+	assert.False(t, results[1].Success)
+	assert.NoError(t, results[1].Err)
+}
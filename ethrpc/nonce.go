@@ -0,0 +1,89 @@
+package ethrpc
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+)
+
+// NonceTracker hands out sequential nonces for an account, for tools which send many
+// transactions without waiting for each of them to be mined. The first nonce is the
+// pending nonce of the account, fetched with PendingNonceAt, which counts the in-flight
+// transactions of the node's mempool, unlike NonceAt which only counts mined ones.
+//
+// Nonces are handed out locally from then on, so if a transaction fails to be sent,
+// call Release to hand out its nonce again, as the transactions sent concurrently with
+// the next nonces would otherwise wait on the gap forever. Reset resyncs with the node
+// instead, which is only safe once no other transactions are being sent, as the node's
+// pending nonce doesn't count the transactions which have yet to reach it.
+type NonceTracker struct {
+	provider *Provider
+	account  common.Address
+
+	next     uint64
+	released []uint64
+	synced   bool
+	mu       sync.Mutex
+}
+
+func NewNonceTracker(provider *Provider, account common.Address) *NonceTracker {
+	return &NonceTracker{
+		provider: provider,
+		account:  account,
+	}
+}
+
+// Next returns the next nonce of the account, or the lowest released nonce. It is safe
+// for concurrent use, and each nonce is only returned once until released or Reset.
+func (n *NonceTracker) Next(ctx context.Context) (uint64, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(n.released) > 0 {
+		nonce := n.released[0]
+		n.released = n.released[1:]
+		return nonce, nil
+	}
+
+	if !n.synced {
+		nonce, err := n.provider.PendingNonceAt(ctx, n.account)
+		if err != nil {
+			return 0, err
+		}
+		n.next = nonce
+		n.synced = true
+	}
+
+	nonce := n.next
+	n.next++
+	return nonce, nil
+}
+
+// Release returns a nonce which was handed out by Next but not used, ie. as its
+// transaction failed to be sent, so it is handed out again before any new nonce.
+func (n *NonceTracker) Release(nonce uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !n.synced || nonce >= n.next {
+		return
+	}
+	i := sort.Search(len(n.released), func(i int) bool { return n.released[i] >= nonce })
+	if i < len(n.released) && n.released[i] == nonce {
+		return
+	}
+	n.released = append(n.released, 0)
+	copy(n.released[i+1:], n.released[i:])
+	n.released[i] = nonce
+}
+
+// Reset discards the local nonce and the released nonces, so the next nonce is fetched
+// from the node again.
+func (n *NonceTracker) Reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.synced = false
+	n.released = nil
+}
@@ -0,0 +1,97 @@
+package ethrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonceTracker(t *testing.T) {
+	account := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	var pending, requests uint64 = 5, 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		var tag string
+		if req.Method == "eth_getTransactionCount" && len(req.Params) == 2 {
+			json.Unmarshal(req.Params[1], &tag)
+		}
+		if tag == "pending" {
+			atomic.AddUint64(&requests, 1)
+			resp["result"] = hexutil.Uint64(atomic.LoadUint64(&pending))
+		} else {
+			resp["error"] = map[string]interface{}{"code": -32602, "message": "unexpected request"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+
+	nonce, err := provider.PendingNonceAt(context.Background(), account)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), nonce)
+
+	tracker := ethrpc.NewNonceTracker(provider, account)
+
+	// nonces are handed out once, across goroutines
+	var mu sync.Mutex
+	seen := map[uint64]bool{}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := tracker.Next(context.Background())
+			assert.NoError(t, err)
+			mu.Lock()
+			seen[nonce] = true
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, seen, 10)
+	for nonce := uint64(5); nonce < 15; nonce++ {
+		require.True(t, seen[nonce])
+	}
+	require.Equal(t, uint64(2), atomic.LoadUint64(&requests))
+
+	// resync after a failed send
+	atomic.StoreUint64(&pending, 7)
+	tracker.Reset()
+	nonce, err = tracker.Next(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), nonce)
+
+	// released nonces are handed out again first, lowest first, without a resync
+	for i := 0; i < 3; i++ {
+		_, err = tracker.Next(context.Background())
+		require.NoError(t, err)
+	}
+	tracker.Release(10)
+	tracker.Release(8)
+	tracker.Release(8)
+	for _, expected := range []uint64{8, 10, 11} {
+		nonce, err = tracker.Next(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, expected, nonce)
+	}
+	require.Equal(t, uint64(3), atomic.LoadUint64(&requests))
+}
@@ -0,0 +1,353 @@
+package ethrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("ethrpc: circuit breaker is open for all endpoints")
+
+// RetryPolicy determines how failed requests are retried. Requests are retried on
+// network errors, and on HTTP 429 and 5xx responses. JSON-RPC errors, ie. reverts, are
+// never retried. Each retry is sent to the next available endpoint.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts per request, including the first.
+	MaxAttempts int
+
+	// MinBackoff is the delay before the first retry, which doubles on every retry
+	// up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// RateLimitPolicy limits the rate of requests sent to each endpoint.
+type RateLimitPolicy struct {
+	// RequestsPerSecond per endpoint, where 0 means unlimited. Overridden by the
+	// MaxRequestPerSecond of the endpoint's NodeConfig when set.
+	RequestsPerSecond float64
+
+	// Burst is the number of requests which may be sent at once.
+	Burst int
+}
+
+// CircuitBreakerPolicy stops sending requests to an endpoint after consecutive failures,
+// until the cooldown has passed.
+type CircuitBreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failures which opens the circuit,
+	// where 0 disables the circuit breaker.
+	FailureThreshold int
+
+	// Cooldown is how long the circuit stays open, after which a single request is let
+	// through to probe the endpoint.
+	Cooldown time.Duration
+}
+
+type ResilientOptions struct {
+	Retry          RetryPolicy
+	RateLimit      RateLimitPolicy
+	CircuitBreaker CircuitBreakerPolicy
+
+	// HTTPClient is the underlying client used to send requests, its Transport is
+	// wrapped by the resilient transport.
+	HTTPClient *http.Client
+}
+
+var DefaultResilientOptions = ResilientOptions{
+	Retry: RetryPolicy{
+		MaxAttempts: 5,
+		MinBackoff:  100 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+	},
+	RateLimit: RateLimitPolicy{
+		RequestsPerSecond: 0, // unlimited
+		Burst:             1,
+	},
+	CircuitBreaker: CircuitBreakerPolicy{
+		FailureThreshold: 5,
+		Cooldown:         30 * time.Second,
+	},
+	HTTPClient: nil, // http.DefaultClient
+}
+
+// ResilientProvider is a Provider which bundles retries, rate limiting, failover across
+// multiple endpoints and circuit breaking. The policies are applied at the transport
+// level, so every method of the embedded Provider benefits from them, and the embedded
+// Provider can be passed anywhere a *Provider is expected, ie. ethmonitor.NewMonitor.
+type ResilientProvider struct {
+	*Provider
+	transport *resilientTransport
+}
+
+// NewResilientProvider returns a provider for the given http endpoints of the same chain.
+// Requests are sent to the first endpoint, failing over to the next endpoints in order.
+func NewResilientProvider(endpoints []string, options ...ResilientOptions) (*ResilientProvider, error) {
+	config := &Config{}
+	for _, endpoint := range endpoints {
+		config.AddNode(NodeConfig{URL: endpoint})
+	}
+	return NewResilientProviderWithConfig(config, options...)
+}
+
+func NewResilientProviderWithConfig(config *Config, options ...ResilientOptions) (*ResilientProvider, error) {
+	opts := DefaultResilientOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if len(config.Nodes) == 0 {
+		return nil, errors.New("ethrpc: resilient provider requires at least one endpoint")
+	}
+	if opts.Retry.MaxAttempts < 1 {
+		opts.Retry.MaxAttempts = 1
+	}
+
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	transport := &resilientTransport{
+		base:   base,
+		policy: opts,
+	}
+	for _, node := range config.Nodes {
+		u, err := url.Parse(node.URL)
+		if err != nil {
+			return nil, fmt.Errorf("ethrpc: invalid endpoint url %q: %w", node.URL, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("ethrpc: resilient provider requires http endpoints, got %q", node.URL)
+		}
+
+		rps := opts.RateLimit.RequestsPerSecond
+		if node.MaxRequestPerSecond > 0 {
+			rps = node.MaxRequestPerSecond
+		}
+		transport.endpoints = append(transport.endpoints, &endpoint{
+			url:     u,
+			limiter: newRateLimiter(rps, opts.RateLimit.Burst),
+			breaker: &circuitBreaker{policy: opts.CircuitBreaker},
+		})
+	}
+
+	client := *httpClient
+	client.Transport = transport
+
+	provider, err := NewProviderWithConfig(config, &client)
+	if err != nil {
+		return nil, err
+	}
+	return &ResilientProvider{Provider: provider, transport: transport}, nil
+}
+
+// Endpoints returns the endpoint urls, and whether the circuit breaker of each
+// is currently open.
+func (p *ResilientProvider) Endpoints() map[string]bool {
+	status := map[string]bool{}
+	for _, e := range p.transport.endpoints {
+		status[e.url.String()] = e.breaker.isOpen()
+	}
+	return status
+}
+
+type endpoint struct {
+	url     *url.URL
+	limiter *rateLimiter
+	breaker *circuitBreaker
+}
+
+type resilientTransport struct {
+	base      http.RoundTripper
+	policy    ResilientOptions
+	endpoints []*endpoint
+}
+
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := req.Context()
+	backoff := t.policy.Retry.MinBackoff
+
+	var lastErr error
+	for attempt := 0; attempt < t.policy.Retry.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > t.policy.Retry.MaxBackoff {
+				backoff = t.policy.Retry.MaxBackoff
+			}
+		}
+
+		e := t.nextEndpoint(attempt)
+		if e == nil {
+			lastErr = ErrCircuitOpen
+			continue
+		}
+		if err := e.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		u := *e.url
+		r := req.Clone(ctx)
+		r.URL = &u
+		r.Host = ""
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+
+		resp, err := t.base.RoundTrip(r)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			e.breaker.failure()
+			lastErr = err
+			continue
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			e.breaker.failure()
+			lastErr = fmt.Errorf("ethrpc: %s responded with status %d", e.url.Host, resp.StatusCode)
+			if attempt == t.policy.Retry.MaxAttempts-1 {
+				return resp, nil
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			continue
+		}
+
+		e.breaker.success()
+		return resp, nil
+	}
+
+	return nil, lastErr
+}
+
+// nextEndpoint returns the first endpoint with a closed circuit, starting from the
+// endpoint for the given attempt, so each retry fails over to the next endpoint.
+func (t *resilientTransport) nextEndpoint(attempt int) *endpoint {
+	n := len(t.endpoints)
+	for i := 0; i < n; i++ {
+		e := t.endpoints[(attempt+i)%n]
+		if e.breaker.allow() {
+			return e
+		}
+	}
+	return nil
+}
+
+type circuitBreaker struct {
+	policy    CircuitBreakerPolicy
+	failures  int
+	openUntil time.Time
+	mu        sync.Mutex
+}
+
+func (b *circuitBreaker) allow() bool {
+	if b.policy.FailureThreshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.policy.FailureThreshold {
+		return true
+	}
+	if time.Now().Before(b.openUntil) {
+		return false
+	}
+	// half-open, let a single probe through until it succeeds or fails
+	b.openUntil = time.Now().Add(b.policy.Cooldown)
+	return true
+}
+
+func (b *circuitBreaker) isOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.policy.FailureThreshold > 0 && b.failures >= b.policy.FailureThreshold && time.Now().Before(b.openUntil)
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+}
+
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.policy.FailureThreshold > 0 && b.failures >= b.policy.FailureThreshold {
+		b.openUntil = time.Now().Add(b.policy.Cooldown)
+	}
+}
+
+// rateLimiter is a token bucket, which refills at rps tokens per second up to burst.
+type rateLimiter struct {
+	rps    float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rps: rps, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l.rps <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rps
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	// reserve a token, waiting for the deficit to refill
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rps * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
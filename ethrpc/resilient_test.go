@@ -0,0 +1,87 @@
+package ethrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBlockNumberNode(status *int32, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		if code := atomic.LoadInt32(status); code != http.StatusOK {
+			w.WriteHeader(int(code))
+			return
+		}
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": "0x10"})
+	}))
+}
+
+func TestResilientProviderFailover(t *testing.T) {
+	primaryStatus, backupStatus := int32(http.StatusServiceUnavailable), int32(http.StatusOK)
+	var primaryHits, backupHits int32
+
+	primary := newBlockNumberNode(&primaryStatus, &primaryHits)
+	defer primary.Close()
+	backup := newBlockNumberNode(&backupStatus, &backupHits)
+	defer backup.Close()
+
+	opts := ethrpc.DefaultResilientOptions
+	opts.Retry.MinBackoff = time.Millisecond
+	opts.CircuitBreaker.FailureThreshold = 2
+	opts.CircuitBreaker.Cooldown = time.Hour
+
+	provider, err := ethrpc.NewResilientProvider([]string{primary.URL, backup.URL}, opts)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		num, err := provider.BlockNumber(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, uint64(16), num)
+	}
+
+	// the primary circuit opened after 2 failures, all other requests went to the backup
+	assert.Equal(t, int32(2), atomic.LoadInt32(&primaryHits))
+	assert.Equal(t, int32(5), atomic.LoadInt32(&backupHits))
+	assert.Equal(t, map[string]bool{primary.URL: true, backup.URL: false}, provider.Endpoints())
+
+	// all endpoints failing
+	atomic.StoreInt32(&backupStatus, http.StatusBadGateway)
+	_, err = provider.BlockNumber(context.Background())
+	assert.Error(t, err)
+}
+
+func TestResilientProviderRateLimit(t *testing.T) {
+	status := int32(http.StatusOK)
+	var hits int32
+	node := newBlockNumberNode(&status, &hits)
+	defer node.Close()
+
+	opts := ethrpc.DefaultResilientOptions
+	opts.RateLimit.RequestsPerSecond = 50
+	opts.RateLimit.Burst = 1
+
+	provider, err := ethrpc.NewResilientProvider([]string{node.URL}, opts)
+	require.NoError(t, err)
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		_, err := provider.BlockNumber(context.Background())
+		require.NoError(t, err)
+	}
+	// 5 requests beyond the burst, at 20ms each
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
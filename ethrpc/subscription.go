@@ -0,0 +1,403 @@
+package ethrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/0xsequence/ethkit/go-ethereum/rpc"
+)
+
+var ErrStreamingUnavailable = errors.New("ethrpc: streaming is unavailable, provider has no websocket endpoint")
+
+var (
+	// StreamReconnectMinBackoff is the initial delay before re-dialing a dropped
+	// websocket connection. The delay doubles on every failed attempt.
+	StreamReconnectMinBackoff = 1 * time.Second
+
+	// StreamReconnectMaxBackoff is the maximum delay between re-dial attempts.
+	StreamReconnectMaxBackoff = 30 * time.Second
+)
+
+// Reconnect is delivered on a Subscription's Reconnected channel once the subscription
+// has been re-established after the websocket connection dropped. Consumers should
+// backfill any data after LastBlockNumber, as notifications sent while the connection
+// was down are lost.
+type Reconnect struct {
+	// LastBlockNumber is the block number of the last notification received before
+	// the connection dropped, or 0 when unknown (ie. pending transactions).
+	LastBlockNumber uint64
+
+	// Err is the error which caused the connection to drop.
+	Err error
+}
+
+// Subscription is an eth_subscribe subscription managed by the Provider. When the
+// websocket connection drops, the provider re-dials the node with backoff and
+// re-establishes all active subscriptions automatically.
+type Subscription struct {
+	stream  *stream
+	args    []interface{}
+	deliver func(ctx context.Context, raw json.RawMessage) (uint64, error)
+
+	lastBlockNum uint64
+	reconnected  chan Reconnect
+	err          chan error
+	decodeErr    chan error
+
+	done    chan struct{}
+	closed  bool
+	closeMu sync.Mutex
+}
+
+var _ ethereum.Subscription = &Subscription{}
+
+// Reconnected returns a channel which receives a signal every time the subscription
+// is re-established after a connection drop. If a previous signal has not been read
+// yet, it is kept as-is, as it refers to the earliest gap.
+func (s *Subscription) Reconnected() <-chan Reconnect {
+	return s.reconnected
+}
+
+// Err returns a channel which receives non-recoverable subscription errors, ie. when
+// the node rejects re-subscribing. The channel is closed by Unsubscribe.
+func (s *Subscription) Err() <-chan error {
+	return s.err
+}
+
+// DecodeErr returns a channel which receives the errors of notifications which failed to
+// be decoded, ie. a null header, which are skipped while the subscription keeps running.
+// If a previous error has not been read yet, new ones are dropped.
+func (s *Subscription) DecodeErr() <-chan error {
+	return s.decodeErr
+}
+
+// Unsubscribe stops the subscription. It can safely be called more than once.
+func (s *Subscription) Unsubscribe() {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.done)
+	close(s.err)
+	s.stream.remove(s)
+}
+
+func (s *Subscription) sendErr(err error) {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.err <- err:
+	default:
+	}
+}
+
+// run subscribes on the given client and forwards notifications until the
+// subscription is dropped or unsubscribed.
+func (s *Subscription) run(client *rpc.Client, sub *rpc.ClientSubscription, rawCh chan json.RawMessage) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		select {
+		case <-s.done:
+			sub.Unsubscribe()
+			return
+
+		case raw := <-rawCh:
+			blockNum, err := s.deliver(ctx, raw)
+			if err != nil {
+				if ctx.Err() == nil {
+					select {
+					case s.decodeErr <- fmt.Errorf("ethrpc: failed to decode subscription notification: %w", err):
+					default:
+					}
+				}
+				continue
+			}
+			if blockNum > 0 {
+				atomic.StoreUint64(&s.lastBlockNum, blockNum)
+			}
+
+		case err := <-sub.Err():
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+			if err == nil {
+				err = errors.New("ethrpc: websocket connection closed")
+			}
+			s.stream.reconnect(client, err)
+			return
+		}
+	}
+}
+
+func (s *Subscription) subscribe(ctx context.Context, client *rpc.Client) error {
+	rawCh := make(chan json.RawMessage)
+	sub, err := client.EthSubscribe(ctx, rawCh, s.args...)
+	if err != nil {
+		return err
+	}
+	go s.run(client, sub, rawCh)
+	return nil
+}
+
+// StreamNewHeads subscribes to new block headers over the provider's websocket endpoint.
+func (s *Provider) StreamNewHeads(ctx context.Context, ch chan<- *types.Header) (*Subscription, error) {
+	return s.streamSubscribe(ctx, []interface{}{"newHeads"}, func(ctx context.Context, raw json.RawMessage) (uint64, error) {
+		var header *types.Header
+		if err := json.Unmarshal(raw, &header); err != nil {
+			return 0, err
+		}
+		if header == nil || header.Number == nil {
+			return 0, errors.New("header is missing its number")
+		}
+		select {
+		case ch <- header:
+		case <-ctx.Done():
+		}
+		return header.Number.Uint64(), nil
+	})
+}
+
+// StreamFilterLogs subscribes to logs matching the filter query over the provider's
+// websocket endpoint.
+func (s *Provider) StreamFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (*Subscription, error) {
+	arg, err := toFilterArg(q)
+	if err != nil {
+		return nil, err
+	}
+	return s.streamSubscribe(ctx, []interface{}{"logs", arg}, func(ctx context.Context, raw json.RawMessage) (uint64, error) {
+		var log types.Log
+		if err := json.Unmarshal(raw, &log); err != nil {
+			return 0, err
+		}
+		select {
+		case ch <- log:
+		case <-ctx.Done():
+		}
+		return log.BlockNumber, nil
+	})
+}
+
+// StreamPendingTransactions subscribes to the hashes of transactions entering the
+// node's mempool over the provider's websocket endpoint.
+func (s *Provider) StreamPendingTransactions(ctx context.Context, ch chan<- common.Hash) (*Subscription, error) {
+	return s.streamSubscribe(ctx, []interface{}{"newPendingTransactions"}, func(ctx context.Context, raw json.RawMessage) (uint64, error) {
+		var hash common.Hash
+		if err := json.Unmarshal(raw, &hash); err != nil {
+			return 0, err
+		}
+		select {
+		case ch <- hash:
+		case <-ctx.Done():
+		}
+		return 0, nil
+	})
+}
+
+func (s *Provider) streamSubscribe(ctx context.Context, args []interface{}, deliver func(context.Context, json.RawMessage) (uint64, error)) (*Subscription, error) {
+	stream, err := s.getStream()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := stream.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		stream:      stream,
+		args:        args,
+		deliver:     deliver,
+		reconnected: make(chan Reconnect, 1),
+		err:         make(chan error, 1),
+		decodeErr:   make(chan error, 1),
+		done:        make(chan struct{}),
+	}
+
+	stream.add(sub)
+	err = sub.subscribe(ctx, client)
+	if err != nil {
+		stream.remove(sub)
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *Provider) getStream() (*stream, error) {
+	s.streamMu.Lock()
+	defer s.streamMu.Unlock()
+
+	if s.stream != nil {
+		return s.stream, nil
+	}
+
+	wsURL := s.Config.Nodes[0].WSURL
+	if wsURL == "" && isWebsocketURL(s.Config.Nodes[0].URL) {
+		wsURL = s.Config.Nodes[0].URL
+	}
+	if wsURL == "" {
+		return nil, ErrStreamingUnavailable
+	}
+
+	s.stream = &stream{
+		url:  wsURL,
+		subs: map[*Subscription]struct{}{},
+	}
+	return s.stream, nil
+}
+
+// stream manages the websocket connection shared by all subscriptions of a provider.
+type stream struct {
+	url          string
+	client       *rpc.Client
+	subs         map[*Subscription]struct{}
+	reconnecting bool
+	mu           sync.Mutex
+}
+
+func (s *stream) getClient(ctx context.Context) (*rpc.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil {
+		return s.client, nil
+	}
+	client, err := rpc.DialWebsocket(ctx, s.url, "")
+	if err != nil {
+		return nil, err
+	}
+	s.client = client
+	return client, nil
+}
+
+func (s *stream) add(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs[sub] = struct{}{}
+}
+
+func (s *stream) remove(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subs, sub)
+}
+
+// reconnect re-dials the node and re-establishes all active subscriptions. It is
+// called by every subscription which observed the connection drop, but only the
+// first caller for a given client performs the reconnect.
+func (s *stream) reconnect(client *rpc.Client, cause error) {
+	s.mu.Lock()
+	if s.reconnecting || s.client != client {
+		s.mu.Unlock()
+		return
+	}
+	s.reconnecting = true
+	s.mu.Unlock()
+
+	go func() {
+		backoff := StreamReconnectMinBackoff
+		for {
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > StreamReconnectMaxBackoff {
+				backoff = StreamReconnectMaxBackoff
+			}
+
+			s.mu.Lock()
+			if len(s.subs) == 0 {
+				// nothing left to re-establish, we'll dial again on the next subscription
+				s.client.Close()
+				s.client = nil
+				s.reconnecting = false
+				s.mu.Unlock()
+				return
+			}
+			s.mu.Unlock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), StreamReconnectMaxBackoff)
+			newClient, err := rpc.DialWebsocket(ctx, s.url, "")
+			cancel()
+			if err != nil {
+				continue
+			}
+
+			s.mu.Lock()
+			s.client.Close()
+			s.client = newClient
+			s.reconnecting = false
+			subs := make([]*Subscription, 0, len(s.subs))
+			for sub := range s.subs {
+				subs = append(subs, sub)
+			}
+			s.mu.Unlock()
+
+			for _, sub := range subs {
+				ctx, cancel := context.WithTimeout(context.Background(), StreamReconnectMaxBackoff)
+				err := sub.subscribe(ctx, newClient)
+				cancel()
+				if err != nil {
+					sub.sendErr(fmt.Errorf("ethrpc: failed to re-subscribe after reconnect: %w", err))
+					continue
+				}
+				select {
+				case sub.reconnected <- Reconnect{LastBlockNumber: atomic.LoadUint64(&sub.lastBlockNum), Err: cause}:
+				default:
+				}
+			}
+			return
+		}
+	}()
+}
+
+func isWebsocketURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "ws" || u.Scheme == "wss"
+}
+
+func toFilterArg(q ethereum.FilterQuery) (interface{}, error) {
+	arg := map[string]interface{}{
+		"address": q.Addresses,
+		"topics":  q.Topics,
+	}
+	if q.BlockHash != nil {
+		arg["blockHash"] = *q.BlockHash
+		if q.FromBlock != nil || q.ToBlock != nil {
+			return nil, fmt.Errorf("ethrpc: cannot specify both BlockHash and FromBlock/ToBlock")
+		}
+	} else {
+		if q.FromBlock == nil {
+			arg["fromBlock"] = "0x0"
+		} else {
+			arg["fromBlock"] = toBlockNumArg(q.FromBlock)
+		}
+		arg["toBlock"] = toBlockNumArg(q.ToBlock)
+	}
+	return arg, nil
+}
@@ -0,0 +1,176 @@
+package ethrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+// wsNode is a minimal websocket json-rpc node which supports eth_subscribe
+type wsNode struct {
+	conns map[*websocket.Conn]struct{}
+	mu    sync.Mutex
+}
+
+func newWSNode() (*wsNode, *httptest.Server) {
+	node := &wsNode{conns: map[*websocket.Conn]struct{}{}}
+	upgrader := websocket.Upgrader{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		for {
+			var req struct {
+				ID     json.RawMessage `json:"id"`
+				Method string          `json:"method"`
+			}
+			if err := conn.ReadJSON(&req); err != nil {
+				node.mu.Lock()
+				delete(node.conns, conn)
+				node.mu.Unlock()
+				return
+			}
+
+			node.mu.Lock()
+			if req.Method == "eth_subscribe" {
+				node.conns[conn] = struct{}{}
+				conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": "0x1"})
+			} else {
+				conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": true})
+			}
+			node.mu.Unlock()
+		}
+	}))
+
+	return node, srv
+}
+
+func (n *wsNode) push(header *types.Header) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for conn := range n.conns {
+		conn.WriteJSON(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  "eth_subscription",
+			"params":  map[string]interface{}{"subscription": "0x1", "result": header},
+		})
+	}
+	return len(n.conns)
+}
+
+func (n *wsNode) dropConnections() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for conn := range n.conns {
+		conn.Close()
+		delete(n.conns, conn)
+	}
+}
+
+func (n *wsNode) waitForSubscribers(t *testing.T) {
+	require.Eventually(t, func() bool {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		return len(n.conns) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestStreamNewHeadsReconnect(t *testing.T) {
+	ethrpc.StreamReconnectMinBackoff = 10 * time.Millisecond
+
+	node, srv := newWSNode()
+	defer srv.Close()
+
+	config := &ethrpc.Config{}
+	config.AddNode(ethrpc.NodeConfig{URL: srv.URL, WSURL: "ws" + strings.TrimPrefix(srv.URL, "http")})
+	provider, err := ethrpc.NewProviderWithConfig(config)
+	require.NoError(t, err)
+
+	ch := make(chan *types.Header, 10)
+	sub, err := provider.StreamNewHeads(context.Background(), ch)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	node.waitForSubscribers(t)
+	node.push(mockHeader(1))
+	require.Equal(t, uint64(1), (<-ch).Number.Uint64())
+
+	// drop the connection, and expect the subscription to be re-established
+	node.dropConnections()
+
+	select {
+	case reconnect := <-sub.Reconnected():
+		require.Equal(t, uint64(1), reconnect.LastBlockNumber)
+		require.Error(t, reconnect.Err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reconnect")
+	}
+
+	node.waitForSubscribers(t)
+	node.push(mockHeader(2))
+	require.Equal(t, uint64(2), (<-ch).Number.Uint64())
+}
+
+func TestStreamNewHeadsNullHeader(t *testing.T) {
+	node, srv := newWSNode()
+	defer srv.Close()
+
+	config := &ethrpc.Config{}
+	config.AddNode(ethrpc.NodeConfig{URL: srv.URL, WSURL: "ws" + strings.TrimPrefix(srv.URL, "http")})
+	provider, err := ethrpc.NewProviderWithConfig(config)
+	require.NoError(t, err)
+
+	ch := make(chan *types.Header, 10)
+	sub, err := provider.StreamNewHeads(context.Background(), ch)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	// a null header is skipped and reported as a decode error, instead of being delivered
+	node.waitForSubscribers(t)
+	node.push(nil)
+
+	select {
+	case err := <-sub.DecodeErr():
+		require.ErrorContains(t, err, "header is missing its number")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the decode error")
+	}
+
+	// and the subscription keeps running, without a terminal error
+	node.push(mockHeader(1))
+	require.Equal(t, uint64(1), (<-ch).Number.Uint64())
+	select {
+	case err := <-sub.Err():
+		t.Fatalf("unexpected subscription error: %v", err)
+	default:
+	}
+}
+
+func TestStreamUnavailable(t *testing.T) {
+	provider, err := ethrpc.NewProvider("http://localhost:1")
+	require.NoError(t, err)
+
+	_, err = provider.StreamNewHeads(context.Background(), make(chan *types.Header))
+	require.ErrorIs(t, err, ethrpc.ErrStreamingUnavailable)
+}
+
+func mockHeader(num int64) *types.Header {
+	return &types.Header{
+		Number:     big.NewInt(num),
+		Difficulty: big.NewInt(0),
+		Extra:      []byte{},
+	}
+}
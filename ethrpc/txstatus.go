@@ -0,0 +1,170 @@
+package ethrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+)
+
+// TxState is the inclusion state of a transaction as seen by the node.
+type TxState int
+
+const (
+	// TxStateUnknown means the node does not know about the transaction, ie. it was
+	// never broadcast to this node, or it was evicted from the mempool.
+	TxStateUnknown TxState = iota
+
+	// TxStatePending means the transaction is in the mempool, waiting to be mined.
+	TxStatePending
+
+	// TxStateMined means the transaction has been included in a block.
+	TxStateMined
+
+	// TxStateDropped means the transaction is still known to the node, but its nonce
+	// has already been used by another mined transaction from the same sender, so it
+	// can never be mined.
+	TxStateDropped
+)
+
+func (s TxState) String() string {
+	switch s {
+	case TxStatePending:
+		return "PENDING"
+	case TxStateMined:
+		return "MINED"
+	case TxStateDropped:
+		return "DROPPED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// TxStatus is the result of Provider.TransactionStatus.
+type TxStatus struct {
+	State TxState
+
+	// Transaction is nil when the State is TxStateUnknown.
+	Transaction *types.Transaction
+
+	// BlockNumber, BlockHash and Receipt are only set when the State is TxStateMined.
+	// The Receipt may still be nil if the node has not indexed it yet.
+	BlockNumber *big.Int
+	BlockHash   common.Hash
+	Receipt     *types.Receipt
+
+	// Status is the receipt status, ie. types.ReceiptStatusSuccessful, and is only
+	// valid when Receipt is set.
+	Status uint64
+}
+
+// TransactionStatus returns whether the transaction is unknown, pending, mined or
+// dropped, by combining eth_getTransactionByHash and eth_getTransactionReceipt.
+func (s *Provider) TransactionStatus(ctx context.Context, txnHash common.Hash) (*TxStatus, error) {
+	var raw json.RawMessage
+	err := s.RPC.CallContext(ctx, &raw, "eth_getTransactionByHash", txnHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return &TxStatus{State: TxStateUnknown}, nil
+	}
+
+	var txn rpcTransaction
+	if err := json.Unmarshal(raw, &txn); err != nil {
+		return nil, err
+	}
+
+	status := &TxStatus{State: TxStatePending, Transaction: txn.tx}
+
+	if txn.BlockHash != nil && *txn.BlockHash != (common.Hash{}) {
+		status.State = TxStateMined
+		status.BlockHash = *txn.BlockHash
+		if txn.BlockNumber != nil {
+			status.BlockNumber, _ = hexutil.DecodeBig(*txn.BlockNumber)
+		}
+
+		receipt, err := s.TransactionReceipt(ctx, txnHash)
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+		if receipt != nil {
+			status.Receipt = receipt
+			status.Status = receipt.Status
+		}
+		return status, nil
+	}
+
+	// pending, check if the nonce has already been used by another transaction
+	if txn.tx == nil {
+		return status, nil
+	}
+	var from common.Address
+	if txn.From != nil {
+		from = *txn.From
+	} else {
+		from, err = types.Sender(types.LatestSignerForChainID(txn.tx.ChainId()), txn.tx)
+		if err != nil {
+			return status, nil
+		}
+	}
+
+	nonce, err := s.NonceAt(ctx, from, nil)
+	if err != nil {
+		return nil, err
+	}
+	if nonce > txn.tx.Nonce() {
+		status.State = TxStateDropped
+	}
+
+	return status, nil
+}
+
+var (
+	ErrTransactionNotFound = errors.New("ethrpc: transaction not found")
+	ErrTransactionPending  = errors.New("ethrpc: transaction is pending")
+)
+
+// TransactionLogs returns the logs emitted by a mined transaction, as found in its receipt.
+// It returns ErrTransactionPending if the transaction is still in the mempool, and
+// ErrTransactionNotFound if the node does not know about the transaction. If the
+// transaction is mined, but the node has not indexed its receipt yet, the error wraps
+// ethereum.NotFound.
+func (s *Provider) TransactionLogs(ctx context.Context, txnHash common.Hash) ([]types.Log, error) {
+	receipt, err := s.TransactionReceipt(ctx, txnHash)
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		return nil, err
+	}
+	if receipt != nil {
+		logs := make([]types.Log, 0, len(receipt.Logs))
+		for _, log := range receipt.Logs {
+			logs = append(logs, *log)
+		}
+		return logs, nil
+	}
+
+	// no receipt, so the transaction is either pending or unknown
+	var raw json.RawMessage
+	err = s.RPC.CallContext(ctx, &raw, "eth_getTransactionByHash", txnHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, ErrTransactionNotFound
+	}
+
+	var txn rpcTransaction
+	if err := json.Unmarshal(raw, &txn); err != nil {
+		return nil, err
+	}
+	if txn.BlockHash != nil && *txn.BlockHash != (common.Hash{}) {
+		return nil, fmt.Errorf("ethrpc: receipt of mined transaction %s is not available yet: %w", txnHash.Hex(), ethereum.NotFound)
+	}
+	return nil, ErrTransactionPending
+}
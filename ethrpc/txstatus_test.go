@@ -0,0 +1,209 @@
+package ethrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionStatus(t *testing.T) {
+	key, err := crypto.HexToECDSA("4646464646464646464646464646464646464646464646464646464646464646")
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	to := common.HexToAddress("0x3535353535353535353535353535353535353535")
+	signer := types.NewLondonSigner(big.NewInt(1))
+	newTxn := func(nonce uint64) *types.Transaction {
+		txn, err := types.SignTx(types.NewTransaction(nonce, to, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+		require.NoError(t, err)
+		return txn
+	}
+
+	mined, pending, dropped := newTxn(1), newTxn(2), newTxn(0)
+	blockHash := common.HexToHash("0xabcd")
+
+	txnJSON := func(txn *types.Transaction, isMined bool) map[string]interface{} {
+		data, _ := json.Marshal(txn)
+		var out map[string]interface{}
+		json.Unmarshal(data, &out)
+		out["from"] = from
+		if isMined {
+			out["blockHash"] = blockHash
+			out["blockNumber"] = "0xa"
+		}
+		return out
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		var hash common.Hash
+		if len(req.Params) > 0 {
+			json.Unmarshal(req.Params[0], &hash)
+		}
+
+		var result interface{}
+		switch req.Method {
+		case "eth_getTransactionByHash":
+			switch hash {
+			case mined.Hash():
+				result = txnJSON(mined, true)
+			case pending.Hash():
+				result = txnJSON(pending, false)
+			case dropped.Hash():
+				result = txnJSON(dropped, false)
+			}
+		case "eth_getTransactionReceipt":
+			if hash == mined.Hash() {
+				result = &types.Receipt{
+					Status:      types.ReceiptStatusFailed,
+					TxHash:      mined.Hash(),
+					BlockHash:   blockHash,
+					BlockNumber: big.NewInt(10),
+					Logs:        []*types.Log{},
+				}
+			}
+		case "eth_getTransactionCount":
+			result = "0x2"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	status, err := provider.TransactionStatus(ctx, mined.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, ethrpc.TxStateMined, status.State)
+	assert.Equal(t, uint64(10), status.BlockNumber.Uint64())
+	assert.Equal(t, blockHash, status.BlockHash)
+	require.NotNil(t, status.Receipt)
+	assert.Equal(t, types.ReceiptStatusFailed, status.Status)
+
+	status, err = provider.TransactionStatus(ctx, pending.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, ethrpc.TxStatePending, status.State)
+	assert.Equal(t, pending.Hash(), status.Transaction.Hash())
+	assert.Nil(t, status.Receipt)
+
+	status, err = provider.TransactionStatus(ctx, dropped.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, ethrpc.TxStateDropped, status.State)
+
+	status, err = provider.TransactionStatus(ctx, common.HexToHash("0x1234"))
+	require.NoError(t, err)
+	assert.Equal(t, ethrpc.TxStateUnknown, status.State)
+	assert.Nil(t, status.Transaction)
+}
+
+func TestTransactionLogs(t *testing.T) {
+	key, err := crypto.HexToECDSA("4646464646464646464646464646464646464646464646464646464646464646")
+	require.NoError(t, err)
+
+	to := common.HexToAddress("0x3535353535353535353535353535353535353535")
+	signer := types.NewLondonSigner(big.NewInt(1))
+	newTxn := func(nonce uint64) *types.Transaction {
+		txn, err := types.SignTx(types.NewTransaction(nonce, to, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+		require.NoError(t, err)
+		return txn
+	}
+
+	mined, indexing, pending := newTxn(1), newTxn(2), newTxn(3)
+	blockHash := common.HexToHash("0xabcd")
+
+	txnJSON := func(txn *types.Transaction, isMined bool) map[string]interface{} {
+		data, _ := json.Marshal(txn)
+		var out map[string]interface{}
+		json.Unmarshal(data, &out)
+		if isMined {
+			out["blockHash"] = blockHash
+			out["blockNumber"] = "0xa"
+		}
+		return out
+	}
+
+	logs := []*types.Log{
+		{Address: to, Topics: []common.Hash{common.HexToHash("0x01")}, Data: []byte{1}, TxHash: mined.Hash(), BlockHash: blockHash, BlockNumber: 10, Index: 0},
+		{Address: to, Topics: []common.Hash{common.HexToHash("0x02")}, Data: []byte{2}, TxHash: mined.Hash(), BlockHash: blockHash, BlockNumber: 10, Index: 1},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		var hash common.Hash
+		if len(req.Params) > 0 {
+			json.Unmarshal(req.Params[0], &hash)
+		}
+
+		var result interface{}
+		switch req.Method {
+		case "eth_getTransactionByHash":
+			switch hash {
+			case mined.Hash():
+				result = txnJSON(mined, true)
+			case indexing.Hash():
+				result = txnJSON(indexing, true)
+			case pending.Hash():
+				result = txnJSON(pending, false)
+			}
+		case "eth_getTransactionReceipt":
+			if hash == mined.Hash() {
+				result = &types.Receipt{
+					Status:      types.ReceiptStatusSuccessful,
+					TxHash:      mined.Hash(),
+					BlockHash:   blockHash,
+					BlockNumber: big.NewInt(10),
+					Logs:        logs,
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	defer srv.Close()
+
+	provider, err := ethrpc.NewProvider(srv.URL)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	txnLogs, err := provider.TransactionLogs(ctx, mined.Hash())
+	require.NoError(t, err)
+	require.Len(t, txnLogs, 2)
+	assert.Equal(t, *logs[0], txnLogs[0])
+	assert.Equal(t, *logs[1], txnLogs[1])
+
+	_, err = provider.TransactionLogs(ctx, indexing.Hash())
+	assert.ErrorIs(t, err, ethereum.NotFound)
+
+	_, err = provider.TransactionLogs(ctx, pending.Hash())
+	assert.ErrorIs(t, err, ethrpc.ErrTransactionPending)
+
+	_, err = provider.TransactionLogs(ctx, common.HexToHash("0x1234"))
+	assert.ErrorIs(t, err, ethrpc.ErrTransactionNotFound)
+}